@@ -0,0 +1,39 @@
+package main
+
+import (
+  "context"
+  "flag"
+
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/bootstrap"
+  "github.com/ushakovn/outfit/internal/config"
+  "github.com/ushakovn/outfit/pkg/logger"
+
+  _ "github.com/ushakovn/boiler/pkg/app"
+)
+
+var dryRun bool
+
+func main() {
+  ctx := context.Background()
+
+  logger.Init()
+
+  log.Warn("migrator app initializing")
+
+  flag.BoolVar(&dryRun, "dry-run", false, "print pending migrations without applying")
+  flag.Parse()
+
+  settings, err := config.LoadSettings(ctx)
+  if err != nil {
+    log.Fatalf("config.LoadSettings: %v", err)
+  }
+
+  if _, err = bootstrap.NewStorage(ctx, settings.Mongodb, bootstrap.StorageParams{
+    DryRun: dryRun,
+  }); err != nil {
+    log.Fatalf("bootstrap.NewStorage: %v", err)
+  }
+
+  log.Warn("migrator app terminating")
+}
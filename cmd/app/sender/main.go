@@ -3,13 +3,15 @@ package main
 import (
   "context"
   "flag"
-  "net/http"
+  "os/signal"
+  "syscall"
 
   log "github.com/sirupsen/logrus"
   _ "github.com/ushakovn/boiler/pkg/app"
+  "github.com/ushakovn/outfit/internal/app/bootstrap"
+  "github.com/ushakovn/outfit/internal/app/runs"
   "github.com/ushakovn/outfit/internal/app/sender"
   "github.com/ushakovn/outfit/internal/config"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   tgbot "github.com/ushakovn/outfit/internal/deps/telegram"
   "github.com/ushakovn/outfit/internal/models"
   "github.com/ushakovn/outfit/pkg/logger"
@@ -18,7 +20,8 @@ import (
 var productType models.ProductType
 
 func main() {
-  ctx := context.Background()
+  ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+  defer cancel()
 
   logger.Init()
 
@@ -27,37 +30,53 @@ func main() {
   flag.StringVar(&productType, "type", "", "product type")
   flag.Parse()
 
-  mongoClient, err := mongodb.NewClient(ctx,
-    mongodb.Config{
-      Host: config.Get(ctx, config.MongodbHost).String(),
-      Port: config.Get(ctx, config.MongodbPort).String(),
-      Authentication: &mongodb.Authentication{
-        User:     config.Get(ctx, config.MongodbUser).String(),
-        Password: config.Get(ctx, config.MongodbPassword).String(),
-      },
-    },
-    mongodb.Dependencies{
-      Client: http.DefaultClient,
-    })
+  settings, err := config.LoadSettings(ctx)
   if err != nil {
-    log.Fatalf("mongodb.NewClient: %v", err)
+    log.Fatalf("config.LoadSettings: %v", err)
   }
 
+  runtimeSettings, err := config.NewRuntime(ctx)
+  if err != nil {
+    log.Fatalf("config.NewRuntime: %v", err)
+  }
+
+  storage, err := bootstrap.NewStorage(ctx, settings.Mongodb, bootstrap.StorageParams{})
+  if err != nil {
+    log.Fatalf("bootstrap.NewStorage: %v", err)
+  }
+  repositories := storage.Repositories
+
   telegramBotClient, err := tgbot.NewBotClient(tgbot.Config{
-    Token: config.Get(ctx, config.TelegramToken).String(),
+    Token: settings.Telegram.Token,
   })
   if err != nil {
     log.Fatalf("tgbot.NewBotClient: %v", err)
   }
 
-  senderCron := sender.NewSenderCron(productType, sender.Dependencies{
+  senderCron := sender.NewSenderCron(sender.Config{
+    ProductType: productType,
+  }, sender.Dependencies{
     Telegram: telegramBotClient,
-    Mongodb:  mongoClient,
+    Messages: repositories.Messages,
+    Runs: runs.NewRecorder(runs.Dependencies{
+      Runs: repositories.Runs,
+    }),
+    Settings: runtimeSettings,
   })
 
-  if err = senderCron.Start(ctx); err != nil {
+  if _, err = bootstrap.StartProbes(ctx, settings.Probes.Port, storage.Check()); err != nil {
+    log.Fatalf("bootstrap.StartProbes: %v", err)
+  }
+
+  err = senderCron.Start(ctx)
+
+  // Запуск, прерванный сигналом, не считается аварийным: неотправленные сообщения отправит следующий запуск.
+  if err != nil && ctx.Err() == nil {
     log.Fatalf("senderCron.Start: %v", err)
   }
+  if ctx.Err() != nil {
+    log.Warnf("sender cron app interrupted: %v", err)
+  }
 
   log.Warn("sender cron app terminating")
 }
@@ -3,12 +3,16 @@ package main
 import (
   "context"
   "net/http"
-  "os"
   "os/signal"
   "syscall"
+  "time"
 
   "github.com/go-resty/resty/v2"
+  telegram "github.com/go-telegram/bot"
   log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/bootstrap"
+  "github.com/ushakovn/outfit/internal/app/probes"
+  "github.com/ushakovn/outfit/internal/app/runs"
   tgtransport "github.com/ushakovn/outfit/internal/app/telegram"
   "github.com/ushakovn/outfit/internal/app/tracker"
   "github.com/ushakovn/outfit/internal/config"
@@ -18,39 +22,50 @@ import (
   "github.com/ushakovn/outfit/internal/deps/parsers/oktyabr"
   "github.com/ushakovn/outfit/internal/deps/parsers/ridestep"
   "github.com/ushakovn/outfit/internal/deps/parsers/traektoria"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/deps/resolver"
   tgbot "github.com/ushakovn/outfit/internal/deps/telegram"
   "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/cache"
   "github.com/ushakovn/outfit/pkg/logger"
   "github.com/ushakovn/outfit/pkg/parser/xpath"
 
   _ "github.com/ushakovn/boiler/pkg/app"
 )
 
+const cronRunMaxAge = 24 * time.Hour
+
 func main() {
-  ctx := context.Background()
+  ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+  defer cancel()
 
   logger.Init()
 
   log.Warn("telegram bot app initializing")
 
-  mongoClient, err := mongodb.NewClient(ctx,
-    mongodb.Config{
-      Host: config.Get(ctx, config.MongodbHost).String(),
-      Port: config.Get(ctx, config.MongodbPort).String(),
-      Authentication: &mongodb.Authentication{
-        User:     config.Get(ctx, config.MongodbUser).String(),
-        Password: config.Get(ctx, config.MongodbPassword).String(),
-      },
-    },
-    mongodb.Dependencies{
-      Client: http.DefaultClient,
-    })
+  settings, err := config.LoadSettings(ctx)
+  if err != nil {
+    log.Fatalf("config.LoadSettings: %v", err)
+  }
+
+  // Секрет не хранится в репозитории. Без него ссылки на добавление отслеживания нельзя подписать.
+  if settings.Telegram.DeepLink.Secret == "" {
+    log.Fatalf("telegram deep link secret is not set: set %s env or %s config value", config.DeepLinkSecretEnv, config.TelegramDeepLinkSecret)
+  }
+
+  runtimeSettings, err := config.NewRuntime(ctx)
+  if err != nil {
+    log.Fatalf("config.NewRuntime: %v", err)
+  }
+
+  storage, err := bootstrap.NewStorage(ctx, settings.Mongodb, bootstrap.StorageParams{})
   if err != nil {
-    log.Fatalf("mongodb.NewClient: %v", err)
+    log.Fatalf("bootstrap.NewStorage: %v", err)
   }
+  repositories := storage.Repositories
 
-  httpClient := resty.NewWithClient(http.DefaultClient)
+  httpClient := resty.NewWithClient(&http.Client{
+    Timeout: settings.HTTP.ClientTimeout,
+  })
   xpathParser := xpath.NewParser(xpath.Dependencies{Client: httpClient})
 
   lamodaParser := lamoda.NewParser(lamoda.Dependencies{Xpath: xpathParser})
@@ -61,7 +76,8 @@ func main() {
   traektoriaParser := traektoria.NewParser(traektoria.Dependencies{Client: httpClient})
 
   trackerClient := tracker.NewTracker(tracker.Dependencies{
-    Mongodb: mongoClient,
+    Trackings: repositories.Trackings,
+    Messages:  repositories.Messages,
     Parsers: map[models.ProductType]models.Parser{
       models.ProductTypeLamoda:     lamodaParser,
       models.ProductTypeKixbox:     kixboxParser,
@@ -70,29 +86,168 @@ func main() {
       models.ProductTypeRidestep:   ridestepParser,
       models.ProductTypeTraektoria: traektoriaParser,
     },
+    Settings: runtimeSettings,
+    Products: cache.NewCache[string, *models.Product](ctx, cache.Config{
+      TTL:             settings.Tracker.ProductsCacheTTL,
+      Capacity:        settings.Tracker.ProductsCacheCapacity,
+      JanitorInterval: settings.Tracker.ProductsCacheTTL,
+    }),
+  })
+
+  urlResolver, err := resolver.NewResolver(resolver.Config{
+    MaxHops: settings.HTTP.Resolver.MaxHops,
+    Timeout: settings.HTTP.Resolver.Timeout,
   })
+  if err != nil {
+    log.Fatalf("resolver.NewResolver: %v", err)
+  }
+
+  webhookSettings := settings.Telegram.Webhook
+
+  var telegramMonitor *tgbot.Monitor
+
+  // Монитор отслеживает запросы long polling и не используется в режиме webhook.
+  if !webhookSettings.Enabled {
+    telegramMonitor = tgbot.NewMonitor()
+  }
+
+  timingMiddleware, err := tgtransport.NewTimingMiddleware(tgtransport.TimingConfig{
+    SlowThreshold: settings.Telegram.SlowUpdateThreshold,
+  })
+  if err != nil {
+    log.Fatalf("tgtransport.NewTimingMiddleware: %v", err)
+  }
+
+  rateLimitMiddleware, err := tgtransport.NewRateLimitMiddleware(ctx, tgtransport.RateLimitConfig{
+    Interval: settings.Telegram.RateLimit.Interval,
+    Burst:    settings.Telegram.RateLimit.Burst,
+  })
+  if err != nil {
+    log.Fatalf("tgtransport.NewRateLimitMiddleware: %v", err)
+  }
 
   telegramBotClient, err := tgbot.NewBotClient(tgbot.Config{
-    Token: config.Get(ctx, config.TelegramToken).String(),
+    Token:   settings.Telegram.Token,
+    Monitor: telegramMonitor,
+    Webhook: webhookSettings.Enabled,
+    // Порядок важен: восстановление после паники охватывает всю цепочку,
+    // а блокировка чата берется только для обновлений, прошедших ограничения.
+    Middlewares: []telegram.Middleware{
+      tgtransport.NewRecoveryMiddleware(),
+      timingMiddleware,
+      tgtransport.NewMaintenanceMiddleware(runtimeSettings),
+      rateLimitMiddleware,
+      tgtransport.NewChatLockMiddleware(),
+    },
   })
   if err != nil {
     log.Fatalf("tgbot.NewBotClient: %v", err)
   }
 
-  telegramBotTransport := tgtransport.NewTransport(tgtransport.Dependencies{
-    Tracker:  trackerClient,
-    Telegram: telegramBotClient,
-    Mongodb:  mongoClient,
+  telegramBotTransportDeps := tgtransport.Dependencies{
+    Tracker:   trackerClient,
+    Telegram:  telegramBotClient,
+    Trackings: repositories.Trackings,
+    Sessions:  repositories.Sessions,
+    Issues:    repositories.Issues,
+    Sliders:   repositories.Sliders,
+    DeepLinks: repositories.DeepLinks,
+    Resolver:  urlResolver,
+  }
+
+  var telegramWebhook *tgbot.Webhook
+
+  if webhookSettings.Enabled {
+    telegramWebhook, err = tgbot.NewWebhook(
+      tgbot.WebhookConfig{
+        URL:            webhookSettings.URL,
+        Port:           webhookSettings.Port,
+        SecretToken:    webhookSettings.SecretToken,
+        MaxConnections: webhookSettings.MaxConnections,
+        DrainTimeout:   webhookSettings.DrainTimeout,
+        DeleteOnStop:   webhookSettings.DeleteOnStop,
+      },
+      telegramBotClient)
+    if err != nil {
+      log.Fatalf("tgbot.NewWebhook: %v", err)
+    }
+
+    telegramBotTransportDeps.Webhook = telegramWebhook
+  }
+
+  telegramBotTransport, err := tgtransport.NewTransport(
+    tgtransport.Config{
+      ListLimit: settings.Telegram.ListLimit,
+      SliderTTL: settings.Telegram.SliderTTL,
+      DeepLink: tgtransport.DeepLinkConfig{
+        Secret: settings.Telegram.DeepLink.Secret,
+        TTL:    settings.Telegram.DeepLink.TTL,
+      },
+      Trash: tgtransport.TrashConfig{
+        UndoTTL: settings.Telegram.Trash.UndoTTL,
+        TTL:     settings.Telegram.Trash.TTL,
+      },
+      Bulk: tgtransport.BulkConfig{
+        Workers: settings.Telegram.Bulk.Workers,
+        Limit:   settings.Telegram.Bulk.Limit,
+      },
+    },
+    telegramBotTransportDeps)
+  if err != nil {
+    log.Fatalf("tgtransport.NewTransport: %v", err)
+  }
+
+  runsRecorder := runs.NewRecorder(runs.Dependencies{
+    Runs: repositories.Runs,
   })
 
+  _, err = bootstrap.StartProbes(ctx, settings.Probes.Port,
+    storage.Check(),
+    probes.Check{
+      Name: "telegram",
+      Probe: func(ctx context.Context) error {
+        _, err := telegramBotClient.GetMe(ctx)
+        return err
+      },
+    },
+    probes.Check{
+      Name: "telegram_updates",
+      Probe: func(ctx context.Context) error {
+        if telegramWebhook != nil {
+          return telegramWebhook.Check(ctx)
+        }
+        return telegramMonitor.Check(telegramMonitor.DefaultMaxDelay())
+      },
+    },
+    probes.Check{
+      Name:     "tracker_last_run",
+      Optional: true,
+      Probe: func(ctx context.Context) error {
+        return runsRecorder.Check(ctx, models.TrackerCronName, cronRunMaxAge)
+      },
+    },
+    probes.Check{
+      Name:     "sender_last_run",
+      Optional: true,
+      Probe: func(ctx context.Context) error {
+        return runsRecorder.Check(ctx, models.SenderCronName, cronRunMaxAge)
+      },
+    },
+  )
+  if err != nil {
+    log.Fatalf("bootstrap.StartProbes: %v", err)
+  }
+
   err = telegramBotTransport.Start(ctx)
   if err != nil {
     log.Fatalf("telegramBotTransport.Start: %v", err)
   }
 
-  exitSignal := make(chan os.Signal)
-  signal.Notify(exitSignal, syscall.SIGINT, syscall.SIGTERM)
-  <-exitSignal
+  <-ctx.Done()
 
   log.Warn("telegram bot app terminating")
+
+  if err = telegramBotTransport.Stop(); err != nil {
+    log.Errorf("telegramBotTransport.Stop: %v", err)
+  }
 }
@@ -4,17 +4,21 @@ import (
   "context"
   "flag"
   "net/http"
+  "os/signal"
+  "syscall"
 
   "github.com/go-resty/resty/v2"
   log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/bootstrap"
+  "github.com/ushakovn/outfit/internal/app/runs"
   "github.com/ushakovn/outfit/internal/app/tracker"
+  "github.com/ushakovn/outfit/internal/config"
   "github.com/ushakovn/outfit/internal/deps/parsers/kixbox"
   "github.com/ushakovn/outfit/internal/deps/parsers/lamoda"
   "github.com/ushakovn/outfit/internal/deps/parsers/lime"
   "github.com/ushakovn/outfit/internal/deps/parsers/oktyabr"
   "github.com/ushakovn/outfit/internal/deps/parsers/ridestep"
   "github.com/ushakovn/outfit/internal/deps/parsers/traektoria"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   "github.com/ushakovn/outfit/internal/models"
   "github.com/ushakovn/outfit/pkg/logger"
   "github.com/ushakovn/outfit/pkg/parser/xpath"
@@ -25,7 +29,8 @@ import (
 var productType models.ProductType
 
 func main() {
-  ctx := context.Background()
+  ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+  defer cancel()
 
   logger.Init()
 
@@ -34,23 +39,25 @@ func main() {
   flag.StringVar(&productType, "type", "", "product type")
   flag.Parse()
 
-  mongoClient, err := mongodb.NewClient(ctx,
-    mongodb.Config{
-      Host: "localhost",
-      Port: "27017",
-      Authentication: &mongodb.Authentication{
-        User:     "outfit",
-        Password: "scp",
-      },
-    },
-    mongodb.Dependencies{
-      Client: http.DefaultClient,
-    })
+  settings, err := config.LoadSettings(ctx)
+  if err != nil {
+    log.Fatalf("config.LoadSettings: %v", err)
+  }
+
+  runtimeSettings, err := config.NewRuntime(ctx)
+  if err != nil {
+    log.Fatalf("config.NewRuntime: %v", err)
+  }
+
+  storage, err := bootstrap.NewStorage(ctx, settings.Mongodb, bootstrap.StorageParams{})
   if err != nil {
-    log.Fatalf("mongodb.NewClient: %v", err)
+    log.Fatalf("bootstrap.NewStorage: %v", err)
   }
+  repositories := storage.Repositories
 
-  httpClient := resty.NewWithClient(http.DefaultClient)
+  httpClient := resty.NewWithClient(&http.Client{
+    Timeout: settings.HTTP.ClientTimeout,
+  })
   xpathParser := xpath.NewParser(xpath.Dependencies{Client: httpClient})
 
   lamodaParser := lamoda.NewParser(lamoda.Dependencies{Xpath: xpathParser})
@@ -60,8 +67,17 @@ func main() {
   ridestepParser := ridestep.NewParser(ridestep.Dependencies{Xpath: xpathParser})
   traektoriaParser := traektoria.NewParser(traektoria.Dependencies{Client: httpClient})
 
-  trackerCron := tracker.NewTrackerCron(productType, tracker.Dependencies{
-    Mongodb: mongoClient,
+  trackerCron := tracker.NewTrackerCron(tracker.Config{
+    ProductType:    productType,
+    Owner:          settings.Tracker.InstanceId,
+    LeaseTTL:       settings.Tracker.LeaseTTL,
+    BatchSize:      settings.Tracker.BatchSize,
+    HandleInterval: settings.Tracker.HandleInterval,
+    FailingTTL:     settings.Tracker.ArchiveFailingTTL,
+    StaleTTL:       settings.Tracker.ArchiveStaleTTL,
+  }, tracker.Dependencies{
+    Trackings: repositories.Trackings,
+    Messages:  repositories.Messages,
     Parsers: map[models.ProductType]models.Parser{
       models.ProductTypeLamoda:     lamodaParser,
       models.ProductTypeKixbox:     kixboxParser,
@@ -70,11 +86,25 @@ func main() {
       models.ProductTypeRidestep:   ridestepParser,
       models.ProductTypeTraektoria: traektoriaParser,
     },
+    Runs: runs.NewRecorder(runs.Dependencies{
+      Runs: repositories.Runs,
+    }),
+    Settings: runtimeSettings,
   })
 
-  if err = trackerCron.Start(ctx); err != nil {
+  if _, err = bootstrap.StartProbes(ctx, settings.Probes.Port, storage.Check()); err != nil {
+    log.Fatalf("bootstrap.StartProbes: %v", err)
+  }
+
+  err = trackerCron.Start(ctx)
+
+  // Запуск, прерванный сигналом, не считается аварийным: необработанные отслеживания захватит следующий запуск.
+  if err != nil && ctx.Err() == nil {
     log.Fatalf("trackerCron.Start: %v", err)
   }
+  if ctx.Err() != nil {
+    log.Warnf("tracker cron app interrupted: %v", err)
+  }
 
   log.Warn("tracker cron app terminating")
 }
@@ -8,13 +8,13 @@ require (
 	github.com/go-playground/validator/v10 v10.22.1
 	github.com/go-resty/resty/v2 v2.15.3
 	github.com/go-telegram/bot v1.11.1
-	github.com/go-telegram/ui v0.4.1
 	github.com/google/uuid v1.3.1
 	github.com/leekchan/accounting v1.0.0
 	github.com/microcosm-cc/bluemonday v1.0.27
 	github.com/samber/lo v1.47.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/sourcegraph/go-selenium v0.0.0-20170113155244-3da7d00aac9c
+	github.com/spf13/cast v1.7.0
 	github.com/tebeka/selenium v0.9.9
 	github.com/ushakovn/boiler v0.0.0-20241130145712-0b70e59756fa
 	go.mongodb.org/mongo-driver v1.17.1
@@ -72,7 +72,6 @@ require (
 	github.com/rantav/go-grpc-channelz v0.0.4 // indirect
 	github.com/shopspring/decimal v0.0.0-20180709203117-cd690d0c9e24 // indirect
 	github.com/sosodev/duration v1.1.0 // indirect
-	github.com/spf13/cast v1.7.0 // indirect
 	github.com/swaggo/files/v2 v2.0.0 // indirect
 	github.com/swaggo/swag v1.8.1 // indirect
 	github.com/vektah/gqlparser/v2 v2.5.10 // indirect
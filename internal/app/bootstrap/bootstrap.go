@@ -0,0 +1,119 @@
+package bootstrap
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+
+  boilerconfig "github.com/ushakovn/boiler/pkg/config"
+  "github.com/ushakovn/outfit/internal/app/probes"
+  "github.com/ushakovn/outfit/internal/config"
+  "github.com/ushakovn/outfit/internal/deps/parsers"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongorepo"
+  "github.com/ushakovn/outfit/pkg/buildinfo"
+)
+
+// Storage подключение к MongoDB и репозитории приложений.
+type Storage struct {
+  Client       *mongodb.Client
+  Repositories *mongorepo.Repositories
+}
+
+type StorageParams struct {
+  // DryRun выводит ожидающие миграции без применения.
+  DryRun bool
+}
+
+// NewStorage подключается к MongoDB, создает репозитории и применяет миграции.
+func NewStorage(ctx context.Context, settings config.MongodbSettings, params StorageParams) (*Storage, error) {
+  client, err := mongodb.NewClient(ctx,
+    mongodb.Config{
+      URI:  settings.URI,
+      Host: settings.Host,
+      Port: settings.Port,
+      Authentication: &mongodb.Authentication{
+        User:     settings.User,
+        Password: settings.Password,
+      },
+      AuthSource:             settings.AuthSource,
+      ReplicaSet:             settings.ReplicaSet,
+      TLS:                    settings.TLS,
+      ConnectTimeout:         settings.ConnectTimeout,
+      ServerSelectionTimeout: settings.ServerSelectionTimeout,
+    },
+    mongodb.Dependencies{
+      Client: http.DefaultClient,
+    })
+  if err != nil {
+    return nil, fmt.Errorf("mongodb.NewClient: %w", err)
+  }
+
+  repositories, err := mongorepo.New(
+    mongorepo.Config{
+      Database: settings.Database,
+      Collections: mongorepo.Collections{
+        Trackings:      settings.Collections.Trackings,
+        Sessions:       settings.Collections.Sessions,
+        Messages:       settings.Collections.Messages,
+        Issues:         settings.Collections.Issues,
+        Runs:           settings.Collections.Runs,
+        Migrations:     settings.Collections.Migrations,
+        MigrationsLock: settings.Collections.MigrationsLock,
+        Sliders:        settings.Collections.Sliders,
+        DeepLinks:      settings.Collections.DeepLinks,
+      },
+    },
+    mongorepo.Dependencies{
+      Mongodb: client,
+    })
+  if err != nil {
+    return nil, fmt.Errorf("mongorepo.New: %w", err)
+  }
+
+  if err = repositories.Migrate(ctx, mongorepo.MigrateParams{
+    DryRun:     params.DryRun,
+    ProductKey: parsers.ProductKey,
+  }); err != nil {
+    return nil, fmt.Errorf("repositories.Migrate: %w", err)
+  }
+
+  return &Storage{
+    Client:       client,
+    Repositories: repositories,
+  }, nil
+}
+
+// Check проверка готовности MongoDB.
+func (s *Storage) Check() probes.Check {
+  return probes.Check{
+    Name:  "mongodb",
+    Probe: s.Client.Ping,
+  }
+}
+
+// StartProbes запускает сервер проверок. Сервер останавливается при отмене ctx.
+// Возвращает nil, если порт не указан.
+func StartProbes(ctx context.Context, port int, checks ...probes.Check) (*probes.Server, error) {
+  if port == 0 {
+    return nil, nil
+  }
+
+  server, err := probes.NewServer(
+    probes.Config{
+      Port:  port,
+      Build: buildinfo.Get(boilerconfig.ContextClient(ctx).GetAppInfo().Name),
+    },
+    probes.Dependencies{
+      Checks: checks,
+    })
+  if err != nil {
+    return nil, fmt.Errorf("probes.NewServer: %w", err)
+  }
+
+  if err = server.Start(ctx); err != nil {
+    return nil, fmt.Errorf("server.Start: %w", err)
+  }
+
+  return server, nil
+}
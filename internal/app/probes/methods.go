@@ -0,0 +1,116 @@
+package probes
+
+import (
+  "context"
+  "encoding/json"
+  "net/http"
+  "sync"
+  "time"
+
+  log "github.com/sirupsen/logrus"
+)
+
+const (
+  statusOk     = "ok"
+  statusFailed = "failed"
+)
+
+type healthzResponse struct {
+  Status string `json:"status"`
+}
+
+type readyzResponse struct {
+  Status string                `json:"status"`
+  Checks map[string]checkState `json:"checks"`
+}
+
+type checkState struct {
+  Status   string `json:"status"`
+  Optional bool   `json:"optional,omitempty"`
+  Error    string `json:"error,omitempty"`
+  Duration string `json:"duration"`
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+  writeJSON(w, http.StatusOK, healthzResponse{
+    Status: statusOk,
+  })
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+  ctx, cancel := context.WithTimeout(r.Context(), defaultCheckTimeout)
+  defer cancel()
+
+  resp := readyzResponse{
+    Status: statusOk,
+    Checks: s.runChecks(ctx),
+  }
+
+  code := http.StatusOK
+
+  for _, state := range resp.Checks {
+    if state.Status != statusOk && !state.Optional {
+      resp.Status = statusFailed
+      code = http.StatusServiceUnavailable
+    }
+  }
+
+  writeJSON(w, code, resp)
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, _ *http.Request) {
+  writeJSON(w, http.StatusOK, s.config.Build)
+}
+
+func (s *Server) runChecks(ctx context.Context) map[string]checkState {
+  var (
+    mu sync.Mutex
+    wg sync.WaitGroup
+  )
+
+  states := make(map[string]checkState, len(s.deps.Checks))
+
+  for _, check := range s.deps.Checks {
+    wg.Add(1)
+
+    go func(check Check) {
+      defer wg.Done()
+
+      startedAt := time.Now()
+      err := check.Probe(ctx)
+
+      state := checkState{
+        Status:   statusOk,
+        Optional: check.Optional,
+        Duration: time.Since(startedAt).String(),
+      }
+
+      if err != nil {
+        state.Status = statusFailed
+        state.Error = err.Error()
+
+        log.
+          WithField("check.name", check.Name).
+          WithField("check.optional", check.Optional).
+          Warnf("readiness check failed: %v", err)
+      }
+
+      mu.Lock()
+      states[check.Name] = state
+      mu.Unlock()
+    }(check)
+  }
+
+  wg.Wait()
+
+  return states
+}
+
+func writeJSON(w http.ResponseWriter, code int, value any) {
+  w.Header().Set("Content-Type", "application/json")
+  w.WriteHeader(code)
+
+  if err := json.NewEncoder(w).Encode(value); err != nil {
+    log.Errorf("probes.Server: json.Encode: %v", err)
+  }
+}
@@ -0,0 +1,108 @@
+package probes
+
+import (
+  "context"
+  "fmt"
+  "net"
+  "net/http"
+  "strconv"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/pkg/buildinfo"
+)
+
+const (
+  defaultCheckTimeout    = 3 * time.Second
+  defaultShutdownTimeout = 5 * time.Second
+)
+
+type Server struct {
+  config Config
+  deps   Dependencies
+  server *http.Server
+}
+
+type Config struct {
+  Port  int `validate:"required"`
+  Build buildinfo.Info
+}
+
+type Dependencies struct {
+  Checks []Check
+}
+
+// Check проверка готовности.
+// Ошибка некритичной проверки отображается в ответе, но не влияет на статус.
+type Check struct {
+  Name     string
+  Optional bool
+  Probe    func(ctx context.Context) error
+}
+
+func (c *Config) Validate() error {
+  return validator.New().Struct(c)
+}
+
+func NewServer(config Config, deps Dependencies) (*Server, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  s := &Server{
+    config: config,
+    deps:   deps,
+  }
+
+  mux := http.NewServeMux()
+  mux.HandleFunc("/healthz", s.handleHealthz)
+  mux.HandleFunc("/readyz", s.handleReadyz)
+  mux.HandleFunc("/version", s.handleVersion)
+
+  s.server = &http.Server{
+    Addr:              net.JoinHostPort("", strconv.Itoa(config.Port)),
+    Handler:           mux,
+    ReadHeaderTimeout: defaultCheckTimeout,
+  }
+
+  return s, nil
+}
+
+func (s *Server) Start(ctx context.Context) error {
+  listener, err := net.Listen("tcp", s.server.Addr)
+  if err != nil {
+    return fmt.Errorf("net.Listen: %w", err)
+  }
+
+  go func() {
+    if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+      log.Errorf("probes.Server: s.server.Serve: %v", err)
+    }
+  }()
+
+  go func() {
+    <-ctx.Done()
+
+    if err := s.Stop(); err != nil {
+      log.Errorf("probes.Server: s.Stop: %v", err)
+    }
+  }()
+
+  log.
+    WithField("probes.addr", s.server.Addr).
+    Info("probes server started")
+
+  return nil
+}
+
+func (s *Server) Stop() error {
+  ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+  defer cancel()
+
+  if err := s.server.Shutdown(ctx); err != nil {
+    return fmt.Errorf("s.server.Shutdown: %w", err)
+  }
+
+  return nil
+}
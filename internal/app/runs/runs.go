@@ -0,0 +1,112 @@
+package runs
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "time"
+
+  "github.com/google/uuid"
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Recorder struct {
+  deps Dependencies
+}
+
+type Dependencies struct {
+  Runs models.RunsRepository
+}
+
+func NewRecorder(deps Dependencies) *Recorder {
+  return &Recorder{deps: deps}
+}
+
+func (r *Recorder) Start(ctx context.Context, name models.CronName, typ models.ProductType) (*models.CronRun, error) {
+  run := &models.CronRun{
+    UUID:        uuid.NewString(),
+    Name:        name,
+    ProductType: typ,
+    Status:      models.CronRunStatusRunning,
+    Timestamps: models.CronRunTimestamps{
+      StartedAt: time.Now(),
+    },
+  }
+
+  if err := r.deps.Runs.Insert(ctx, *run); err != nil {
+    return nil, fmt.Errorf("r.deps.Runs.Insert: %w", err)
+  }
+
+  return run, nil
+}
+
+// Finish сохраняет результат запуска. Результат сохраняется и для запуска, прерванного отменой ctx.
+func (r *Recorder) Finish(ctx context.Context, run *models.CronRun, runErr error) {
+  ctx = context.WithoutCancel(ctx)
+
+  run.Status = models.CronRunStatusSucceeded
+  run.Timestamps.FinishedAt = lo.ToPtr(time.Now())
+
+  if runErr != nil {
+    run.Status = models.CronRunStatusFailed
+    run.Error = runErr.Error()
+  }
+
+  if err := r.deps.Runs.Update(ctx, run); err != nil {
+    log.
+      WithFields(log.Fields{
+        "run.uuid":         run.UUID,
+        "run.name":         run.Name,
+        "run.product_type": run.ProductType,
+      }).
+      Errorf("r.deps.Runs.Update: %v", err)
+  }
+}
+
+// Check проверяет последний запуск крона по каждому типу товаров, с которым крон запускался:
+// запуск должен завершиться успешно не позднее maxAge назад.
+func (r *Recorder) Check(ctx context.Context, name models.CronName, maxAge time.Duration) error {
+  var found bool
+
+  // Пустой тип соответствует запуску крона по всем типам товаров.
+  for _, typ := range append([]models.ProductType{""}, models.ProductTypes...) {
+    run, err := r.deps.Runs.FindLast(ctx, name, typ)
+    if err != nil {
+      if errors.Is(err, models.ErrNotFound) {
+        continue
+      }
+      return fmt.Errorf("r.deps.Runs.FindLast: %w", err)
+    }
+    found = true
+
+    if err = checkRun(run, maxAge); err != nil {
+      return err
+    }
+  }
+
+  if !found {
+    return fmt.Errorf("%s cron has never run", name)
+  }
+
+  return nil
+}
+
+func checkRun(run *models.CronRun, maxAge time.Duration) error {
+  name := run.Name
+
+  if run.ProductType != "" {
+    name = fmt.Sprintf("%s[%s]", run.Name, run.ProductType)
+  }
+
+  if run.Status == models.CronRunStatusFailed {
+    return fmt.Errorf("%s cron last run failed: %s", name, run.Error)
+  }
+
+  if age := time.Since(run.Timestamps.StartedAt); age > maxAge {
+    return fmt.Errorf("%s cron last run started %s ago", name, age.Truncate(time.Second))
+  }
+
+  return nil
+}
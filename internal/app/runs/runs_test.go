@@ -0,0 +1,94 @@
+package runs
+
+import (
+  "context"
+  "testing"
+  "time"
+
+  "github.com/google/uuid"
+  "github.com/ushakovn/outfit/internal/deps/storage/memrepo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func newTestRun(typ models.ProductType, status models.CronRunStatus, startedAgo time.Duration) models.CronRun {
+  return models.CronRun{
+    UUID:        uuid.NewString(),
+    Name:        models.TrackerCronName,
+    ProductType: typ,
+    Status:      status,
+    Timestamps: models.CronRunTimestamps{
+      StartedAt: time.Now().Add(-startedAgo),
+    },
+  }
+}
+
+func TestRecorderCheck(t *testing.T) {
+  const maxAge = time.Hour
+
+  tests := []struct {
+    name    string
+    runs    []models.CronRun
+    wantErr bool
+  }{
+    {
+      name:    "never run",
+      wantErr: true,
+    },
+    {
+      name: "last run of every product type succeeded",
+      runs: []models.CronRun{
+        newTestRun(models.ProductTypeLamoda, models.CronRunStatusSucceeded, time.Minute),
+        newTestRun(models.ProductTypeKixbox, models.CronRunStatusSucceeded, 2*time.Minute),
+      },
+    },
+    {
+      name: "failed run of one product type is not hidden by another",
+      runs: []models.CronRun{
+        newTestRun(models.ProductTypeLamoda, models.CronRunStatusFailed, 2*time.Minute),
+        newTestRun(models.ProductTypeKixbox, models.CronRunStatusSucceeded, time.Minute),
+      },
+      wantErr: true,
+    },
+    {
+      name: "stale run of one product type is not hidden by another",
+      runs: []models.CronRun{
+        newTestRun(models.ProductTypeLamoda, models.CronRunStatusSucceeded, 2*maxAge),
+        newTestRun(models.ProductTypeKixbox, models.CronRunStatusSucceeded, time.Minute),
+      },
+      wantErr: true,
+    },
+    {
+      name: "failed run is fixed by later run of same product type",
+      runs: []models.CronRun{
+        newTestRun(models.ProductTypeLamoda, models.CronRunStatusFailed, 2*time.Minute),
+        newTestRun(models.ProductTypeLamoda, models.CronRunStatusSucceeded, time.Minute),
+      },
+    },
+    {
+      name: "run for all product types",
+      runs: []models.CronRun{
+        newTestRun("", models.CronRunStatusSucceeded, time.Minute),
+      },
+    },
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      ctx := context.Background()
+
+      repository := memrepo.NewRuns()
+      for _, run := range tt.runs {
+        if err := repository.Insert(ctx, run); err != nil {
+          t.Fatalf("repository.Insert: %v", err)
+        }
+      }
+
+      recorder := NewRecorder(Dependencies{Runs: repository})
+
+      err := recorder.Check(ctx, models.TrackerCronName, maxAge)
+      if (err != nil) != tt.wantErr {
+        t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+      }
+    })
+  }
+}
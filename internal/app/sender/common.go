@@ -4,38 +4,32 @@ import (
   "context"
   "fmt"
 
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  tgmodels "github.com/go-telegram/bot/models"
   "github.com/ushakovn/outfit/internal/models"
 )
 
-func (c *Sender) makeMessagesFilters() map[string]any {
-  filters := map[string]any{
-    "type":    models.ProductDiffSendableType,
-    "sent_id": nil,
+func (c *Sender) makeMessagesFilters() models.MessagesFilter {
+  return models.MessagesFilter{
+    ProductType: c.config.ProductType,
   }
-  if c.config.ProductType != "" {
-    filters["product.type"] = c.config.ProductType
-  }
-  return filters
 }
 
 func (c *Sender) updateSendableMessage(ctx context.Context, message *models.SendableMessage) error {
-  _, err := c.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
-    GetParams: mongodb.GetParams{
-      CommonParams: mongodb.CommonParams{
-        Database:   "outfit",
-        Collection: "messages",
-        StructType: models.SendableMessage{},
-      },
-      Filters: map[string]any{
-        "uuid": message.UUID,
-      },
-    },
-    Document: message,
-  })
-  if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Update: %w", err)
+  if err := c.deps.Messages.Update(ctx, message); err != nil {
+    return fmt.Errorf("c.deps.Messages.Update: %w", err)
   }
 
   return nil
 }
+
+func newInlineKeyboard(buttons []models.SendableButton) tgmodels.InlineKeyboardMarkup {
+  rows := make([][]tgmodels.InlineKeyboardButton, 0, len(buttons))
+
+  for _, button := range buttons {
+    rows = append(rows, []tgmodels.InlineKeyboardButton{
+      {Text: button.Text, CallbackData: button.CallbackData},
+    })
+  }
+
+  return tgmodels.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -3,12 +3,12 @@ package sender
 import (
   "context"
   "fmt"
+  "strconv"
   "strings"
 
   telegram "github.com/go-telegram/bot"
   tgmodels "github.com/go-telegram/bot/models"
   log "github.com/sirupsen/logrus"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   "github.com/ushakovn/outfit/internal/models"
   "github.com/ushakovn/outfit/pkg/worker"
 )
@@ -22,80 +22,111 @@ func (c *Sender) Start(ctx context.Context) error {
     WithField("product_type", c.config.ProductType).
     Info("sender cron starting")
 
-  pool := worker.NewPool(ctx, worker.DefaultCount)
+  if c.deps.Settings.MaintenanceMode() {
+    log.Warn("maintenance mode enabled. sender cron skipped")
+    return nil
+  }
 
-  err := c.deps.Mongodb.Scan(ctx, mongodb.ScanParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "messages",
-      StructType: models.SendableMessage{},
-    },
-    Filters: c.makeMessagesFilters(),
+  run, err := c.deps.Runs.Start(ctx, models.SenderCronName, c.config.ProductType)
+  if err != nil {
+    return fmt.Errorf("c.deps.Runs.Start: %w", err)
+  }
 
-    Callback: func(ctx context.Context, value any) error {
-      message, ok := value.(*models.SendableMessage)
-      if !ok {
-        log.
-          WithField("message.value", value).
-          Errorf("cast message %v with type: %[1]T to: %T failed", value, new(models.SendableMessage))
+  err = c.scan(ctx)
 
-        return nil
-      }
+  c.deps.Runs.Finish(ctx, run, err)
 
-      log.
-        WithFields(log.Fields{
-          "message.uuid":        message.UUID,
-          "message.chat_id":     message.ChatId,
-          "message.product.url": message.Product.URL,
-        }).
-        Info("scanned message from mongodb collection")
+  if err != nil {
+    return fmt.Errorf("c.scan: %w", err)
+  }
+
+  log.
+    WithField("product_type", c.config.ProductType).
+    Info("sender cron completed successfully")
+
+  return nil
+}
+
+func (c *Sender) scan(ctx context.Context) error {
+  pool := worker.NewPool(ctx, worker.Config{
+    Count: c.deps.Settings.SenderWorkers(),
+  })
 
-      pool.Push(func(ctx context.Context) error {
-        if err := c.handleSendableMessage(ctx, message); err != nil {
-          log.
-            WithFields(log.Fields{
-              "message.uuid":        message.UUID,
-              "message.chat_id":     message.ChatId,
-              "message.product.url": message.Product.URL,
-            }).
-            Errorf("sendable message handle failed: %v", err)
+  err := c.deps.Messages.ScanUnsent(ctx, c.makeMessagesFilters(), func(ctx context.Context, message *models.SendableMessage) error {
+    log.
+      WithFields(log.Fields{
+        "message.uuid":        message.UUID,
+        "message.chat_id":     message.ChatId,
+        "message.product.url": message.Product.URL,
+      }).
+      Info("scanned message from messages collection")
 
-          return nil
-        }
+    // Сообщения одного чата отправляются последовательно, чтобы сохранить их порядок.
+    key := strconv.FormatInt(message.ChatId, 10)
 
+    return pool.PushKey(ctx, key, func(ctx context.Context) error {
+      if err := c.handleSendableMessage(ctx, message); err != nil {
         log.
           WithFields(log.Fields{
             "message.uuid":        message.UUID,
             "message.chat_id":     message.ChatId,
             "message.product.url": message.Product.URL,
           }).
-          Info("message handled successfully")
+          Errorf("sendable message handle failed: %v", err)
 
         return nil
-      })
+      }
+
+      log.
+        WithFields(log.Fields{
+          "message.uuid":        message.UUID,
+          "message.chat_id":     message.ChatId,
+          "message.product.url": message.Product.URL,
+        }).
+        Info("message handled successfully")
 
       return nil
-    },
+    })
   })
   if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Scan: %w", err)
+    pool.Cancel()
+    return fmt.Errorf("c.deps.Messages.ScanUnsent: %w", err)
   }
 
-  pool.StopWait()
+  if err = pool.Drain(); err != nil {
+    return fmt.Errorf("pool.Drain: %w", err)
+  }
 
-  log.
-    WithField("product_type", c.config.ProductType).
-    Info("sender cron completed successfully")
+  logPoolStats(pool.Stats())
 
   return nil
 }
 
+func logPoolStats(stats worker.Stats) {
+  log.
+    WithFields(log.Fields{
+      "pool.pushed":      stats.Pushed,
+      "pool.completed":   stats.Completed,
+      "pool.failed":      stats.Failed,
+      "pool.avg_wait":    stats.AvgWait.String(),
+      "pool.avg_latency": stats.AvgLatency.String(),
+      "pool.max_latency": stats.MaxLatency.String(),
+    }).
+    Info("sender pool drained")
+}
+
 func (c *Sender) handleSendableMessage(ctx context.Context, message *models.SendableMessage) error {
-  sent, err := c.deps.Telegram.SendMessage(ctx, &telegram.SendMessageParams{
+  params := &telegram.SendMessageParams{
     ChatID:    message.ChatId,
     Text:      strings.TrimSpace(message.Text.Value),
     ParseMode: tgmodels.ParseModeHTML,
-  })
+  }
+
+  if len(message.Buttons) != 0 {
+    params.ReplyMarkup = newInlineKeyboard(message.Buttons)
+  }
+
+  sent, err := c.deps.Telegram.SendMessage(ctx, params)
   if err != nil {
     return fmt.Errorf("c.deps.Telegram.SendMessage: %w", err)
   }
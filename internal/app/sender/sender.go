@@ -1,8 +1,11 @@
 package sender
 
 import (
+  "context"
+
   telegram "github.com/go-telegram/bot"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/ushakovn/outfit/internal/app/runs"
   "github.com/ushakovn/outfit/internal/models"
 )
 
@@ -17,16 +20,27 @@ type Config struct {
 }
 
 type Dependencies struct {
-  Telegram *telegram.Bot
-  Mongodb  *mongodb.Client
+  Telegram Telegram
+  Messages models.MessagesRepository
+  Runs     *runs.Recorder
+  Settings Settings
+}
+
+// Settings настройки, изменяемые без перезапуска.
+type Settings interface {
+  MaintenanceMode() bool
+  SenderWorkers() int
 }
 
-func NewSenderCron(typ models.ProductType, deps Dependencies) *Sender {
+type Telegram interface {
+  SendMessage(ctx context.Context, params *telegram.SendMessageParams) (*tgmodels.Message, error)
+}
+
+func NewSenderCron(config Config, deps Dependencies) *Sender {
+  config.IsCron = true
+
   return &Sender{
-    config: Config{
-      IsCron:      true,
-      ProductType: typ,
-    },
-    deps: deps,
+    config: config,
+    deps:   deps,
   }
 }
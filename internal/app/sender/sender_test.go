@@ -0,0 +1,227 @@
+package sender
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "slices"
+  "strconv"
+  "sync"
+  "testing"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/samber/lo"
+  "github.com/ushakovn/outfit/internal/deps/storage/memrepo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+var errChatBlocked = errors.New("bot was blocked by the user")
+
+// fakeTelegram запоминает отправленные сообщения и выдает им последовательные идентификаторы.
+type fakeTelegram struct {
+  mu     sync.Mutex
+  lastId int
+  sent   map[int64][]string
+  // errs ошибки отправки по идентификатору чата.
+  errs map[int64]error
+}
+
+func newFakeTelegram() *fakeTelegram {
+  return &fakeTelegram{
+    sent: make(map[int64][]string),
+    errs: make(map[int64]error),
+  }
+}
+
+func (f *fakeTelegram) SendMessage(_ context.Context, params *telegram.SendMessageParams) (*tgmodels.Message, error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+
+  chatId := params.ChatID.(int64)
+
+  if err := f.errs[chatId]; err != nil {
+    return nil, err
+  }
+
+  f.lastId++
+  f.sent[chatId] = append(f.sent[chatId], params.Text)
+
+  return &tgmodels.Message{ID: f.lastId}, nil
+}
+
+func (f *fakeTelegram) sentTexts(chatId int64) []string {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+
+  return append([]string(nil), f.sent[chatId]...)
+}
+
+type fakeSettings struct{}
+
+func (fakeSettings) MaintenanceMode() bool { return false }
+func (fakeSettings) SenderWorkers() int    { return 4 }
+
+func newTestMessage(chatId int64, index int) models.SendableMessage {
+  text := fmt.Sprintf("message %d", index)
+
+  return models.SendableMessage{
+    UUID:   strconv.FormatInt(chatId, 10) + "-" + strconv.Itoa(index),
+    ChatId: chatId,
+    Type:   models.ProductDiffSendableType,
+    Text:   models.SendableText{Value: text, SHA256: text},
+  }
+}
+
+func TestSenderScan(t *testing.T) {
+  const (
+    chatId        int64 = 1
+    blockedChatId int64 = 2
+  )
+
+  tests := []struct {
+    name     string
+    messages []models.SendableMessage
+    errs     map[int64]error
+
+    wantSent   map[int64][]string
+    wantUnsent []string
+  }{
+    {
+      name:     "unsent message is sent",
+      messages: []models.SendableMessage{newTestMessage(chatId, 1)},
+      wantSent: map[int64][]string{chatId: {"message 1"}},
+    },
+    {
+      name: "already sent message is skipped",
+      messages: []models.SendableMessage{
+        func() models.SendableMessage {
+          message := newTestMessage(chatId, 1)
+          message.SetAsSent(100)
+          return message
+        }(),
+        newTestMessage(chatId, 2),
+      },
+      wantSent: map[int64][]string{chatId: {"message 2"}},
+    },
+    {
+      name: "messages of one chat are sent in order",
+      messages: lo.Times(20, func(index int) models.SendableMessage {
+        return newTestMessage(chatId, index)
+      }),
+      wantSent: map[int64][]string{chatId: lo.Times(20, func(index int) string {
+        return fmt.Sprintf("message %d", index)
+      })},
+    },
+    {
+      name: "send failure keeps message unsent",
+      messages: []models.SendableMessage{
+        newTestMessage(blockedChatId, 1),
+        newTestMessage(chatId, 2),
+      },
+      errs:       map[int64]error{blockedChatId: errChatBlocked},
+      wantSent:   map[int64][]string{chatId: {"message 2"}},
+      wantUnsent: []string{"2-1"},
+    },
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      ctx := context.Background()
+
+      messages := memrepo.NewMessages()
+      for _, message := range tt.messages {
+        if _, err := messages.InsertIfNotExist(ctx, message); err != nil {
+          t.Fatalf("messages.InsertIfNotExist: %v", err)
+        }
+      }
+
+      tg := newFakeTelegram()
+      for chatId, err := range tt.errs {
+        tg.errs[chatId] = err
+      }
+
+      sender := NewSenderCron(Config{}, Dependencies{
+        Telegram: tg,
+        Messages: messages,
+        Settings: fakeSettings{},
+      })
+
+      if err := sender.scan(ctx); err != nil {
+        t.Fatalf("scan() error = %v", err)
+      }
+
+      for chatId, want := range tt.wantSent {
+        if got := tg.sentTexts(chatId); !slices.Equal(got, want) {
+          t.Fatalf("chat %d sent = %v, want %v", chatId, got, want)
+        }
+      }
+
+      var unsent []string
+
+      for _, message := range messages.List() {
+        if message.SentId == nil {
+          unsent = append(unsent, message.UUID)
+          continue
+        }
+        if message.Timestamps.SentAt == nil {
+          t.Fatalf("message %s has sent_id %d without sent_at", message.UUID, *message.SentId)
+        }
+      }
+
+      if !slices.Equal(unsent, tt.wantUnsent) {
+        t.Fatalf("unsent = %v, want %v", unsent, tt.wantUnsent)
+      }
+    })
+  }
+}
+
+func TestSenderScanWritesSentId(t *testing.T) {
+  ctx := context.Background()
+
+  messages := memrepo.NewMessages()
+  for index := 1; index <= 3; index++ {
+    if _, err := messages.InsertIfNotExist(ctx, newTestMessage(int64(index), index)); err != nil {
+      t.Fatalf("messages.InsertIfNotExist: %v", err)
+    }
+  }
+
+  sender := NewSenderCron(Config{}, Dependencies{
+    Telegram: newFakeTelegram(),
+    Messages: messages,
+    Settings: fakeSettings{},
+  })
+
+  if err := sender.scan(ctx); err != nil {
+    t.Fatalf("scan() error = %v", err)
+  }
+
+  sentIds := map[int]bool{}
+
+  for _, message := range messages.List() {
+    if message.SentId == nil {
+      t.Fatalf("message %s not marked as sent", message.UUID)
+    }
+    sentIds[*message.SentId] = true
+  }
+
+  // Каждому сообщению записан идентификатор, который вернул telegram.
+  for id := 1; id <= 3; id++ {
+    if !sentIds[id] {
+      t.Fatalf("sent ids = %v, want 1..3", sentIds)
+    }
+  }
+
+  // Повторный проход ничего не отправляет.
+  tg := newFakeTelegram()
+  sender.deps.Telegram = tg
+
+  if err := sender.scan(ctx); err != nil {
+    t.Fatalf("scan() error = %v", err)
+  }
+  for chatId := int64(1); chatId <= 3; chatId++ {
+    if sent := tg.sentTexts(chatId); len(sent) != 0 {
+      t.Fatalf("chat %d resent %v", chatId, sent)
+    }
+  }
+}
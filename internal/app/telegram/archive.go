@@ -0,0 +1,123 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strings"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// Кнопка возобновления отправляется кроном под оповещением об архивировании, поэтому префикс общий с трекером.
+const archivePrefix = models.ArchiveCallbackPrefix
+
+const trackingReactivatedText = `Отслеживание возобновлено ▶️
+Мы пришлем уведомление, как только получим новости по товару 📦`
+
+const trackingActiveText = `Отслеживание уже возобновлено ▶️`
+
+// handleArchiveCallback возобновляет архивное отслеживание кнопкой под оповещением об архивировании.
+func (b *Transport) handleArchiveCallback(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  query := update.CallbackQuery
+
+  chatId, ok := findChatIdInMaybeInaccessible(query.Message)
+  if !ok {
+    log.
+      WithField("callback_query.data", query.Data).
+      Warn("chat_id not found")
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  id := strings.TrimPrefix(query.Data, archivePrefix)
+
+  tracking, err := b.findTrackingById(ctx, chatId, id)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("tracking.id", id).
+      Errorf("b.findTrackingById: %v", err)
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  text := trackingActiveText
+
+  switch {
+  case tracking == nil:
+    text = trackingDeletedText
+
+  case tracking.Archive != nil:
+    if err = b.reactivateTracking(ctx, tracking); err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("tracking.url", tracking.URL).
+        Errorf("b.reactivateTracking: %v", err)
+
+      b.answerCallback(ctx, query.ID, "")
+      return
+    }
+
+    text = trackingReactivatedText
+  }
+
+  b.answerCallback(ctx, query.ID, text)
+
+  // Кнопка убирается, чтобы оповещение не предлагало возобновить отслеживание повторно.
+  _, err = b.deps.Telegram.EditMessageReplyMarkup(ctx, &telegram.EditMessageReplyMarkupParams{
+    ChatID:      chatId,
+    MessageID:   findMessageIdInMaybeInaccessible(query.Message),
+    ReplyMarkup: tgmodels.InlineKeyboardMarkup{InlineKeyboard: [][]tgmodels.InlineKeyboardButton{}},
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      Errorf("b.deps.Telegram.EditMessageReplyMarkup: %v", err)
+  }
+}
+
+// reactivateTracking возвращает отслеживание из архива. Истекший срок отслеживания сбрасывается,
+// иначе трекер сразу архивирует отслеживание снова.
+func (b *Transport) reactivateTracking(ctx context.Context, tracking *models.Tracking) error {
+  now := time.Now()
+
+  tracking.Archive = nil
+  tracking.Timestamps.FailedSince = nil
+  tracking.Timestamps.ReactivatedAt = &now
+
+  if err := b.deps.Trackings.UpdateArchive(ctx, tracking); err != nil {
+    return fmt.Errorf("b.deps.Trackings.UpdateArchive: %w", err)
+  }
+
+  if !tracking.Timestamps.IsExpired(now) {
+    return nil
+  }
+
+  tracking.Timestamps.ExpiresAt = nil
+
+  if err := b.updateTrackingExpiry(ctx, tracking); err != nil {
+    return fmt.Errorf("b.updateTrackingExpiry: %w", err)
+  }
+
+  return nil
+}
+
+// findTrackingById ищет отслеживание по идентификатору. Возвращает nil, если отслеживание не найдено.
+func (b *Transport) findTrackingById(ctx context.Context, chatId int64, id models.TrackingId) (*models.Tracking, error) {
+  tracking, err := b.deps.Trackings.GetById(ctx, chatId, id)
+  if err != nil {
+    if errors.Is(err, models.ErrNotFound) {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("b.deps.Trackings.GetById: %w", err)
+  }
+
+  return tracking, nil
+}
@@ -0,0 +1,956 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "html"
+  "io"
+  "net/http"
+  "runtime/debug"
+  "strings"
+  "sync"
+  "time"
+
+  set "github.com/deckarep/golang-set/v2"
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/tracker"
+  "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/stringer"
+  "github.com/ushakovn/outfit/pkg/worker"
+)
+
+const (
+  // bulkProgressInterval минимальный интервал между обновлениями сообщения о загрузке.
+  bulkProgressInterval = time.Second
+  // bulkFileMaxSize максимальный размер файла со ссылками.
+  bulkFileMaxSize = 64 << 10
+  bulkFileTimeout = 30 * time.Second
+  // bulkListLimit максимальное количество незагруженных ссылок в итоговом сообщении.
+  bulkListLimit = 10
+  // bulkLoadTimeout максимальная длительность загрузки товаров.
+  // Сессия, остающаяся в состоянии загрузки дольше, считается брошенной после перезапуска бота.
+  bulkLoadTimeout = 10 * time.Minute
+  // bulkResetTimeout время на сброс сессии, если загрузка прервана.
+  bulkResetTimeout = 5 * time.Second
+)
+
+type bulkStatus int
+
+const (
+  bulkStatusLoaded bulkStatus = iota
+  bulkStatusExists
+  bulkStatusUnsupported
+  bulkStatusFailed
+)
+
+type BulkConfig struct {
+  // Workers количество товаров, загружаемых одновременно.
+  Workers int `validate:"gt=0"`
+  // Limit максимальное количество ссылок, добавляемых одним сообщением или файлом.
+  Limit int `validate:"gt=0"`
+}
+
+type bulkResult struct {
+  URL      string
+  Status   bulkStatus
+  Tracking *models.Tracking
+}
+
+// handleTrackingBulkMenu загружает все товары из сообщения с несколькими ссылками.
+func (b *Transport) handleTrackingBulkMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if session.Entities == nil || session.Entities.Bulk == nil || len(session.Entities.Bulk.URLs) == 0 {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      WithField("session.entities", session.Entities).
+      Warn("message skipped")
+
+    return
+  }
+
+  b.startBulkLoad(ctx, chatId, session.Entities.Bulk.URLs)
+}
+
+// handleTrackingInputBulkFileMenu загружает все товары по ссылкам из текстового файла.
+func (b *Transport) handleTrackingInputBulkFileMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  text, errMessage := b.downloadBulkFile(ctx, update.Message.Document)
+
+  if errMessage == "" {
+    // Ссылки из подписи к файлу добавляются к ссылкам из файла.
+    text += "\n" + update.Message.Text
+
+    urls := lo.Uniq(stringer.ExtractURLs(text))
+
+    switch {
+    case len(urls) == 0:
+      errMessage = `В файле не найдено ни одной ссылки 😟
+Каждая ссылка на товар должна начинаться с https://`
+
+    case len(urls) > b.config.Bulk.Limit:
+      errMessage = fmt.Sprintf(`За один раз можно добавить до %d товаров 👀
+Разделите ссылки на несколько файлов и попробуйте еще раз 😉`, b.config.Bulk.Limit)
+
+    default:
+      b.startBulkLoad(ctx, chatId, urls)
+      return
+    }
+  }
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   errMessage,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+// downloadBulkFile возвращает содержимое текстового файла. При ошибке возвращается текст для пользователя.
+func (b *Transport) downloadBulkFile(ctx context.Context, document *tgmodels.Document) (text string, errMessage string) {
+  const invalidFileText = `Не удалось прочитать файл 😟
+Отправьте текстовый файл, в котором каждая ссылка на товар указана с новой строки`
+
+  if !strings.HasPrefix(document.MimeType, "text/") || document.FileSize > bulkFileMaxSize {
+    return "", invalidFileText
+  }
+
+  file, err := b.deps.Telegram.GetFile(ctx, &telegram.GetFileParams{FileID: document.FileID})
+  if err != nil {
+    log.
+      WithField("document.file_id", document.FileID).
+      Errorf("b.deps.Telegram.GetFile: %v", err)
+
+    return "", invalidFileText
+  }
+
+  content, err := downloadFile(ctx, b.deps.Telegram.FileDownloadLink(file))
+  if err != nil {
+    log.
+      WithField("document.file_id", document.FileID).
+      Errorf("downloadFile: %v", err)
+
+    return "", invalidFileText
+  }
+
+  return content, ""
+}
+
+func downloadFile(ctx context.Context, url string) (string, error) {
+  ctx, cancel := context.WithTimeout(ctx, bulkFileTimeout)
+  defer cancel()
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+  if err != nil {
+    return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+  }
+
+  resp, err := http.DefaultClient.Do(req)
+  if err != nil {
+    return "", fmt.Errorf("http.DefaultClient.Do: %w", err)
+  }
+  defer resp.Body.Close()
+
+  if resp.StatusCode != http.StatusOK {
+    return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+  }
+
+  content, err := io.ReadAll(io.LimitReader(resp.Body, bulkFileMaxSize))
+  if err != nil {
+    return "", fmt.Errorf("io.ReadAll: %w", err)
+  }
+
+  return string(content), nil
+}
+
+// startBulkLoad переводит сессию в состояние загрузки и загружает товары в фоне,
+// чтобы загрузка не удерживала блокировку чата. Сообщения и команды чата во время загрузки отклоняются.
+func (b *Transport) startBulkLoad(ctx context.Context, chatId int64, urls []string) {
+  err := b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingBulkLoadingMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  started := b.goBackground(func(ctx context.Context) {
+    defer func() {
+      if r := recover(); r != nil {
+        log.
+          WithField("chat_id", chatId).
+          WithField("menu", models.TrackingBulkLoadingMenu).
+          Errorf("b.loadBulkTrackings panic: %v\n%s", r, debug.Stack())
+
+        b.resetBulkSession(ctx, chatId)
+      }
+    }()
+
+    ctx, cancel := context.WithTimeout(ctx, bulkLoadTimeout)
+    defer cancel()
+
+    b.loadBulkTrackings(ctx, chatId, urls)
+  })
+  if !started {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Warn("transport stopped, bulk load skipped")
+
+    b.resetBulkSession(ctx, chatId)
+  }
+}
+
+// handleTrackingBulkLoadingMenu отвечает на сообщения, пока товары загружаются.
+// Если загрузка была прервана перезапуском бота, пользователь возвращается в главное меню.
+func (b *Transport) handleTrackingBulkLoadingMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if !isBulkLoading(session) {
+    b.handleStartSilentMenu(ctx, bot, update)
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Бот еще проверяет карточки товаров ⏳
+Дождитесь окончания загрузки 😉`,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+// rejectWhileBulkLoading отклоняет команды, пока товары загружаются.
+// Команды обрабатываются до маршрутизации по состоянию, а итог загрузки перезаписал бы сессию, начатую командой.
+func (b *Transport) rejectWhileBulkLoading(next telegram.HandlerFunc) telegram.HandlerFunc {
+  return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+    chatId, ok := findChatIdInUpdate(update)
+    if !ok {
+      next(ctx, bot, update)
+      return
+    }
+
+    session, err := b.findSession(ctx, chatId)
+    if err != nil {
+      if !errors.Is(err, models.ErrNotFound) {
+        log.
+          WithField("chat_id", chatId).
+          Errorf("b.findSession: %v", err)
+      }
+
+      next(ctx, bot, update)
+      return
+    }
+
+    ctx = withSession(ctx, session)
+
+    if isBulkLoading(session) {
+      b.handleTrackingBulkLoadingMenu(ctx, bot, update)
+      return
+    }
+
+    next(ctx, bot, update)
+  }
+}
+
+// isBulkLoading сообщает, что в чате выполняется загрузка товаров.
+// Сессия в состоянии загрузки дольше bulkLoadTimeout осталась от загрузки, прерванной перезапуском бота.
+func isBulkLoading(session *models.Session) bool {
+  return session.Message.Menu == models.TrackingBulkLoadingMenu && time.Since(session.UpdatedAt) <= bulkLoadTimeout
+}
+
+// resetBulkSession выводит сессию из состояния загрузки, если загрузка не завершилась.
+// Выполняется и после отмены ctx, чтобы сообщения чата не отклонялись до истечения bulkLoadTimeout.
+func (b *Transport) resetBulkSession(ctx context.Context, chatId int64) {
+  ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bulkResetTimeout)
+  defer cancel()
+
+  err := b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.StartSilentMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Не удалось загрузить товары 😟
+Пожалуйста, попробуйте еще раз`,
+    Reply: newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkLoadingMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+// loadBulkTrackings параллельно загружает карточки товаров и обновляет одно сообщение о ходе загрузки.
+// После загрузки пользователю предлагается выбрать общие для всех товаров размеры.
+func (b *Transport) loadBulkTrackings(ctx context.Context, chatId int64, urls []string) {
+  progress, err := b.deps.Telegram.SendMessage(ctx, &telegram.SendMessageParams{
+    ChatID:    chatId,
+    Text:      makeBulkProgressText(0, len(urls)),
+    ParseMode: tgmodels.ParseModeHTML,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.deps.Telegram.SendMessage: %v", err)
+
+    b.resetBulkSession(ctx, chatId)
+    return
+  }
+
+  // Ссылки, которые не успели поставить в очередь, считаются незагруженными.
+  results := lo.Map(urls, func(url string, _ int) bulkResult {
+    return bulkResult{URL: url, Status: bulkStatusFailed}
+  })
+
+  var (
+    mu       sync.Mutex
+    done     int
+    editedAt = time.Now()
+  )
+
+  pool := worker.NewPool(ctx, worker.Config{
+    Count: b.config.Bulk.Workers,
+  })
+
+  for index, url := range urls {
+    index, url := index, url
+
+    err = pool.Push(ctx, func(ctx context.Context) error {
+      result := b.loadBulkTracking(ctx, chatId, url)
+
+      mu.Lock()
+      defer mu.Unlock()
+
+      results[index] = result
+      done++
+
+      // Сообщение обновляется не чаще интервала, чтобы не упереться в ограничения telegram.
+      if time.Since(editedAt) >= bulkProgressInterval && done < len(urls) {
+        b.editMessageText(ctx, chatId, progress.ID, makeBulkProgressText(done, len(urls)), nil)
+        editedAt = time.Now()
+      }
+
+      return nil
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingBulkMenu).
+        Errorf("pool.Push: %v", err)
+
+      break
+    }
+  }
+
+  if err = pool.Drain(); err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("pool.Drain: %v", err)
+  }
+
+  // Загрузка прервана остановкой бота или превысила bulkLoadTimeout.
+  if err = ctx.Err(); err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Warnf("bulk load interrupted: %v", err)
+
+    b.resetBulkSession(ctx, chatId)
+    return
+  }
+
+  results = dedupeBulkResults(results)
+
+  b.editMessageText(ctx, chatId, progress.ID, makeBulkResultsText(results), nil)
+
+  trackings := lo.FilterMap(results, func(result bulkResult, _ int) (models.Tracking, bool) {
+    if result.Status != bulkStatusLoaded {
+      return models.Tracking{}, false
+    }
+    return *result.Tracking, true
+  })
+
+  if len(trackings) == 0 {
+    err = b.upsertSession(ctx, upsertSessionParams{
+      ChatId: chatId,
+      Menu:   models.StartSilentMenu,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingBulkMenu).
+        Errorf("b.upsertSession: %v", err)
+
+      return
+    }
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   `Не удалось загрузить ни одного нового товара 😟`,
+      Reply: newReplyKeyboard(
+        buttonHelp,
+        buttonTrackingMy,
+        buttonTrackingInsert,
+      ),
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingBulkMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Введите размеры, которые нужно отслеживать для всех товаров 📋
+
+Размеры необходимо вводить через запятую, например:
+S, M, L
+
+Если нужно отслеживать все размеры каждого товара, нажмите далее 😉`,
+    Reply: newReplyKeyboard(
+      buttonNext,
+      buttonBack,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingBulkMenu,
+    Entities: &models.SessionEntities{
+      Bulk: &models.SessionBulk{
+        Trackings: trackings,
+      },
+    },
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+// loadBulkTracking проверяет ссылку и получает карточку товара для нового отслеживания.
+func (b *Transport) loadBulkTracking(ctx context.Context, chatId int64, url string) bulkResult {
+  url = b.resolveProductURL(ctx, url)
+
+  if err := b.checkProductURL(url); err != nil {
+    if errors.Is(err, tracker.ErrUnsupportedProductType) || errors.Is(err, tracker.ErrDisabledProductType) {
+      return bulkResult{URL: url, Status: bulkStatusUnsupported}
+    }
+
+    log.
+      WithField("chat_id", chatId).
+      WithField("url", url).
+      Errorf("b.checkProductURL: %v", err)
+
+    return bulkResult{URL: url, Status: bulkStatusFailed}
+  }
+
+  same, err := b.findSameProductTracking(ctx, chatId, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("url", url).
+      Errorf("b.findSameProductTracking: %v", err)
+
+    return bulkResult{URL: url, Status: bulkStatusFailed}
+  }
+
+  if same != nil {
+    return bulkResult{URL: url, Status: bulkStatusExists}
+  }
+
+  message, err := b.createMessage(ctx, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("url", url).
+      Errorf("b.createMessage: %v", err)
+
+    return bulkResult{URL: url, Status: bulkStatusFailed}
+  }
+
+  return bulkResult{
+    URL:    url,
+    Status: bulkStatusLoaded,
+    Tracking: &models.Tracking{
+      ChatId: chatId,
+      URL:    url,
+      Sizes: models.ParseSizesParams{
+        Values: makeProductSizes(message.Product),
+      },
+      ParsedProduct: message.Product,
+    },
+  }
+}
+
+// dedupeBulkResults помечает повторные ссылки на один товар как уже отслеживаемые.
+func dedupeBulkResults(results []bulkResult) []bulkResult {
+  keys := set.NewThreadUnsafeSet[string]()
+
+  for index, result := range results {
+    if result.Status != bulkStatusLoaded {
+      continue
+    }
+
+    key := result.Tracking.ParsedProduct.Key
+    if key == "" {
+      key = result.URL
+    }
+
+    if !keys.Add(key) {
+      results[index].Status = bulkStatusExists
+    }
+  }
+
+  return results
+}
+
+func (b *Transport) handleTrackingInputBulkSizesMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  bulk, ok := findSessionBulk(session)
+  if !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      WithField("session.entities", session.Entities).
+      Warn("message skipped")
+
+    return
+  }
+
+  bulk.Sizes = parseBulkSizes(update.Message.Text)
+
+  if len(bulk.Sizes) == 0 {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text: `Не удалось найти список размеров 😟
+
+Пример корректного ввода 💬
+S, M, L
+
+Попробуйте еще раз 😉`,
+      Reply: newReplyKeyboard(
+        buttonNext,
+        buttonBack,
+      ),
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingBulkMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  text := fmt.Sprintf(`<b>Введенные вами размеры 📋</b>
+%s
+`, strings.Join(bulk.Sizes, ", "))
+
+  // Товары без введенных размеров в размерной сетке будут отслеживаться, но уведомления по ним могут не прийти.
+  missing := lo.CountBy(bulk.Trackings, func(tracking models.Tracking) bool {
+    return !lo.Some(makeProductSizes(tracking.ParsedProduct), bulk.Sizes)
+  })
+
+  if missing != 0 {
+    text += fmt.Sprintf(`
+Для %d из %d товаров этих размеров нет в размерной сетке 👀
+`, missing, len(bulk.Trackings))
+  }
+
+  text += `
+Если все верно, нажмите далее
+Или введите актуальные размеры заново 😉`
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply: newReplyKeyboard(
+      buttonNext,
+      buttonBack,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingBulkMenu,
+    Entities: session.Entities,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingBulkFlagMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkFlagMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkFlagMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if _, ok = findSessionBulk(session); !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkFlagMenu).
+      WithField("session.entities", session.Entities).
+      Warn("message skipped")
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `<b>Бот отсылает уведомления, когда:</b>
+1. Цена на товар была снижена или появилась скидка на товар 📉
+2. Распроданный товар снова появился в наличии 📦
+
+<b>Опционально, бот может отсылать уведомления, когда</b>:
+1. Цена на товар возросла 📈
+2. Количество товара сократилось 📦
+
+Включить опциональные уведомления для всех товаров?`,
+    Reply: newReplyKeyboard(
+      buttonTrackingFlagOn,
+      buttonTrackingFlagOff,
+      buttonBack,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkFlagMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingBulkFlagMenu,
+    Entities: session.Entities,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkFlagMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingBulkFlagOnMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.insertBulkTrackings(ctx, update, true)
+}
+
+func (b *Transport) handleTrackingBulkFlagOffMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.insertBulkTrackings(ctx, update, false)
+}
+
+// insertBulkTrackings создает отслеживания для загруженных товаров с общими размерами и уведомлениями.
+func (b *Transport) insertBulkTrackings(ctx context.Context, update *tgmodels.Update, flag bool) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingBulkConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  bulk, ok := findSessionBulk(session)
+  if !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkConfirmMenu).
+      WithField("session.entities", session.Entities).
+      Warn("message skipped")
+
+    return
+  }
+
+  // Сессия переводится до создания, чтобы повторное нажатие не создало отслеживания еще раз.
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingBulkConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  var inserted, exists, failed int
+
+  for _, tracking := range bulk.Trackings {
+    if len(bulk.Sizes) != 0 {
+      setTrackingSizes(&tracking, bulk.Sizes)
+    }
+    setTrackingFlag(&tracking, flag)
+
+    tracking.Timestamps.CreatedAt = time.Now()
+
+    if err = b.insertTracking(ctx, tracking); err != nil {
+      if errors.Is(err, models.ErrAlreadyExists) {
+        exists++
+        continue
+      }
+
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingBulkConfirmMenu).
+        WithField("tracking.url", tracking.URL).
+        Errorf("b.insertTracking: %v", err)
+
+      failed++
+      continue
+    }
+
+    inserted++
+  }
+
+  text := fmt.Sprintf(`Создано отслеживаний: %d 😉
+Мы пришлем уведомление, как только получим новости по товарам 📦`, inserted)
+
+  if exists != 0 {
+    text += fmt.Sprintf("\n\nУже отслеживались: %d", exists)
+  }
+  if failed != 0 {
+    text += fmt.Sprintf("\n\nНе удалось создать: %d 😟", failed)
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply: newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingBulkConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func findSessionBulk(session *models.Session) (*models.SessionBulk, bool) {
+  if session.Entities == nil || session.Entities.Bulk == nil || len(session.Entities.Bulk.Trackings) == 0 {
+    return nil, false
+  }
+  return session.Entities.Bulk, true
+}
+
+func parseBulkSizes(fields string) []string {
+  values := lo.Map(strings.Split(fields, ","), func(value string, _ int) string {
+    return strings.ReplaceAll(value, " ", "")
+  })
+  return lo.Uniq(lo.Compact(values))
+}
+
+func makeBulkProgressText(done, total int) string {
+  return fmt.Sprintf(`Бот проверяет карточки товаров 💬
+Готово %d из %d ⏳`, done, total)
+}
+
+func makeBulkResultsText(results []bulkResult) string {
+  counts := lo.CountValuesBy(results, func(result bulkResult) bulkStatus {
+    return result.Status
+  })
+
+  text := fmt.Sprintf(`<b>Карточки товаров проверены 📋</b>
+
+Загружено: %d`, counts[bulkStatusLoaded])
+
+  if count := counts[bulkStatusExists]; count != 0 {
+    text += fmt.Sprintf("\nУже отслеживаются: %d", count)
+  }
+  if count := counts[bulkStatusUnsupported]; count != 0 {
+    text += fmt.Sprintf("\nМагазин не поддерживается или недоступен: %d", count)
+  }
+  if count := counts[bulkStatusFailed]; count != 0 {
+    text += fmt.Sprintf("\nНе удалось загрузить: %d", count)
+  }
+
+  skipped := lo.Filter(results, func(result bulkResult, _ int) bool {
+    return result.Status == bulkStatusUnsupported || result.Status == bulkStatusFailed
+  })
+
+  if len(skipped) == 0 {
+    return text
+  }
+
+  text += "\n\n<b>Не добавлены 👀</b>"
+
+  for _, result := range lo.Slice(skipped, 0, bulkListLimit) {
+    text += "\n" + html.EscapeString(result.URL)
+  }
+
+  if len(skipped) > bulkListLimit {
+    text += fmt.Sprintf("\nИ еще %d", len(skipped)-bulkListLimit)
+  }
+
+  return text
+}
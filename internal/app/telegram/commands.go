@@ -0,0 +1,347 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strings"
+  "time"
+  "unicode"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// commandLanguages языки описаний команд в меню. Пустой код используется для остальных языков.
+var commandLanguages = []string{"", "ru", "en"}
+
+type command struct {
+  Name          string
+  Description   string
+  DescriptionEn string
+  Handler       telegram.HandlerFunc
+}
+
+func (c command) description(languageCode string) string {
+  if languageCode == "en" {
+    return c.DescriptionEn
+  }
+  return c.Description
+}
+
+func (b *Transport) newCommands() []command {
+  return []command{
+    {
+      Name:          "start",
+      Description:   "Главное меню",
+      DescriptionEn: "Main menu",
+      Handler:       b.handleStartCommand,
+    },
+    {
+      Name:          "add",
+      Description:   "Добавить отслеживание: /add <ссылка> [размеры]",
+      DescriptionEn: "Add a tracking: /add <url> [sizes]",
+      Handler:       b.handleAddCommand,
+    },
+    {
+      Name:          "list",
+      Description:   "Список отслеживаний",
+      DescriptionEn: "List trackings",
+      Handler:       b.handleTrackingListMenu,
+    },
+    {
+      Name:          "search",
+      Description:   "Поиск отслеживаний: /search <запрос>",
+      DescriptionEn: "Search trackings: /search <query>",
+      Handler:       b.handleSearchCommand,
+    },
+    {
+      Name:          "delete",
+      Description:   "Удалить отслеживание: /delete <ссылка>",
+      DescriptionEn: "Delete a tracking: /delete <url>",
+      Handler:       b.handleDeleteCommand,
+    },
+    {
+      Name:          "settings",
+      Description:   "Управление отслеживаниями",
+      DescriptionEn: "Manage trackings",
+      Handler:       b.handleTrackingMyMenu,
+    },
+    {
+      Name:          "help",
+      Description:   "Помощь",
+      DescriptionEn: "Help",
+      Handler:       b.handleStartMenu,
+    },
+  }
+}
+
+// setMyCommands регистрирует меню команд в telegram.
+func (b *Transport) setMyCommands(ctx context.Context) error {
+  commands := b.newCommands()
+
+  for _, languageCode := range commandLanguages {
+    botCommands := make([]tgmodels.BotCommand, 0, len(commands))
+
+    for _, cmd := range commands {
+      botCommands = append(botCommands, tgmodels.BotCommand{
+        Command:     cmd.Name,
+        Description: cmd.description(languageCode),
+      })
+    }
+
+    _, err := b.deps.Telegram.SetMyCommands(ctx, &telegram.SetMyCommandsParams{
+      Commands:     botCommands,
+      LanguageCode: languageCode,
+    })
+    if err != nil {
+      return fmt.Errorf("b.deps.Telegram.SetMyCommands: language_code: %q: %w", languageCode, err)
+    }
+  }
+
+  return nil
+}
+
+// parseCommand возвращает имя команды без косой черты и упоминания бота, а также ее аргументы.
+func parseCommand(text string) (name string, args string, ok bool) {
+  if !strings.HasPrefix(text, "/") {
+    return "", "", false
+  }
+
+  name = strings.TrimPrefix(text, "/")
+
+  if index := strings.IndexFunc(name, unicode.IsSpace); index >= 0 {
+    name, args = name[:index], strings.TrimSpace(name[index:])
+  }
+  name, _, _ = strings.Cut(name, "@")
+
+  return name, args, name != ""
+}
+
+func matchCommand(name string) telegram.MatchFunc {
+  return func(update *tgmodels.Update) bool {
+    if update.Message == nil {
+      return false
+    }
+    parsed, _, ok := parseCommand(update.Message.Text)
+
+    return ok && parsed == name
+  }
+}
+
+// withMessageText возвращает копию обновления с текстом аргументов команды для переиспользования обработчиков меню.
+func withMessageText(update *tgmodels.Update, text string) *tgmodels.Update {
+  message := *update.Message
+  message.Text = text
+  // Сущности и превью относятся к исходному тексту команды.
+  message.Entities = nil
+  message.LinkPreviewOptions = nil
+
+  copied := *update
+  copied.Message = &message
+
+  return &copied
+}
+
+func (b *Transport) handleAddCommand(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInsertMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  _, args, _ := parseCommand(update.Message.Text)
+  fields := strings.Fields(args)
+
+  switch len(fields) {
+  case 0:
+    b.handleTrackingInsertMenu(ctx, bot, update)
+    return
+
+  case 1:
+    // Без размеров продолжается пошаговое добавление с выбора размеров.
+    b.handleTrackingInputUrlMenu(ctx, bot, withMessageText(update, fields[0]))
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+  )
+
+  parsedUrl, message, ok := b.loadTrackingProduct(ctx, loadTrackingProductParams{
+    ChatId: chatId,
+    Text:   fields[0],
+    Menu:   models.TrackingInsertConfirmMenu,
+    Reply:  reply,
+  })
+  if !ok {
+    return
+  }
+
+  tracking := &models.Tracking{
+    ChatId: chatId,
+    URL:    parsedUrl,
+    Sizes: models.ParseSizesParams{
+      Values: parseCommandSizes(strings.Join(fields[1:], " ")),
+    },
+    ParsedProduct: message.Product,
+    Timestamps: models.TrackingTimestamps{
+      CreatedAt: time.Now(),
+    },
+  }
+
+  err := b.insertTracking(ctx, *tracking)
+  if err != nil {
+    if errors.Is(err, models.ErrAlreadyExists) {
+      err = b.sendMessage(ctx, sendMessageParams{
+        ChatId: chatId,
+        Text:   trackingExistsText,
+        Reply:  reply,
+      })
+      if err != nil {
+        log.
+          WithField("chat_id", chatId).
+          WithField("menu", models.TrackingInsertConfirmMenu).
+          Errorf("b.sendMessage: %v", err)
+      }
+      return
+    }
+
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.insertTracking: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingInsertConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   message.Text.Value,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   makeTrackingCreatedText(tracking),
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func (b *Transport) handleSearchCommand(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  _, args, _ := parseCommand(update.Message.Text)
+
+  if args == "" {
+    b.handleTrackingSearchInputMenu(ctx, bot, update)
+    return
+  }
+
+  b.handleTrackingSearchShowMenu(ctx, bot, withMessageText(update, args))
+}
+
+func (b *Transport) handleDeleteCommand(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingDeleteMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  _, args, _ := parseCommand(update.Message.Text)
+
+  // Без ссылки показывается список, в котором отслеживание удаляется кнопкой.
+  if args == "" {
+    b.handleTrackingListMenu(ctx, bot, update)
+    return
+  }
+
+  parsedUrl, errMessage := parseTrackingURL(args)
+
+  if errMessage != "" {
+    err := b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   errMessage,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingDeleteMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  b.handleTrackingDeleteMenu(ctx, bot, chatId, b.resolveProductURL(ctx, parsedUrl))
+}
+
+// parseCommandSizes разбирает размеры, перечисленные через запятую или пробел.
+func parseCommandSizes(fields string) (values []string) {
+  separator := ","
+  if !strings.Contains(fields, separator) {
+    separator = " "
+  }
+
+  for _, value := range strings.Split(fields, separator) {
+    value = strings.ReplaceAll(value, " ", "")
+
+    if value != "" {
+      values = append(values, value)
+    }
+  }
+
+  return values
+}
+
+func makeTrackingCreatedText(tracking *models.Tracking) (text string) {
+  sizes := strings.Join(tracking.Sizes.Values, ", ")
+
+  text = fmt.Sprintf(`Отслеживание для товара создано 😉
+
+<b>Отслеживаемые размеры 📋</b>
+%s
+`, sizes)
+
+  text += validateTrackingSizes(tracking.Sizes.Values, &models.Session{Tracking: tracking})
+
+  text += `
+Мы пришлем уведомление, как только получим новости по товару 📦`
+
+  return text
+}
@@ -11,19 +11,12 @@ import (
   set "github.com/deckarep/golang-set/v2"
   telegram "github.com/go-telegram/bot"
   tgmodels "github.com/go-telegram/bot/models"
-  tginline "github.com/go-telegram/ui/keyboard/inline"
-  tgreply "github.com/go-telegram/ui/keyboard/reply"
-  tgslider "github.com/go-telegram/ui/slider"
   "github.com/samber/lo"
   log "github.com/sirupsen/logrus"
-  "github.com/ushakovn/outfit/internal/app/telegram/assets"
   "github.com/ushakovn/outfit/internal/app/tracker"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   "github.com/ushakovn/outfit/internal/models"
-  "github.com/ushakovn/outfit/pkg/cache"
   "github.com/ushakovn/outfit/pkg/stringer"
   "github.com/ushakovn/outfit/pkg/validator"
-  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
   "golang.org/x/net/html"
 )
 
@@ -74,23 +67,9 @@ func setTrackingFlag(tracking *models.Tracking, flag bool) {
 }
 
 func (b *Transport) findSession(ctx context.Context, chatID int64) (*models.Session, error) {
-  res, err := b.deps.Mongodb.Get(ctx, mongodb.GetParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "sessions",
-      StructType: models.Session{},
-    },
-    Filters: map[string]any{
-      "chat_id": chatID,
-    },
-  })
+  session, err := b.deps.Sessions.Get(ctx, chatID)
   if err != nil {
-    return nil, fmt.Errorf("b.deps.Mongodb.Get: %w", err)
-  }
-
-  session, ok := res.(*models.Session)
-  if !ok {
-    return nil, fmt.Errorf("cast %v with type: %[1]T to: %T failed", res, new(models.Session))
+    return nil, fmt.Errorf("b.deps.Sessions.Get: %w", err)
   }
 
   return session, nil
@@ -112,34 +91,38 @@ func (b *Transport) deleteSessionMessage(ctx context.Context, session *models.Se
   return nil
 }
 
+// deleteTracking перемещает отслеживание в корзину, откуда его можно восстановить до окончательного удаления.
 func (b *Transport) deleteTracking(ctx context.Context, session *models.Session) error {
-  _, err := b.deps.Mongodb.Delete(ctx, mongodb.DeleteParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-    },
-    Filters: map[string]any{
-      "chat_id": session.Tracking.ChatId,
-      "url":     session.Tracking.URL,
-    },
+  now := time.Now()
+
+  err := b.deps.Trackings.Delete(ctx, models.DeleteTrackingParams{
+    ChatId:    session.Tracking.ChatId,
+    URL:       session.Tracking.URL,
+    DeletedAt: now,
+    PurgeAt:   now.Add(b.config.Trash.TTL),
   })
   if err != nil {
-    return fmt.Errorf("b.deps.Mongodb.Delete: %w", err)
+    return fmt.Errorf("b.deps.Trackings.Delete: %w", err)
   }
 
   return nil
 }
 
 func (b *Transport) insertTracking(ctx context.Context, tracking models.Tracking) error {
-  _, err := b.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-    },
-    Document: tracking,
-  })
-  if err != nil {
-    return fmt.Errorf("b.deps.Mongodb.Insert: %w", err)
+  if tracking.ProductKey == "" {
+    tracking.ProductKey = tracking.ParsedProduct.Key
+  }
+
+  if err := b.deps.Trackings.Insert(ctx, tracking); err != nil {
+    return fmt.Errorf("b.deps.Trackings.Insert: %w", err)
+  }
+
+  return nil
+}
+
+func (b *Transport) updateTrackingSettings(ctx context.Context, tracking *models.Tracking) error {
+  if err := b.deps.Trackings.UpdateSettings(ctx, tracking); err != nil {
+    return fmt.Errorf("b.deps.Trackings.UpdateSettings: %w", err)
   }
 
   return nil
@@ -168,12 +151,29 @@ func (b *Transport) sendMessage(ctx context.Context, params sendMessageParams) e
   return nil
 }
 
+func (b *Transport) editMessageText(ctx context.Context, chatId int64, messageId int, text string, reply tgmodels.ReplyMarkup) {
+  _, err := b.deps.Telegram.EditMessageText(ctx, &telegram.EditMessageTextParams{
+    ChatID:      chatId,
+    MessageID:   messageId,
+    Text:        text,
+    ParseMode:   tgmodels.ParseModeHTML,
+    ReplyMarkup: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("message_id", messageId).
+      Errorf("b.deps.Telegram.EditMessageText: %v", err)
+  }
+}
+
 type upsertSessionParams struct {
   ChatId    int64
   Menu      models.SessionMenu
   MessageID *int
   Tracking  *models.Tracking
   Entities  *models.SessionEntities
+  Editing   bool
 }
 
 func (b *Transport) upsertSession(ctx context.Context, params upsertSessionParams) error {
@@ -186,72 +186,58 @@ func (b *Transport) upsertSession(ctx context.Context, params upsertSessionParam
     Tracking:  params.Tracking,
     Entities:  params.Entities,
     UpdatedAt: time.Now(),
+    Editing:   params.Editing,
   }
 
-  _, err := b.deps.Mongodb.Upsert(ctx, mongodb.UpdateParams{
-    GetParams: mongodb.GetParams{
-      CommonParams: mongodb.CommonParams{
-        Database:   "outfit",
-        Collection: "sessions",
-        StructType: models.Session{},
-      },
-      Filters: map[string]any{
-        "chat_id": session.ChatId,
-      },
-    },
-    Document: session,
-  })
-  if err != nil {
-    return fmt.Errorf("b.deps.Mongodb.Upsert: %w", err)
+  if err := b.deps.Sessions.Upsert(ctx, session); err != nil {
+    return fmt.Errorf("b.deps.Sessions.Upsert: %w", err)
   }
 
   return nil
 }
 
 func (b *Transport) findTracking(ctx context.Context, chatId int64, url string) (*models.Tracking, error) {
-  res, err := b.deps.Mongodb.Get(ctx, mongodb.GetParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-      StructType: models.Tracking{},
-    },
-    Filters: map[string]any{
-      "url":     url,
-      "chat_id": chatId,
-    },
-  })
+  tracking, err := b.deps.Trackings.Get(ctx, chatId, url)
   if err != nil {
-    if errors.Is(err, mongodb.ErrNotFound) {
+    if errors.Is(err, models.ErrNotFound) {
       return nil, nil
     }
-    return nil, fmt.Errorf("b.deps.Mongodb.Get: %w", err)
+    return nil, fmt.Errorf("b.deps.Trackings.Get: %w", err)
   }
 
-  tracking, ok := res.(*models.Tracking)
-  if !ok {
-    return nil, fmt.Errorf("cast %v with type: %[1]T to: %T failed", res, new(models.Tracking))
+  return tracking, nil
+}
+
+// findSameProductTracking ищет отслеживание того же товара по ключу товара, а если ключ не определен, по ссылке.
+func (b *Transport) findSameProductTracking(ctx context.Context, chatId int64, url string) (*models.Tracking, error) {
+  key, err := b.deps.Tracker.FindProductKey(url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("url", url).
+      Warnf("b.deps.Tracker.FindProductKey: %v", err)
+
+    return b.findTracking(ctx, chatId, url)
+  }
+
+  tracking, err := b.deps.Trackings.GetByProductKey(ctx, chatId, key)
+  if err != nil {
+    if errors.Is(err, models.ErrNotFound) {
+      return b.findTracking(ctx, chatId, url)
+    }
+    return nil, fmt.Errorf("b.deps.Trackings.GetByProductKey: %w", err)
   }
 
   return tracking, nil
 }
 
 func (b *Transport) listTrackings(ctx context.Context, chatID int64) ([]*models.Tracking, error) {
-  res, err := b.deps.Mongodb.Find(ctx, mongodb.FindParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-      StructType: models.Tracking{},
-    },
-    Filters: map[string]any{
-      "chat_id": chatID,
-    },
-    Limit: 100,
-  })
+  list, err := b.deps.Trackings.List(ctx, chatID, b.config.ListLimit)
   if err != nil {
-    return nil, fmt.Errorf("b.deps.Mongodb.Find: %v", err)
+    return nil, fmt.Errorf("b.deps.Trackings.List: %w", err)
   }
 
-  return makeListTrackings(res)
+  return list, nil
 }
 
 func (b *Transport) checkProductURL(url string) error {
@@ -262,6 +248,21 @@ func (b *Transport) checkProductURL(url string) error {
   return nil
 }
 
+// resolveProductURL раскрывает короткие ссылки и ссылки приложений до проверки магазина и сохранения отслеживания.
+// Если раскрыть ссылку не удалось, возвращается исходная ссылка.
+func (b *Transport) resolveProductURL(ctx context.Context, url string) string {
+  resolved, err := b.deps.Resolver.Resolve(ctx, url)
+  if err != nil {
+    log.
+      WithField("url", url).
+      Warnf("b.deps.Resolver.Resolve: %v", err)
+
+    return url
+  }
+
+  return resolved
+}
+
 func (b *Transport) createMessage(ctx context.Context, url string) (*models.SendableMessage, error) {
   message, err := b.deps.Tracker.CreateMessage(ctx, tracker.CreateMessageParams{
     URL: url,
@@ -356,6 +357,40 @@ func parseSearchQuery(fields string) (query string) {
   return query
 }
 
+// dateLayout формат ввода и вывода дат в сообщениях бота.
+const dateLayout = "02.01.2006"
+
+// futureDateMaxTerm максимальный срок от текущего момента до вводимой даты.
+const futureDateMaxTerm = 365 * 24 * time.Hour
+
+// parseFutureDate разбирает дату позже сегодняшней. Возвращается начало указанного дня.
+func parseFutureDate(text string, now time.Time) (date time.Time, errMessage string) {
+  date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(text), time.Local)
+  if err != nil {
+    return time.Time{}, fmt.Sprintf(`Не удалось распознать дату 😟
+Введите дату в формате %s`, now.Format(dateLayout))
+  }
+
+  if !date.After(now) {
+    return time.Time{}, `Дата должна быть позже сегодняшней 📅`
+  }
+
+  if date.Sub(now) > futureDateMaxTerm {
+    return time.Time{}, `Дата должна быть не позже, чем через год 📅`
+  }
+
+  return date, ""
+}
+
+// makeTrackingTitle возвращает название товара отслеживания или ссылку, если название неизвестно.
+func makeTrackingTitle(tracking *models.Tracking) string {
+  title := strings.TrimSpace(tracking.ParsedProduct.Brand + " " + tracking.ParsedProduct.Category)
+  if title == "" {
+    return tracking.URL
+  }
+  return title
+}
+
 func makeProductSizes(product models.Product) (values []string) {
   values = lo.Map(product.Options, func(option models.ProductOption, _ int) string {
     return option.Size.Base.Value
@@ -411,6 +446,17 @@ func findChatIdInUpdate(update *tgmodels.Update) (int64, bool) {
   return 0, false
 }
 
+// findChatIdInAnyUpdate ищет чат в сообщении или в нажатии inline кнопки.
+func findChatIdInAnyUpdate(update *tgmodels.Update) (int64, bool) {
+  if chatId, ok := findChatIdInUpdate(update); ok {
+    return chatId, true
+  }
+  if update != nil && update.CallbackQuery != nil {
+    return findChatIdInMaybeInaccessible(update.CallbackQuery.Message)
+  }
+  return 0, false
+}
+
 func findChatIdInMaybeInaccessible(msg tgmodels.MaybeInaccessibleMessage) (int64, bool) {
   if msg.Message != nil && msg.Message.Chat.ID != 0 {
     return msg.Message.Chat.ID, true
@@ -421,172 +467,18 @@ func findChatIdInMaybeInaccessible(msg tgmodels.MaybeInaccessibleMessage) (int64
   return 0, false
 }
 
-func newInlineKeyboard(bot *telegram.Bot, prefix string) *tginline.Keyboard {
-  return tginline.New(bot,
-    tginline.OnError(func(err error) {
-      log.Errorf("telegram.InlineKeyboard: %v", err)
-    }),
-    tginline.WithPrefix(prefix),
-    tginline.NoDeleteAfterClick(),
-  )
-}
-
-func newReplyKeyboard(prefix string) *tgreply.ReplyKeyboard {
-  return tgreply.New(
-    tgreply.WithPrefix(prefix),
-    tgreply.IsOneTimeKeyboard(),
-    tgreply.ResizableKeyboard(),
-  )
-}
-
-type trackingSliderParams struct {
-  ChatId    int64
-  Bot       *telegram.Bot
-  Trackings []*models.Tracking
-}
-
-func (b *Transport) newTrackingSlider(params trackingSliderParams) *tgslider.Slider {
-  slides := make([]tgslider.Slide, 0, len(params.Trackings))
-
-  for _, tracking := range params.Trackings {
-    res := models.Sendable(params.ChatId).
-      SetTrackingPtr(tracking).
-      BuildTrackingMessage()
-
-    if !res.IsValid {
-      continue
-    }
-
-    slide := tgslider.Slide{
-      Text:  telegram.EscapeMarkdown(res.Message.Text.Value),
-      Photo: tracking.ParsedProduct.ImageURL,
-    }
-
-    if slide.Photo == "" {
-      slide.Photo = string(assets.NoPhoto)
-      slide.IsUpload = true
-    }
-
-    slides = append(slides, slide)
-  }
-
-  return tgslider.New(params.Bot, slides,
-    tgslider.OnError(func(err error) {
-      log.Errorf("telegram.TrackingSlider: %v", err)
-    }),
-    tgslider.WithPrefix("tracking"),
-    tgslider.OnSelect("Удалить", true, b.handleTrackingDeleteMenu),
-    tgslider.OnCancel("Назад", true, b.handleTrackingSilentMenu),
-  )
-}
-
 func (b *Transport) insertIssue(ctx context.Context, issue *models.Issue) error {
-  _, err := b.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "issues",
-    },
-    Document: issue,
-  })
-  if err != nil {
-    return fmt.Errorf("b.deps.Mongodb.Insert: %w", err)
+  if err := b.deps.Issues.Insert(ctx, *issue); err != nil {
+    return fmt.Errorf("b.deps.Issues.Insert: %w", err)
   }
 
   return nil
 }
 
-func (b *Transport) findTrackingInCache(chatId models.ChatId, index trackingIndex) (url string, ok bool) {
-  key := cache.Key[models.ChatId, trackingIndex]{
-    P: chatId,
-    S: index,
-  }
-  url, ok = b.deps.cache.trackings.Get(key)
-
-  b.deleteTrackingsCache(chatId)
-
-  return url, ok
-}
-
-func (b *Transport) fillTrackingsCache(chatId models.ChatId, list []*models.Tracking) {
-  b.deleteTrackingsCache(chatId)
-
-  for index, tracking := range list {
-    key := cache.Key[models.ChatId, trackingIndex]{
-      P: chatId,
-      S: index,
-    }
-    b.deps.cache.trackings.Set(key, tracking.URL)
-  }
-}
-
-func (b *Transport) deleteTrackingsCache(chatId models.ChatId) {
-  b.deps.cache.trackings.DeleteP(chatId)
-}
-
-func (b *Transport) searchTracking(ctx context.Context, query string) ([]*models.Tracking, error) {
-  res, err := b.deps.Mongodb.TextSearch(ctx, mongodb.TextSearchParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-      StructType: models.Tracking{},
-    },
-    Query: query,
-    Limit: 100,
-  })
-  if err != nil {
-    return nil, fmt.Errorf("b.deps.Mongodb.TextSearch: %w", err)
-  }
-
-  return makeListTrackings(res)
-}
-
-func (b *Transport) checkTrackingIndex(ctx context.Context) error {
-  _, err := b.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-      StructType: models.Tracking{},
-    },
-    Parts: []mongodb.IndexPart{
-      {
-        Field: "parsed_product.brand",
-        Type:  mongodb.IndexTypeText,
-      },
-      {
-        Field: "parsed_product.category",
-        Type:  mongodb.IndexTypeText,
-      },
-      {
-        Field: "parsed_product.description",
-        Type:  mongodb.IndexTypeText,
-      },
-      {
-        Field: "tracking.url",
-        Type:  mongodb.IndexTypeText,
-      },
-      {
-        Field: "tracking.comment",
-        Type:  mongodb.IndexTypeText,
-      },
-    },
-    Options: mongodbopts.Index().SetName("trackings_text_index"),
-  })
+func (b *Transport) searchTracking(ctx context.Context, chatId int64, query string) ([]*models.Tracking, error) {
+  list, err := b.deps.Trackings.Search(ctx, chatId, query, b.config.ListLimit)
   if err != nil {
-    return fmt.Errorf("b.deps.Mongodb.CreateIndex: %w", err)
-  }
-  return nil
-}
-
-func makeListTrackings(res []any) (list []*models.Tracking, err error) {
-  list = make([]*models.Tracking, 0, len(res))
-
-  for _, record := range res {
-    tracking, ok := record.(*models.Tracking)
-    if !ok {
-      return nil, fmt.Errorf("cast %v with type: %[1]T to: %T failed", record, new(models.Tracking))
-    }
-
-    list = append(list, tracking)
+    return nil, fmt.Errorf("b.deps.Trackings.Search: %w", err)
   }
 
   return list, nil
@@ -0,0 +1,299 @@
+package telegram
+
+import (
+  "context"
+  "crypto/hmac"
+  "crypto/sha256"
+  "encoding/base64"
+  "errors"
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+const (
+  // deepLinkIdSize размер идентификатора ссылки в байтах. В base64 занимает 16 символов.
+  deepLinkIdSize = 12
+  // deepLinkSignatureSize размер подписи ссылки в байтах. В base64 занимает 22 символа.
+  deepLinkSignatureSize = 16
+  // deepLinkCacheCapacity количество ссылок, повторное сохранение которых пропускается.
+  deepLinkCacheCapacity = 10_000
+)
+
+const deepLinkInvalidText = `Ссылка устарела или недействительна 😟
+Вы можете добавить отслеживание самостоятельно 💡`
+
+type DeepLinkConfig struct {
+  // Secret ключ подписи ссылок. Ссылки с другой подписью не принимаются без обращения к базе.
+  Secret string `validate:"required,min=32"`
+  // TTL время действия ссылки с момента последней выдачи.
+  TTL time.Duration `validate:"gt=0"`
+}
+
+func (c *DeepLinkConfig) Validate() error {
+  return validator.New().Struct(c)
+}
+
+type newDeepLinkParams struct {
+  URL          models.ProductURL
+  Sizes        []string
+  SourceChatId models.ChatId
+  TrackingId   models.TrackingId
+}
+
+// newDeepLinks сохраняет ссылки одним запросом и возвращает токены для /start в порядке params.
+// Идентификатор выводится из содержимого ссылки, поэтому повторная выдача той же ссылки продлевает ее, а не создает новую.
+// Ссылки, токены которых уже выданы, повторно не сохраняются.
+func (b *Transport) newDeepLinks(ctx context.Context, params []newDeepLinkParams) ([]string, error) {
+  now := time.Now()
+
+  tokens := make([]string, len(params))
+  ids := make([]models.DeepLinkId, len(params))
+  links := make([]models.DeepLink, 0, len(params))
+  missing := make(map[models.DeepLinkId]struct{}, len(params))
+
+  for index, link := range params {
+    id := b.makeDeepLinkId(link)
+    ids[index] = id
+
+    if token, ok := b.deepLinks.Get(id); ok {
+      tokens[index] = token
+      continue
+    }
+    if _, ok := missing[id]; ok {
+      continue
+    }
+    missing[id] = struct{}{}
+
+    links = append(links, models.DeepLink{
+      Id:           id,
+      URL:          link.URL,
+      Sizes:        link.Sizes,
+      SourceChatId: link.SourceChatId,
+      TrackingId:   link.TrackingId,
+      CreatedAt:    now,
+      ExpiresAt:    now.Add(b.config.DeepLink.TTL),
+    })
+  }
+
+  if len(links) == 0 {
+    return tokens, nil
+  }
+
+  if err := b.deps.DeepLinks.UpsertMany(ctx, links); err != nil {
+    return nil, fmt.Errorf("b.deps.DeepLinks.UpsertMany: %w", err)
+  }
+
+  for index, id := range ids {
+    if _, ok := missing[id]; !ok {
+      continue
+    }
+    tokens[index] = id + b.signDeepLinkId(id)
+    b.deepLinks.Set(id, tokens[index])
+  }
+
+  return tokens, nil
+}
+
+// findDeepLink возвращает ссылку по токену. Для поддельного, устаревшего или неизвестного токена возвращается nil.
+func (b *Transport) findDeepLink(ctx context.Context, token string) (*models.DeepLink, error) {
+  id, signature, ok := splitDeepLinkToken(token)
+  if !ok || !hmac.Equal([]byte(signature), []byte(b.signDeepLinkId(id))) {
+    return nil, nil
+  }
+
+  link, err := b.deps.DeepLinks.Get(ctx, id)
+  if err != nil {
+    if errors.Is(err, models.ErrNotFound) {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("b.deps.DeepLinks.Get: %w", err)
+  }
+
+  return link, nil
+}
+
+func (b *Transport) makeDeepLinkId(params newDeepLinkParams) models.DeepLinkId {
+  // Идентификатор подписывается секретом, чтобы по нему нельзя было подобрать чат, поделившийся ссылкой.
+  sum := b.deepLinkMAC("id",
+    strconv.FormatInt(params.SourceChatId, 10),
+    params.TrackingId,
+    params.URL,
+    strings.Join(params.Sizes, ","),
+  )
+  return base64.RawURLEncoding.EncodeToString(sum[:deepLinkIdSize])
+}
+
+func (b *Transport) signDeepLinkId(id models.DeepLinkId) string {
+  sum := b.deepLinkMAC("signature", id)
+
+  return base64.RawURLEncoding.EncodeToString(sum[:deepLinkSignatureSize])
+}
+
+func (b *Transport) deepLinkMAC(parts ...string) []byte {
+  mac := hmac.New(sha256.New, []byte(b.config.DeepLink.Secret))
+  mac.Write([]byte(strings.Join(parts, "\x00")))
+
+  return mac.Sum(nil)
+}
+
+func splitDeepLinkToken(token string) (id string, signature string, ok bool) {
+  idLength := base64.RawURLEncoding.EncodedLen(deepLinkIdSize)
+  signatureLength := base64.RawURLEncoding.EncodedLen(deepLinkSignatureSize)
+
+  if len(token) != idLength+signatureLength {
+    return "", "", false
+  }
+  return token[:idLength], token[idLength:], true
+}
+
+// newStartURL возвращает ссылку на бота, открывающую добавление отслеживания по токену.
+func (b *Transport) newStartURL(token string) string {
+  if token == "" {
+    return fmt.Sprintf("https://t.me/%s", b.username)
+  }
+  return fmt.Sprintf("https://t.me/%s?start=%s", b.username, token)
+}
+
+// handleStartCommand открывает главное меню или, если /start передан токен ссылки, добавление отслеживания из нее.
+func (b *Transport) handleStartCommand(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  _, token, _ := parseCommand(update.Message.Text)
+
+  if token == "" {
+    b.handleStartMenu(ctx, bot, update)
+    return
+  }
+
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.StartMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  link, err := b.findDeepLink(ctx, token)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartMenu).
+      Errorf("b.findDeepLink: %v", err)
+
+    return
+  }
+
+  if link == nil {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   deepLinkInvalidText,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.StartMenu).
+        Errorf("b.sendMessage: %v", err)
+
+      return
+    }
+
+    b.handleStartMenu(ctx, bot, update)
+    return
+  }
+
+  // Без размеров добавление продолжается с выбора размеров товара.
+  if len(link.Sizes) == 0 {
+    b.handleTrackingInputUrlMenu(ctx, bot, withMessageText(update, link.URL))
+    return
+  }
+
+  b.handleDeepLinkConfirmMenu(ctx, chatId, link)
+}
+
+// handleDeepLinkConfirmMenu загружает товар из ссылки и переходит к подтверждению отслеживания с размерами из нее.
+func (b *Transport) handleDeepLinkConfirmMenu(ctx context.Context, chatId int64, link *models.DeepLink) {
+  reply := newReplyKeyboard(
+    buttonTrackingInsertConfirm,
+    buttonTrackingComment,
+    buttonBack,
+  )
+
+  parsedUrl, message, ok := b.loadTrackingProduct(ctx, loadTrackingProductParams{
+    ChatId: chatId,
+    Text:   link.URL,
+    Menu:   models.TrackingFlagConfirmMenu,
+    Reply:  newReplyKeyboard(buttonHelp, buttonTrackingMy, buttonTrackingInsert),
+  })
+  if !ok {
+    return
+  }
+
+  tracking := &models.Tracking{
+    ChatId: chatId,
+    URL:    parsedUrl,
+    Sizes: models.ParseSizesParams{
+      Values: link.Sizes,
+    },
+    ParsedProduct: message.Product,
+    Timestamps: models.TrackingTimestamps{
+      CreatedAt: time.Now(),
+    },
+  }
+
+  err := b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingFlagConfirmMenu,
+    Tracking: tracking,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   message.Text.Value,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  text := fmt.Sprintf(`<b>Размеры из ссылки 📋</b>
+%s
+`, strings.Join(link.Sizes, ", "))
+
+  text += validateTrackingSizes(link.Sizes, &models.Session{Tracking: tracking})
+
+  text += `
+Вы можете оставить комментарий к отслеживанию 💡
+Если комментарий не требуется, просто подтвердите отслеживание 📨`
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
@@ -0,0 +1,212 @@
+package telegram
+
+import (
+  "context"
+  "fmt"
+  "strings"
+
+  telegram "github.com/go-telegram/bot"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+const trackingExistsText = `Отслеживание по данному товару уже существует ✉️
+Вы можете изменить его кнопкой «Изменить» в списке отслеживаний ✏️`
+
+const trackingDeletedText = `Отслеживание уже удалено 🗑️`
+
+// handleTrackingEditMenu запускает шаги добавления отслеживания для существующего отслеживания.
+// Изменяются только размеры, флаги и комментарий: распарсенный товар и время создания сохраняются,
+// чтобы следующий запуск трекера сравнил товар с прежним состоянием.
+func (b *Transport) handleTrackingEditMenu(ctx context.Context, bot *telegram.Bot, chatId int64, url models.ProductURL) {
+  tracking, err := b.findTracking(ctx, chatId, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingEditMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  if tracking == nil {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   trackingDeletedText,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingEditMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  // Аренда трекера не должна попасть в сессию.
+  tracking.Lease = nil
+
+  reply := newReplyKeyboard(
+    buttonNext,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   makeTrackingEditText(tracking),
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingEditMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingEditMenu,
+    Tracking: tracking,
+    Editing:  true,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingEditMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingEditConfirmMenu(ctx context.Context, chatId int64, session *models.Session) {
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+  )
+
+  stored, err := b.findTracking(ctx, chatId, session.Tracking.URL)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  // Сессия переводится до ответа, чтобы повторное нажатие не изменило отслеживание еще раз.
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingInsertConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  text := trackingDeletedText
+
+  if stored != nil {
+    err = b.updateTrackingSettings(ctx, session.Tracking)
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingInsertConfirmMenu).
+        Errorf("b.updateTrackingSettings: %v", err)
+
+      return
+    }
+
+    text = makeTrackingUpdatedText(session.Tracking)
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+// showExistingTracking отправляет карточку существующего отслеживания с кнопками изменения и удаления.
+func (b *Transport) showExistingTracking(ctx context.Context, chatId int64, tracking *models.Tracking) {
+  _, err := b.showSlider(ctx, showSliderParams{
+    ChatId:    chatId,
+    Trackings: []*models.Tracking{tracking},
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("tracking.url", tracking.URL).
+      Errorf("b.showSlider: %v", err)
+  }
+}
+
+func makeTrackingEditText(tracking *models.Tracking) (text string) {
+  text = `<b>Изменение отслеживания ✏️</b>
+`
+
+  if len(tracking.Sizes.Values) != 0 {
+    text += fmt.Sprintf(`
+<b>Отслеживаемые размеры 📋</b>
+%s
+`, strings.Join(tracking.Sizes.Values, ", "))
+  }
+
+  if tracking.Comment != "" {
+    text += fmt.Sprintf(`
+<b>Комментарий 💬</b>
+%s
+`, tracking.Comment)
+  }
+
+  sizesValues := makeProductSizes(tracking.ParsedProduct)
+
+  // Если товар имеет one size размер.
+  if len(sizesValues) <= 1 {
+    text += `
+Нажмите далее, чтобы перейти к настройке уведомлений 😉`
+
+    return text
+  }
+
+  text += fmt.Sprintf(`
+<b>Доступные размеры 📋:</b>
+%s
+
+Введите новые размеры через запятую, в точности так, как указано в списке, или нажмите далее, чтобы оставить текущие 😉`,
+    strings.Join(sizesValues, ", "))
+
+  return text
+}
+
+func makeTrackingUpdatedText(tracking *models.Tracking) (text string) {
+  sizes := strings.Join(tracking.Sizes.Values, ", ")
+
+  text = fmt.Sprintf(`Отслеживание для товара изменено 😉
+
+<b>Отслеживаемые размеры 📋</b>
+%s
+`, sizes)
+
+  text += validateTrackingSizes(tracking.Sizes.Values, &models.Session{Tracking: tracking})
+
+  text += `
+История товара сохранена, уведомления придут по новым параметрам 📦`
+
+  return text
+}
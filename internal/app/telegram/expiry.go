@@ -0,0 +1,228 @@
+package telegram
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// handleTrackingExpiryMenu предлагает ввести дату, после которой отслеживание переносится в архив.
+func (b *Transport) handleTrackingExpiryMenu(ctx context.Context, bot *telegram.Bot, chatId int64, url models.ProductURL) {
+  tracking, err := b.findTracking(ctx, chatId, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  if tracking == nil {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   trackingDeletedText,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingExpiryMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  // Аренда трекера не должна попасть в сессию.
+  tracking.Lease = nil
+
+  text := fmt.Sprintf(`Введите дату, до которой отслеживать товар, например, до конца распродажи 📅
+После этой даты отслеживание будет перенесено в архив
+
+<b>Пример ввода 💬</b>
+%s`, time.Now().Add(trackingPauseWeek).Format(dateLayout))
+
+  reply := newReplyKeyboard(buttonBack)
+
+  if expiresAt := tracking.Timestamps.ExpiresAt; expiresAt != nil {
+    text = fmt.Sprintf(`Сейчас товар отслеживается до %s ⏳
+
+%s`, expiresAt.Local().Format(models.MessageTimeLayout), text)
+
+    reply = newReplyKeyboard(
+      buttonTrackingExpiryReset,
+      buttonBack,
+    )
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingExpiryMenu,
+    Tracking: tracking,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputExpiryMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingExpiryMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  date, errMessage := parseFutureDate(update.Message.Text, time.Now())
+
+  if errMessage != "" {
+    err := b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   errMessage,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingExpiryMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  // Указанный день отслеживается целиком.
+  b.setTrackingExpiry(ctx, update, date.AddDate(0, 0, 1))
+}
+
+func (b *Transport) handleTrackingExpiryResetMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.setTrackingExpiry(ctx, update, time.Time{})
+}
+
+// setTrackingExpiry сохраняет срок для отслеживания из сессии. Нулевое время снимает срок.
+func (b *Transport) setTrackingExpiry(ctx context.Context, update *tgmodels.Update, expiresAt time.Time) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if session.Tracking == nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      WithField("session.tracking", session.Tracking).
+      Warn("message skipped")
+
+    return
+  }
+
+  tracking, err := b.findTracking(ctx, chatId, session.Tracking.URL)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingExpiryConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  text := trackingDeletedText
+
+  if tracking != nil {
+    tracking.Timestamps.ExpiresAt = nil
+    text = `Срок отслеживания снят ♾`
+
+    if !expiresAt.IsZero() {
+      tracking.Timestamps.ExpiresAt = &expiresAt
+      text = fmt.Sprintf(`Товар отслеживается до %s ⏳
+После этого отслеживание будет перенесено в архив, а мы пришлем уведомление`,
+        expiresAt.Format(models.MessageTimeLayout))
+    }
+
+    if err = b.updateTrackingExpiry(ctx, tracking); err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingExpiryConfirmMenu).
+        Errorf("b.updateTrackingExpiry: %v", err)
+
+      return
+    }
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply: newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingExpiryConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func (b *Transport) updateTrackingExpiry(ctx context.Context, tracking *models.Tracking) error {
+  if err := b.deps.Trackings.UpdateExpiry(ctx, tracking); err != nil {
+    return fmt.Errorf("b.deps.Trackings.UpdateExpiry: %w", err)
+  }
+
+  return nil
+}
@@ -0,0 +1,202 @@
+package telegram
+
+import (
+  "context"
+  "strings"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+const (
+  // inlineResultsLimit максимальное количество результатов, принимаемое telegram в ответе на inline запрос.
+  inlineResultsLimit = 50
+  // inlineCacheTime время кэширования результатов в секундах. Отслеживания меняются часто, поэтому кэш короткий.
+  inlineCacheTime = 10
+)
+
+const inlineShareButtonText = "Отслеживать тоже"
+
+func isInlineQueryUpdate(update *tgmodels.Update) bool {
+  return update.InlineQuery != nil && update.InlineQuery.From != nil
+}
+
+// handleInlineQuery отвечает на запрос @бот <запрос> отслеживаниями пользователя.
+// Для личного чата с ботом идентификатор чата совпадает с идентификатором пользователя.
+// Inline режим должен быть включен для бота в BotFather.
+func (b *Transport) handleInlineQuery(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  query := update.InlineQuery
+  chatId := query.From.ID
+
+  list, err := b.findInlineTrackings(ctx, chatId, query.Query)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("inline_query.query", query.Query).
+      Errorf("b.findInlineTrackings: %v", err)
+
+    return
+  }
+
+  list = lo.Slice(list, 0, inlineResultsLimit)
+
+  shareURLs := b.newShareURLs(ctx, chatId, list)
+
+  results := make([]tgmodels.InlineQueryResult, 0, len(list))
+
+  for index, tracking := range list {
+    if result, ok := newInlineResult(chatId, tracking, shareURLs[index]); ok {
+      results = append(results, result)
+    }
+  }
+
+  _, err = b.deps.Telegram.AnswerInlineQuery(ctx, &telegram.AnswerInlineQueryParams{
+    InlineQueryID: query.ID,
+    Results:       results,
+    CacheTime:     inlineCacheTime,
+    IsPersonal:    true,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("inline_query.id", query.ID).
+      Errorf("b.deps.Telegram.AnswerInlineQuery: %v", err)
+  }
+}
+
+// findInlineTrackings ищет отслеживания по запросу. Для пустого запроса возвращается весь список.
+func (b *Transport) findInlineTrackings(ctx context.Context, chatId int64, query string) ([]*models.Tracking, error) {
+  if query = parseSearchQuery(query); query == "" {
+    return b.listTrackings(ctx, chatId)
+  }
+  return b.searchTracking(ctx, chatId, query)
+}
+
+func newInlineResult(chatId int64, tracking *models.Tracking, shareURL string) (tgmodels.InlineQueryResult, bool) {
+  // Комментарий, пауза, архив и срок личные, поэтому не попадают в карточку, которой делятся в других чатах.
+  shared := *tracking
+  shared.Comment = ""
+  shared.Pause = models.TrackingPause{}
+  shared.Archive = nil
+  shared.Timestamps.ExpiresAt = nil
+
+  res := models.Sendable(chatId).
+    SetTrackingPtr(&shared).
+    BuildTrackingMessage()
+
+  if !res.IsValid {
+    return nil, false
+  }
+
+  product := tracking.ParsedProduct
+
+  title := makeTrackingTitle(tracking)
+
+  description := makeInlineDescription(tracking)
+  text := strings.TrimSpace(res.Message.Text.Value)
+
+  if price := findTrackingPrice(tracking); price != "" {
+    text += "\nЦена: " + price
+  }
+
+  reply := tgmodels.InlineKeyboardMarkup{
+    InlineKeyboard: [][]tgmodels.InlineKeyboardButton{
+      {
+        {Text: inlineShareButtonText, URL: shareURL},
+      },
+    },
+  }
+
+  if product.ImageURL == "" {
+    return &tgmodels.InlineQueryResultArticle{
+      ID:          tracking.Id(),
+      Title:       title,
+      Description: description,
+      InputMessageContent: &tgmodels.InputTextMessageContent{
+        MessageText: text,
+      },
+      ReplyMarkup: reply,
+    }, true
+  }
+
+  return &tgmodels.InlineQueryResultPhoto{
+    ID:           tracking.Id(),
+    PhotoURL:     product.ImageURL,
+    ThumbnailURL: product.ImageURL,
+    Title:        title,
+    Description:  description,
+    Caption:      text,
+    ReplyMarkup:  reply,
+  }, true
+}
+
+// newShareURLs возвращает ссылки для кнопок «Отслеживать тоже», открывающие подтверждение отслеживания с теми же размерами.
+// Ссылки всех результатов сохраняются одним запросом. Если ссылки сохранить не удалось, кнопки ведут на бота.
+func (b *Transport) newShareURLs(ctx context.Context, chatId int64, list []*models.Tracking) []string {
+  params := lo.Map(list, func(tracking *models.Tracking, _ int) newDeepLinkParams {
+    return newDeepLinkParams{
+      URL:          tracking.URL,
+      Sizes:        tracking.Sizes.Values,
+      SourceChatId: chatId,
+      TrackingId:   tracking.Id(),
+    }
+  })
+
+  tokens, err := b.newDeepLinks(ctx, params)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("trackings.count", len(list)).
+      Errorf("b.newDeepLinks: %v", err)
+
+    tokens = make([]string, len(list))
+  }
+
+  return lo.Map(tokens, func(token string, _ int) string {
+    return b.newStartURL(token)
+  })
+}
+
+func makeInlineDescription(tracking *models.Tracking) string {
+  var parts []string
+
+  if price := findTrackingPrice(tracking); price != "" {
+    parts = append(parts, price)
+  }
+  if len(tracking.Sizes.Values) != 0 {
+    parts = append(parts, "Размеры: "+strings.Join(tracking.Sizes.Values, ", "))
+  }
+
+  return strings.Join(parts, " · ")
+}
+
+// findTrackingPrice возвращает минимальную цену среди отслеживаемых размеров или всех размеров товара.
+func findTrackingPrice(tracking *models.Tracking) string {
+  sizes := make(map[string]struct{}, len(tracking.Sizes.Values))
+  for _, value := range tracking.Sizes.Values {
+    sizes[value] = struct{}{}
+  }
+
+  var found *models.ProductPrice
+
+  for _, option := range tracking.ParsedProduct.Options {
+    if option.Size.NotFoundSize != nil || option.Price.Discount.IntValue <= 0 {
+      continue
+    }
+    if _, ok := sizes[option.Size.Base.Value]; len(sizes) != 0 && !ok {
+      continue
+    }
+    if found == nil || option.Price.Discount.IntValue < found.IntValue {
+      price := option.Price.Discount
+      found = &price
+    }
+  }
+
+  if found == nil {
+    return ""
+  }
+  return found.StringValue
+}
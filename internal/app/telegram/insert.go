@@ -0,0 +1,819 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "strings"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/tracker"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func (b *Transport) handleTrackingInsertMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInsertMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Введите ссылку на товар 📦
+
+Чтобы добавить сразу несколько товаров, отправьте ссылки одним сообщением или текстовым файлом 📋`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingInsertMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputUrlMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInputUrlMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  text, ok := b.chooseTrackingURL(ctx, chatId, update.Message)
+  if !ok {
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  parsedUrl, message, ok := b.loadTrackingProduct(ctx, loadTrackingProductParams{
+    ChatId: chatId,
+    Text:   text,
+    Menu:   models.TrackingInputUrlMenu,
+    Reply:  reply,
+  })
+  if !ok {
+    return
+  }
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   message.Text.Value,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputUrlMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  sizesValues := makeProductSizes(message.Product)
+  sizesCount := len(sizesValues)
+
+  // Если товар имеет one size размер.
+  if sizesCount <= 1 {
+    reply = newReplyKeyboard(
+      buttonNext,
+      buttonBack,
+    )
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text: `<b>Проверьте полученные от бота данные</b>
+Нажмите далее, если все хорошо 😉`,
+      Reply: reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingInputUrlMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    // Если товар имеет нормальную размерную сетку.
+  } else {
+    text := `<b>Проверьте полученные от бота данные</b>
+
+Если все хорошо, выберите необходимые размеры из списка 📋
+
+<b>Доступные размеры 📋:</b> 
+`
+    sizesString := strings.Join(sizesValues, ", ")
+    text += strings.TrimSpace(sizesString)
+
+    text += `
+
+Размеры необходимо вводить через запятую, в точности так, как указано в списке
+
+Кстати, вы можете ввести размер, которого нет в списке, если точно знаете, что такой существует и может появиться в наличии на сайте 😉
+
+<b>Пример корректного ввода 💬</b>
+`
+
+    text += makeCutSizeValuesString(sizesValues)
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   text,
+      Reply:  reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingInputUrlMenu).
+        Errorf("b.sendMessage: %v", err)
+
+      return
+    }
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingInputUrlMenu,
+    Tracking: &models.Tracking{
+      ChatId: chatId,
+      URL:    parsedUrl,
+      Sizes: models.ParseSizesParams{
+        Values: sizesValues,
+      },
+      ParsedProduct: message.Product,
+      Timestamps: models.TrackingTimestamps{
+        CreatedAt: time.Now(),
+      },
+    },
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputUrlMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputSizesMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInputSizesMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputSizesMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  sizesValues, errMessage := parseTrackingSizes(update.Message.Text, session)
+
+  if errMessage != "" {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   errMessage,
+      Reply:  reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingInputSizesMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  reply = newReplyKeyboard(
+    buttonNext,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   makeTrackingSizesText(sizesValues, session),
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputSizesMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  setTrackingSizes(session.Tracking, sizesValues)
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingInputUrlMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputSizesMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputFlagMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInputFlagMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputFlagMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingFlagOn,
+    buttonTrackingFlagOff,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `<b>Бот отсылает уведомления, когда:</b>
+1. Цена на товар была снижена или появилась скидка на товар 📉
+2. Распроданный товар снова появился в наличии 📦
+
+<b>Опционально, бот может отсылать уведомления, когда</b>:
+1. Цена на товар возросла 📈
+2. Количество товара сократилось 📦
+
+Включить опциональные уведомления?`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputFlagMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingInputFlagMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputFlagMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingFlagOnMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  setTrackingFlag(session.Tracking, true)
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingFlagConfirmMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingInsertConfirm,
+    buttonTrackingComment,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Опциональные уведомления включены 
+
+Далее, вы можете оставить комментарий к вашему отслеживанию 💡
+Он будет отображаться при просмотре списка отслеживаний и получении уведомлений по товару 💬
+
+Если комментарий не требуется, просто подтвердите отслеживание 📨`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func (b *Transport) handleTrackingFlagOffMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  setTrackingFlag(session.Tracking, false)
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingFlagConfirmMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingInsertConfirm,
+    buttonTrackingComment,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Опциональные уведомления отключены 
+
+Далее, вы можете оставить комментарий к вашему отслеживанию 💡
+Он будет отображаться при просмотре списка отслеживаний и получении уведомлений по товару 💬 
+
+Если комментарий не требуется, просто подтвердите отслеживание 📨`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingFlagConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func (b *Transport) handleTrackingCommentMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingCommentMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingCommentMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingInsertConfirm,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Пример ввода 💬
+
+Кепка Stussy черная 
+#stussy #кепка #kixbox
+
+Длина комментария может быть до 100 символов`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingCommentMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingCommentMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingCommentMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputCommentMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInputCommentMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputCommentMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingInsertConfirm,
+    buttonBack,
+  )
+
+  parsedComment, errMessage := parseTrackingComment(update.Message.Text)
+
+  if errMessage != "" {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   errMessage,
+      Reply:  reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingInputCommentMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  session.Tracking.Comment = parsedComment
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingInputCommentMenu,
+    Tracking: session.Tracking,
+    Editing:  session.Editing,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInputCommentMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Комментарий успешно сохранен 😉
+Осталось подтвердить отслеживание 📨`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingCommentMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+func (b *Transport) handleTrackingInsertConfirmMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if session.Tracking == nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      WithField("session.tracking", session.Tracking).
+      Warn("message skipped")
+
+    return
+  }
+
+  if session.Editing {
+    b.handleTrackingEditConfirmMenu(ctx, chatId, session)
+    return
+  }
+
+  err = b.insertTracking(ctx, *session.Tracking)
+  if err != nil {
+    if errors.Is(err, models.ErrAlreadyExists) {
+      reply := newReplyKeyboard(
+        buttonTrackingMy,
+        buttonBack,
+      )
+
+      err = b.sendMessage(ctx, sendMessageParams{
+        ChatId: chatId,
+        Text:   trackingExistsText,
+        Reply:  reply,
+      })
+      if err != nil {
+        log.
+          WithField("chat_id", chatId).
+          WithField("menu", models.TrackingInsertConfirmMenu).
+          Errorf("b.sendMessage: %v", err)
+      }
+      return
+    }
+
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.insertTracking: %v", err)
+
+    return
+  }
+
+  // Сессия переводится до ответа, чтобы повторное нажатие не создало отслеживание еще раз.
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingInsertConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Отслеживание для товара создано 😉
+Мы пришлем уведомление, как только получим новости по товару 📦`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+}
+
+type loadTrackingProductParams struct {
+  ChatId int64
+  Text   string
+  Menu   models.SessionMenu
+  Reply  tgmodels.ReplyMarkup
+}
+
+// loadTrackingProduct проверяет ссылку из текста и получает карточку товара для нового отслеживания.
+// При ошибке пользователь получает объяснение, а ok равен false.
+func (b *Transport) loadTrackingProduct(ctx context.Context, params loadTrackingProductParams) (string, *models.SendableMessage, bool) {
+  parsedUrl, errMessage := parseTrackingURL(params.Text)
+
+  if errMessage != "" {
+    err := b.sendMessage(ctx, sendMessageParams{
+      ChatId: params.ChatId,
+      Text:   errMessage,
+      Reply:  params.Reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", params.ChatId).
+        WithField("menu", params.Menu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return "", nil, false
+  }
+
+  parsedUrl = b.resolveProductURL(ctx, parsedUrl)
+
+  if err := b.checkProductURL(parsedUrl); err != nil {
+    if errors.Is(err, tracker.ErrUnsupportedProductType) {
+      err = b.sendMessage(ctx, sendMessageParams{
+        ChatId: params.ChatId,
+        Text:   `Извините, бот пока не умеет работать с данным сайтом 😟`,
+        Reply:  params.Reply,
+      })
+      if err != nil {
+        log.
+          WithField("chat_id", params.ChatId).
+          WithField("menu", params.Menu).
+          Errorf("b.sendMessage: %v", err)
+      }
+      return "", nil, false
+    }
+
+    if errors.Is(err, tracker.ErrDisabledProductType) {
+      err = b.sendMessage(ctx, sendMessageParams{
+        ChatId: params.ChatId,
+        Text: `Извините, магазин временно недоступен 😟
+Попробуйте добавить отслеживание позже 🕙`,
+        Reply: params.Reply,
+      })
+      if err != nil {
+        log.
+          WithField("chat_id", params.ChatId).
+          WithField("menu", params.Menu).
+          Errorf("b.sendMessage: %v", err)
+      }
+      return "", nil, false
+    }
+
+    log.
+      WithField("chat_id", params.ChatId).
+      WithField("menu", params.Menu).
+      Errorf("checkProductURL: %v", err)
+
+    return "", nil, false
+  }
+
+  // Дубликат ищется по ключу товара, поэтому ссылка с другими параметрами не создает второе отслеживание.
+  tracking, err := b.findSameProductTracking(ctx, params.ChatId, parsedUrl)
+  if err != nil {
+    log.
+      WithField("chat_id", params.ChatId).
+      WithField("menu", params.Menu).
+      Errorf("b.findSameProductTracking: %v", err)
+
+    return "", nil, false
+  }
+
+  if tracking != nil {
+    b.showExistingTracking(ctx, params.ChatId, tracking)
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: params.ChatId,
+      Text:   trackingExistsText,
+      Reply:  params.Reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", params.ChatId).
+        WithField("menu", params.Menu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return "", nil, false
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: params.ChatId,
+    Text:   `Сейчас бот проверит карточку товара и вернется 💬`,
+    Reply:  params.Reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", params.ChatId).
+      WithField("menu", params.Menu).
+      Errorf("b.sendMessage: %v", err)
+
+    return "", nil, false
+  }
+
+  message, err := b.createMessage(ctx, parsedUrl)
+  if err != nil {
+    log.
+      WithField("chat_id", params.ChatId).
+      WithField("menu", params.Menu).
+      Errorf("b.createMessage: %v", err)
+
+    b.sendErrorMessage(ctx, sendErrorMessageParams{
+      ChatId: params.ChatId,
+      Text: `<b>Бот не смог получить данные 😟</b>
+
+Убедитесь, что страница точно указывает на карточку товара
+Если все верно, и ошибка повторится снова, обратитесь в поддержку 👨‍💻
+`,
+      Menu: params.Menu,
+    })
+
+    return "", nil, false
+  }
+
+  return parsedUrl, message, true
+}
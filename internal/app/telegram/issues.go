@@ -0,0 +1,271 @@
+package telegram
+
+import (
+  "context"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func (b *Transport) handleInsertIssueMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.IssueInsertMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonIssueStory,
+    buttonIssueBug,
+    buttonBack,
+  )
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Выберите категорию обратной связи: 
+Баг или Улучшение 😉`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.IssueInsertMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleIssueInputBugMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.IssueInputTypeMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Опишите возникшую у вас проблему 💬`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTypeMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.IssueInputTypeMenu,
+    Entities: &models.SessionEntities{
+      Issue: &models.Issue{
+        ChatId:    chatId,
+        Type:      models.IssueTypeBug,
+        CreatedAt: time.Now(),
+      },
+    },
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTypeMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleIssueInputStoryMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.IssueInputTypeMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Опишите улучшения бота, которые вам хотелось бы видеть 💬`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTypeMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.IssueInputTypeMenu,
+    Entities: &models.SessionEntities{
+      Issue: &models.Issue{
+        ChatId:    chatId,
+        Type:      models.IssueTypeStory,
+        CreatedAt: time.Now(),
+      },
+    },
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTypeMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleIssueInputTextMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.IssueInputTextMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTextMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  session.Entities.Issue.Text = parseIssueText(update.Message.Text)
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.IssueInputTextMenu,
+    Entities: session.Entities,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInputTextMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonIssueInsertConfirm,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Мы получили ваше сообщение, осталось его подтвердить 📧`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleIssueInsertConfirmMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.IssueInsertConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  err = b.insertIssue(ctx, session.Entities.Issue)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertConfirmMenu).
+      Errorf("b.insertIssue: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Спасибо за вашу обратную связь 😉`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.IssueInsertConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.IssueInsertConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
@@ -0,0 +1,361 @@
+package telegram
+
+import (
+  "context"
+  "fmt"
+  "runtime/debug"
+  "sync"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/cache"
+)
+
+const maintenanceText = `Бот на техническом обслуживании 🛠
+Пожалуйста, попробуйте позже 🕙`
+
+const panicText = `Что-то пошло не так 😟
+Пожалуйста, попробуйте еще раз`
+
+const rateLimitText = `Слишком много сообщений 🕙
+Пожалуйста, подождите немного`
+
+// rateLimitCapacity максимальное количество чатов, для которых хранится состояние ограничения.
+const rateLimitCapacity = 100_000
+
+// MaintenanceSettings настройки режима технического обслуживания, изменяемые без перезапуска.
+type MaintenanceSettings interface {
+  MaintenanceMode() bool
+}
+
+// NewMaintenanceMiddleware отвечает уведомлением на любые обновления в режиме технического обслуживания.
+func NewMaintenanceMiddleware(settings MaintenanceSettings) telegram.Middleware {
+  return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+    return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+      if !settings.MaintenanceMode() {
+        next(ctx, bot, update)
+        return
+      }
+
+      replyNotice(ctx, bot, update, maintenanceText)
+    }
+  }
+}
+
+// NewRecoveryMiddleware восстанавливает обработку после паники в обработчике и сообщает пользователю об ошибке.
+// Должен быть первым в цепочке, чтобы перехватывать паники остальных middleware.
+func NewRecoveryMiddleware() telegram.Middleware {
+  return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+    return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+      defer func() {
+        r := recover()
+        if r == nil {
+          return
+        }
+        chatId, _ := findChatIdInAnyUpdate(update)
+
+        log.
+          WithField("chat_id", chatId).
+          WithField("update.id", update.ID).
+          Errorf("telegram handler panic: %v\n%s", r, debug.Stack())
+
+        replyNotice(ctx, bot, update, panicText)
+      }()
+
+      next(ctx, bot, update)
+    }
+  }
+}
+
+type TimingConfig struct {
+  // SlowThreshold длительность обработки, начиная с которой обновление логируется как медленное.
+  SlowThreshold time.Duration `validate:"gt=0"`
+}
+
+func (c *TimingConfig) Validate() error {
+  return validator.New().Struct(c)
+}
+
+// NewTimingMiddleware логирует длительность обработки обновления с идентификатором чата и меню сессии.
+func NewTimingMiddleware(config TimingConfig) (telegram.Middleware, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+    return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+      trace := &updateTrace{}
+      startedAt := time.Now()
+
+      next(context.WithValue(ctx, updateTraceContextKey{}, trace), bot, update)
+
+      duration := time.Since(startedAt)
+      chatId, _ := findChatIdInAnyUpdate(update)
+
+      entry := log.
+        WithField("chat_id", chatId).
+        WithField("menu", trace.Menu).
+        WithField("update.id", update.ID).
+        WithField("duration", duration)
+
+      if duration >= config.SlowThreshold {
+        entry.Warn("telegram update handled slowly")
+        return
+      }
+      entry.Debug("telegram update handled")
+    }
+  }, nil
+}
+
+// updateTrace данные обновления, которые становятся известны только в обработчике.
+type updateTrace struct {
+  Menu models.SessionMenu
+}
+
+type updateTraceContextKey struct{}
+
+// traceMenu сохраняет меню сессии для лога длительности обработки.
+func traceMenu(ctx context.Context, menu models.SessionMenu) {
+  if trace, ok := ctx.Value(updateTraceContextKey{}).(*updateTrace); ok {
+    trace.Menu = menu
+  }
+}
+
+// NewChatLockMiddleware выполняет обновления одного чата последовательно.
+// Без него быстрые повторные нажатия обрабатываются параллельно и гоняются за документ сессии.
+func NewChatLockMiddleware() telegram.Middleware {
+  locks := &chatLocks{
+    locks: make(map[int64]*chatLock),
+  }
+
+  return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+    return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+      chatId, ok := findChatIdInAnyUpdate(update)
+      if !ok {
+        next(ctx, bot, update)
+        return
+      }
+
+      unlock, err := locks.lock(ctx, chatId)
+      if err != nil {
+        log.
+          WithField("chat_id", chatId).
+          WithField("update.id", update.ID).
+          Warnf("update skipped: chat lock: %v", err)
+
+        return
+      }
+      defer unlock()
+
+      next(ctx, bot, update)
+    }
+  }
+}
+
+type chatLock struct {
+  ch   chan struct{}
+  refs int
+}
+
+type chatLocks struct {
+  mu    sync.Mutex
+  locks map[int64]*chatLock
+}
+
+func (l *chatLocks) lock(ctx context.Context, chatId int64) (func(), error) {
+  l.mu.Lock()
+
+  current, ok := l.locks[chatId]
+  if !ok {
+    current = &chatLock{ch: make(chan struct{}, 1)}
+    l.locks[chatId] = current
+  }
+  current.refs++
+
+  l.mu.Unlock()
+
+  select {
+  case current.ch <- struct{}{}:
+    return func() {
+      <-current.ch
+      l.release(chatId, current)
+    }, nil
+
+  case <-ctx.Done():
+    l.release(chatId, current)
+    return nil, ctx.Err()
+  }
+}
+
+// release удаляет блокировку чата, когда ее больше никто не ожидает.
+func (l *chatLocks) release(chatId int64, current *chatLock) {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+
+  current.refs--
+  if current.refs == 0 {
+    delete(l.locks, chatId)
+  }
+}
+
+type RateLimitConfig struct {
+  // Interval время восстановления одного обновления в лимите чата.
+  Interval time.Duration `validate:"gt=0"`
+  // Burst количество обновлений, которые чат может отправить подряд.
+  Burst int `validate:"gt=0"`
+}
+
+func (c *RateLimitConfig) Validate() error {
+  return validator.New().Struct(c)
+}
+
+// NewRateLimitMiddleware ограничивает частоту обновлений от одного чата.
+// Об ограничении пользователь уведомляется один раз, пока лимит не восстановится.
+func NewRateLimitMiddleware(ctx context.Context, config RateLimitConfig) (telegram.Middleware, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  // Состояние хранится, пока лимит не восстановится полностью: после этого оно не отличается от нового.
+  refillTime := config.Interval * time.Duration(config.Burst)
+
+  buckets := cache.NewCache[int64, *rateBucket](ctx, cache.Config{
+    TTL:             refillTime,
+    Capacity:        rateLimitCapacity,
+    JanitorInterval: refillTime,
+  })
+
+  return func(next telegram.HandlerFunc) telegram.HandlerFunc {
+    return func(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+      chatId, ok := findChatIdInAnyUpdate(update)
+      if !ok {
+        next(ctx, bot, update)
+        return
+      }
+
+      bucket, _ := buckets.GetOrLoad(ctx, chatId, func(context.Context) (*rateBucket, error) {
+        return &rateBucket{
+          tokens:    float64(config.Burst),
+          updatedAt: time.Now(),
+        }, nil
+      })
+      if bucket == nil {
+        next(ctx, bot, update)
+        return
+      }
+
+      allowed, notify := bucket.take(time.Now(), config)
+
+      buckets.Set(chatId, bucket)
+
+      if allowed {
+        next(ctx, bot, update)
+        return
+      }
+
+      log.
+        WithField("chat_id", chatId).
+        WithField("update.id", update.ID).
+        Warn("update skipped: chat rate limit exceeded")
+
+      if notify {
+        replyNotice(ctx, bot, update, rateLimitText)
+      } else if update.CallbackQuery != nil {
+        answerCallbackQuery(ctx, bot, update.CallbackQuery.ID, "")
+      }
+    }
+  }, nil
+}
+
+type rateBucket struct {
+  mu        sync.Mutex
+  tokens    float64
+  updatedAt time.Time
+  notified  bool
+}
+
+// take расходует обновление из лимита. Возвращает notify, если о превышении лимита еще не уведомляли.
+func (b *rateBucket) take(now time.Time, config RateLimitConfig) (allowed bool, notify bool) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  elapsed := now.Sub(b.updatedAt)
+  b.updatedAt = now
+
+  b.tokens += float64(elapsed) / float64(config.Interval)
+  if burst := float64(config.Burst); b.tokens > burst {
+    b.tokens = burst
+  }
+
+  if b.tokens >= 1 {
+    b.tokens--
+    b.notified = false
+
+    return true, false
+  }
+
+  notify = !b.notified
+  b.notified = true
+
+  return false, notify
+}
+
+// replyNotice отвечает на обновление уведомлением: всплывающим для нажатия inline кнопки, сообщением для остальных.
+func replyNotice(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update, text string) {
+  if update.CallbackQuery != nil {
+    answerCallbackQuery(ctx, bot, update.CallbackQuery.ID, text)
+    return
+  }
+  if update.InlineQuery != nil {
+    answerEmptyInlineQuery(ctx, bot, update.InlineQuery.ID)
+    return
+  }
+
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    return
+  }
+
+  _, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
+    ChatID: chatId,
+    Text:   text,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      Errorf("bot.SendMessage: %v", err)
+  }
+}
+
+// answerEmptyInlineQuery отвечает на inline запрос пустым списком, иначе клиент ждет ответа до таймаута telegram.
+// Без cache_time telegram кэширует ответ на 5 минут, поэтому кэш минимальный.
+func answerEmptyInlineQuery(ctx context.Context, bot *telegram.Bot, queryId string) {
+  _, err := bot.AnswerInlineQuery(ctx, &telegram.AnswerInlineQueryParams{
+    InlineQueryID: queryId,
+    Results:       []tgmodels.InlineQueryResult{},
+    CacheTime:     1,
+    IsPersonal:    true,
+  })
+  if err != nil {
+    log.
+      WithField("inline_query.id", queryId).
+      Errorf("bot.AnswerInlineQuery: %v", err)
+  }
+}
+
+func answerCallbackQuery(ctx context.Context, bot *telegram.Bot, queryId string, text string) {
+  _, err := bot.AnswerCallbackQuery(ctx, &telegram.AnswerCallbackQueryParams{
+    CallbackQueryID: queryId,
+    Text:            text,
+    ShowAlert:       text != "",
+  })
+  if err != nil {
+    log.
+      WithField("callback_query.id", queryId).
+      Errorf("bot.AnswerCallbackQuery: %v", err)
+  }
+}
@@ -0,0 +1,301 @@
+package telegram
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+const (
+  trackingPauseDay  = 24 * time.Hour
+  trackingPauseWeek = 7 * trackingPauseDay
+)
+
+// handleTrackingPauseMenu предлагает срок приостановки отслеживания.
+func (b *Transport) handleTrackingPauseMenu(ctx context.Context, bot *telegram.Bot, chatId int64, url models.ProductURL) {
+  tracking, err := b.findTracking(ctx, chatId, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  if tracking == nil {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   trackingDeletedText,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingPauseMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  // Аренда трекера не должна попасть в сессию.
+  tracking.Lease = nil
+
+  reply := newReplyKeyboard(
+    buttonTrackingPauseDay,
+    buttonTrackingPauseWeek,
+    buttonTrackingPauseDate,
+    buttonTrackingPauseForever,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `На какой срок приостановить отслеживание? ⏸
+
+Пока отслеживание приостановлено, бот не проверяет товар и не присылает уведомления
+После паузы мы пришлем сводку изменений по товару 📦`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingPauseMenu,
+    Tracking: tracking,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingPauseDayMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.pauseTracking(ctx, update, newSnoozePause(time.Now().Add(trackingPauseDay)))
+}
+
+func (b *Transport) handleTrackingPauseWeekMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.pauseTracking(ctx, update, newSnoozePause(time.Now().Add(trackingPauseWeek)))
+}
+
+func (b *Transport) handleTrackingPauseForeverMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  b.pauseTracking(ctx, update, models.TrackingPause{Paused: true})
+}
+
+func (b *Transport) handleTrackingPauseDateMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingPauseDateMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseDateMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: fmt.Sprintf(`Введите дату, до которой приостановить отслеживание 📅
+
+<b>Пример ввода 💬</b>
+%s`, time.Now().Add(trackingPauseWeek).Format(dateLayout)),
+    Reply: newReplyKeyboard(buttonBack),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseDateMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingPauseDateMenu,
+    Tracking: session.Tracking,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseDateMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingInputPauseDateMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingPauseDateMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  // Отслеживание возобновляется в начале указанного дня.
+  until, errMessage := parseFutureDate(update.Message.Text, time.Now())
+
+  if errMessage != "" {
+    err := b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   errMessage,
+      Reply:  newReplyKeyboard(buttonBack),
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingPauseDateMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+    return
+  }
+
+  b.pauseTracking(ctx, update, newSnoozePause(until))
+}
+
+// pauseTracking сохраняет паузу для отслеживания из сессии.
+func (b *Transport) pauseTracking(ctx context.Context, update *tgmodels.Update, pause models.TrackingPause) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  if session.Tracking == nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      WithField("session.tracking", session.Tracking).
+      Warn("message skipped")
+
+    return
+  }
+
+  tracking, err := b.findTracking(ctx, chatId, session.Tracking.URL)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingPauseConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  text := trackingDeletedText
+
+  if tracking != nil {
+    tracking.Pause = pause
+
+    if err = b.updateTrackingPause(ctx, tracking); err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingPauseConfirmMenu).
+        Errorf("b.updateTrackingPause: %v", err)
+
+      return
+    }
+
+    text = pause.String() + `
+Возобновить его можно в списке отслеживаний ▶️`
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply: newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    ),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingPauseConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+  }
+}
+
+// resumeTracking завершает паузу. Трекер при следующем запуске пришлет сводку изменений за время паузы.
+func (b *Transport) resumeTracking(ctx context.Context, tracking *models.Tracking) error {
+  if !tracking.Pause.IsPaused(time.Now()) {
+    return nil
+  }
+
+  tracking.Pause = newSnoozePause(time.Now())
+
+  if err := b.updateTrackingPause(ctx, tracking); err != nil {
+    return fmt.Errorf("b.updateTrackingPause: %w", err)
+  }
+
+  return nil
+}
+
+func (b *Transport) updateTrackingPause(ctx context.Context, tracking *models.Tracking) error {
+  if err := b.deps.Trackings.UpdatePause(ctx, tracking); err != nil {
+    return fmt.Errorf("b.deps.Trackings.UpdatePause: %w", err)
+  }
+
+  return nil
+}
+
+func newSnoozePause(until time.Time) models.TrackingPause {
+  return models.TrackingPause{SnoozedUntil: &until}
+}
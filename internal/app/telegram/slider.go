@@ -0,0 +1,401 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/telegram/assets"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// Данные кнопок слайдера имеют вид slider:<команда>:<идентификатор отслеживания>.
+// Состояние слайдера хранится в базе, поэтому кнопки не зависят от экземпляра бота.
+const sliderPrefix = "slider:"
+
+const (
+  sliderCmdPrev   = "prev"
+  sliderCmdNext   = "next"
+  sliderCmdNop    = "nop"
+  sliderCmdEdit   = "edit"
+  sliderCmdExpiry = "expiry"
+  sliderCmdPause  = "pause"
+  sliderCmdResume = "resume"
+  sliderCmdDelete = "delete"
+  sliderCmdBack   = "back"
+)
+
+const sliderOutdatedText = `Список устарел 🕙
+Откройте его заново`
+
+type sliderSlide struct {
+  Text     string
+  Photo    string
+  IsUpload bool
+}
+
+type showSliderParams struct {
+  ChatId    int64
+  Trackings []*models.Tracking
+}
+
+// showSlider отправляет слайдер отслеживаний и сохраняет его состояние.
+// Возвращает nil, если в списке нет отслеживаний для показа.
+func (b *Transport) showSlider(ctx context.Context, params showSliderParams) (*tgmodels.Message, error) {
+  trackings := make([]*models.Tracking, 0, len(params.Trackings))
+
+  for _, tracking := range params.Trackings {
+    if _, ok := newSliderSlide(params.ChatId, tracking); ok {
+      trackings = append(trackings, tracking)
+    }
+  }
+
+  if len(trackings) == 0 {
+    return nil, nil
+  }
+
+  now := time.Now()
+
+  slider := models.Slider{
+    ChatId:    params.ChatId,
+    Items:     models.NewSliderItems(trackings),
+    CreatedAt: now,
+    ExpiresAt: now.Add(b.config.SliderTTL),
+  }
+
+  slide, _ := newSliderSlide(params.ChatId, trackings[0])
+
+  sendParams := &telegram.SendPhotoParams{
+    ChatID:      params.ChatId,
+    Photo:       &tgmodels.InputFileString{Data: slide.Photo},
+    Caption:     slide.Text,
+    ParseMode:   tgmodels.ParseModeMarkdown,
+    ReplyMarkup: newSliderKeyboard(&slider, 0, trackings[0]),
+  }
+
+  if slide.IsUpload {
+    sendParams.Photo = &tgmodels.InputFileUpload{
+      Filename: "image.png",
+      Data:     strings.NewReader(slide.Photo),
+    }
+  }
+
+  message, err := b.deps.Telegram.SendPhoto(ctx, sendParams)
+  if err != nil {
+    return nil, fmt.Errorf("b.deps.Telegram.SendPhoto: %w", err)
+  }
+
+  slider.MessageId = message.ID
+
+  if err = b.deps.Sliders.Insert(ctx, slider); err != nil {
+    return nil, fmt.Errorf("b.deps.Sliders.Insert: %w", err)
+  }
+
+  return message, nil
+}
+
+func (b *Transport) handleSliderCallback(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  query := update.CallbackQuery
+
+  chatId, ok := findChatIdInMaybeInaccessible(query.Message)
+  if !ok {
+    log.
+      WithField("callback_query.data", query.Data).
+      Warn("chat_id not found")
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  messageId := findMessageIdInMaybeInaccessible(query.Message)
+
+  cmd, id, ok := parseSliderCallbackData(query.Data)
+  if !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("callback_query.data", query.Data).
+      Warn("invalid slider callback data")
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  slider, err := b.deps.Sliders.Get(ctx, chatId, messageId)
+  if err != nil {
+    if !errors.Is(err, models.ErrNotFound) {
+      log.
+        WithField("chat_id", chatId).
+        WithField("message_id", messageId).
+        Errorf("b.deps.Sliders.Get: %v", err)
+    }
+
+    b.answerCallback(ctx, query.ID, sliderOutdatedText)
+    return
+  }
+
+  index, ok := slider.Find(id)
+  if !ok {
+    b.answerCallback(ctx, query.ID, sliderOutdatedText)
+    return
+  }
+
+  switch cmd {
+  case sliderCmdPrev, sliderCmdNext:
+    b.moveSlider(ctx, slider, index, cmd)
+    b.answerCallback(ctx, query.ID, "")
+
+  case sliderCmdEdit:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingEditMenu(ctx, bot, chatId, slider.Items[index].URL)
+
+  case sliderCmdExpiry:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingExpiryMenu(ctx, bot, chatId, slider.Items[index].URL)
+
+  case sliderCmdPause:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingPauseMenu(ctx, bot, chatId, slider.Items[index].URL)
+
+  case sliderCmdResume:
+    b.answerCallback(ctx, query.ID, b.resumeSlide(ctx, slider, index))
+
+  case sliderCmdDelete:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingDeleteMenu(ctx, bot, chatId, slider.Items[index].URL)
+
+  case sliderCmdBack:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingSilentMenu(ctx, bot, query.Message)
+
+  default:
+    b.answerCallback(ctx, query.ID, "")
+  }
+}
+
+// moveSlider показывает соседнее отслеживание. Удаленные с момента отправки слайдера отслеживания пропускаются.
+func (b *Transport) moveSlider(ctx context.Context, slider *models.Slider, index int, cmd string) {
+  step := 1
+  if cmd == sliderCmdPrev {
+    step = -1
+  }
+
+  count := len(slider.Items)
+
+  for range slider.Items {
+    index = ((index+step)%count + count) % count
+
+    tracking, err := b.findTracking(ctx, slider.ChatId, slider.Items[index].URL)
+    if err != nil {
+      log.
+        WithField("chat_id", slider.ChatId).
+        WithField("tracking.url", slider.Items[index].URL).
+        Errorf("b.findTracking: %v", err)
+
+      return
+    }
+
+    slide, ok := newSliderSlide(slider.ChatId, tracking)
+    if !ok {
+      continue
+    }
+
+    media := &tgmodels.InputMediaPhoto{
+      Media:     slide.Photo,
+      Caption:   slide.Text,
+      ParseMode: tgmodels.ParseModeMarkdown,
+    }
+
+    if slide.IsUpload {
+      media = &tgmodels.InputMediaPhoto{
+        Media:           "attach://image.png",
+        Caption:         slide.Text,
+        ParseMode:       tgmodels.ParseModeMarkdown,
+        MediaAttachment: strings.NewReader(slide.Photo),
+      }
+    }
+
+    _, err = b.deps.Telegram.EditMessageMedia(ctx, &telegram.EditMessageMediaParams{
+      ChatID:      slider.ChatId,
+      MessageID:   slider.MessageId,
+      Media:       media,
+      ReplyMarkup: newSliderKeyboard(slider, index, tracking),
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", slider.ChatId).
+        WithField("message_id", slider.MessageId).
+        Errorf("b.deps.Telegram.EditMessageMedia: %v", err)
+    }
+
+    return
+  }
+}
+
+// resumeSlide возобновляет приостановленное или архивное отслеживание на слайде и обновляет слайд. Возвращает текст ответа на нажатие.
+func (b *Transport) resumeSlide(ctx context.Context, slider *models.Slider, index int) string {
+  tracking, err := b.findTracking(ctx, slider.ChatId, slider.Items[index].URL)
+  if err != nil {
+    log.
+      WithField("chat_id", slider.ChatId).
+      WithField("tracking.url", slider.Items[index].URL).
+      Errorf("b.findTracking: %v", err)
+
+    return ""
+  }
+
+  if tracking == nil {
+    return sliderOutdatedText
+  }
+
+  text := `Отслеживание возобновлено ▶️
+После следующей проверки мы пришлем сводку изменений за время паузы`
+
+  if tracking.Archive != nil {
+    text = trackingReactivatedText
+    err = b.reactivateTracking(ctx, tracking)
+  } else {
+    err = b.resumeTracking(ctx, tracking)
+  }
+  if err != nil {
+    log.
+      WithField("chat_id", slider.ChatId).
+      WithField("tracking.url", tracking.URL).
+      Errorf("b.resumeTracking: %v", err)
+
+    return ""
+  }
+
+  slide, ok := newSliderSlide(slider.ChatId, tracking)
+  if !ok {
+    return ""
+  }
+
+  _, err = b.deps.Telegram.EditMessageCaption(ctx, &telegram.EditMessageCaptionParams{
+    ChatID:      slider.ChatId,
+    MessageID:   slider.MessageId,
+    Caption:     slide.Text,
+    ParseMode:   tgmodels.ParseModeMarkdown,
+    ReplyMarkup: newSliderKeyboard(slider, index, tracking),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", slider.ChatId).
+      WithField("message_id", slider.MessageId).
+      Errorf("b.deps.Telegram.EditMessageCaption: %v", err)
+  }
+
+  return text
+}
+
+func newSliderSlide(chatId int64, tracking *models.Tracking) (sliderSlide, bool) {
+  if tracking == nil {
+    return sliderSlide{}, false
+  }
+
+  res := models.Sendable(chatId).
+    SetTrackingPtr(tracking).
+    BuildTrackingMessage()
+
+  if !res.IsValid {
+    return sliderSlide{}, false
+  }
+
+  slide := sliderSlide{
+    Text:  telegram.EscapeMarkdown(res.Message.Text.Value),
+    Photo: tracking.ParsedProduct.ImageURL,
+  }
+
+  if slide.Photo == "" {
+    slide.Photo = string(assets.NoPhoto)
+    slide.IsUpload = true
+  }
+
+  return slide, true
+}
+
+func newSliderKeyboard(slider *models.Slider, index int, tracking *models.Tracking) tgmodels.InlineKeyboardMarkup {
+  id := slider.Items[index].TrackingId
+
+  pause := tgmodels.InlineKeyboardButton{Text: "Пауза", CallbackData: newSliderCallbackData(sliderCmdPause, id)}
+  if tracking.Archive != nil || tracking.Pause.IsPaused(time.Now()) {
+    pause = tgmodels.InlineKeyboardButton{Text: "Возобновить", CallbackData: newSliderCallbackData(sliderCmdResume, id)}
+  }
+
+  return tgmodels.InlineKeyboardMarkup{
+    InlineKeyboard: [][]tgmodels.InlineKeyboardButton{
+      {
+        {Text: "«", CallbackData: newSliderCallbackData(sliderCmdPrev, id)},
+        {Text: strconv.Itoa(index+1) + "/" + strconv.Itoa(len(slider.Items)), CallbackData: newSliderCallbackData(sliderCmdNop, id)},
+        {Text: "»", CallbackData: newSliderCallbackData(sliderCmdNext, id)},
+      },
+      {
+        {Text: "Изменить", CallbackData: newSliderCallbackData(sliderCmdEdit, id)},
+        {Text: "Срок", CallbackData: newSliderCallbackData(sliderCmdExpiry, id)},
+        {Text: "Удалить", CallbackData: newSliderCallbackData(sliderCmdDelete, id)},
+      },
+      {
+        pause,
+        {Text: "Назад", CallbackData: newSliderCallbackData(sliderCmdBack, id)},
+      },
+    },
+  }
+}
+
+func newSliderCallbackData(cmd string, id models.TrackingId) string {
+  return sliderPrefix + cmd + ":" + id
+}
+
+func parseSliderCallbackData(data string) (cmd string, id models.TrackingId, ok bool) {
+  cmd, id, ok = strings.Cut(strings.TrimPrefix(data, sliderPrefix), ":")
+  if !ok || cmd == "" || id == "" {
+    return "", "", false
+  }
+  return cmd, id, true
+}
+
+func (b *Transport) answerCallback(ctx context.Context, queryId string, text string) {
+  _, err := b.deps.Telegram.AnswerCallbackQuery(ctx, &telegram.AnswerCallbackQueryParams{
+    CallbackQueryID: queryId,
+    Text:            text,
+    ShowAlert:       text != "",
+  })
+  if err != nil {
+    log.
+      WithField("callback_query.id", queryId).
+      Errorf("b.deps.Telegram.AnswerCallbackQuery: %v", err)
+  }
+}
+
+func (b *Transport) deleteMessage(ctx context.Context, chatId int64, messageId int) {
+  _, err := b.deps.Telegram.DeleteMessage(ctx, &telegram.DeleteMessageParams{
+    ChatID:    chatId,
+    MessageID: messageId,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("message_id", messageId).
+      Errorf("b.deps.Telegram.DeleteMessage: %v", err)
+  }
+}
+
+func findMessageIdInMaybeInaccessible(msg tgmodels.MaybeInaccessibleMessage) int {
+  if msg.Message != nil {
+    return msg.Message.ID
+  }
+  if msg.InaccessibleMessage != nil {
+    return msg.InaccessibleMessage.MessageID
+  }
+  return 0
+}
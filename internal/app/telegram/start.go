@@ -0,0 +1,228 @@
+package telegram
+
+import (
+  "context"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func (b *Transport) handleStartMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.StartMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingMy,
+    buttonTrackingInsert,
+    buttonShopList,
+    buttonIssueInsert,
+  )
+
+  text := `<b>Бот создан для отслеживания товаров 💬</b>
+
+<b>Он отсылает уведомления, когда:</b>
+1. Цена на товар была снижена или появилась скидка на товар 📉
+2. Распроданный товар снова появился в наличии 📦
+
+<b>Опционально, бот может отсылать уведомления, когда</b>:
+1. Цена на товар возросла 📈
+2. Количество товара сократилось 📦
+
+<b>Управление ботом происходит с помощью виртуальной клавиатуры 💡</b>`
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.StartMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleStartSilentMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.StartSilentMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  err = b.deleteSessionMessage(ctx, session)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.deleteSessionMessage: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+    buttonShopList,
+    buttonIssueInsert,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Вы вернулись в главное меню бота 💬`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.StartSilentMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingSilentMenu(ctx context.Context, bot *telegram.Bot, message tgmodels.MaybeInaccessibleMessage) {
+  chatId, ok := findChatIdInMaybeInaccessible(message)
+  if !ok {
+    log.
+      WithField("inaccessible_message", message).
+      WithField("menu", models.StartSilentMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+  )
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Вы вернулись в главное меню бота 💬`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.StartSilentMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.StartSilentMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleShopList(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Магазины, с которыми работает бот:
+1. Lamoda
+2. Lime
+3. Kixbox
+4. Ridestep
+5. Траектория
+6. Октябрь Скейтшоп
+Список постепенно будет пополняться 🤓`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.ShopListMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.ShopListMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
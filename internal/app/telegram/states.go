@@ -0,0 +1,436 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// buttonId идентификатор кнопки виртуальной клавиатуры.
+// Маршрутизация выполняется по точному совпадению текста кнопки, а не по вхождению подстроки.
+type buttonId string
+
+const (
+  buttonBack       buttonId = "back"
+  buttonBackToMenu buttonId = "back_to_menu"
+  buttonNext       buttonId = "next"
+
+  buttonHelp           buttonId = "help"
+  buttonTrackingMy     buttonId = "tracking_my"
+  buttonTrackingInsert buttonId = "tracking_insert"
+  buttonShopList       buttonId = "shop_list"
+  buttonIssueInsert    buttonId = "issue_insert"
+
+  buttonTrackingList          buttonId = "tracking_list"
+  buttonTrackingSearch        buttonId = "tracking_search"
+  buttonTrackingSearchAgain   buttonId = "tracking_search_again"
+  buttonTrackingTrash         buttonId = "tracking_trash"
+  buttonTrackingFlagOn        buttonId = "tracking_flag_on"
+  buttonTrackingFlagOff       buttonId = "tracking_flag_off"
+  buttonTrackingComment       buttonId = "tracking_comment"
+  buttonTrackingInsertConfirm buttonId = "tracking_insert_confirm"
+  buttonTrackingDeleteConfirm buttonId = "tracking_delete_confirm"
+  buttonTrackingPauseDay      buttonId = "tracking_pause_day"
+  buttonTrackingPauseWeek     buttonId = "tracking_pause_week"
+  buttonTrackingPauseDate     buttonId = "tracking_pause_date"
+  buttonTrackingPauseForever  buttonId = "tracking_pause_forever"
+  buttonTrackingExpiryReset   buttonId = "tracking_expiry_reset"
+  buttonTrackingBulk          buttonId = "tracking_bulk"
+
+  buttonIssueStory         buttonId = "issue_story"
+  buttonIssueBug           buttonId = "issue_bug"
+  buttonIssueInsertConfirm buttonId = "issue_insert_confirm"
+)
+
+var buttonTexts = map[buttonId]string{
+  buttonBack:       "Назад",
+  buttonBackToMenu: "Назад в меню",
+  buttonNext:       "Далее",
+
+  buttonHelp:           "Помощь 💡",
+  buttonTrackingMy:     "Мои отслеживания ✉️",
+  buttonTrackingInsert: "Добавить отслеживание 📨",
+  buttonShopList:       "Поддерживаемые магазины 👜",
+  buttonIssueInsert:    "Обратная связь 📧",
+
+  buttonTrackingList:          "Список 📋",
+  buttonTrackingSearch:        "Поиск 🔎",
+  buttonTrackingSearchAgain:   "К поиску",
+  buttonTrackingTrash:         "Корзина 🗑️",
+  buttonTrackingFlagOn:        "Включить️",
+  buttonTrackingFlagOff:       "Пропустить️",
+  buttonTrackingComment:       "Комментарий 💬",
+  buttonTrackingInsertConfirm: "Подтвердить 📨",
+  buttonTrackingDeleteConfirm: "Подтвердить",
+  buttonTrackingPauseDay:      "На 1 день ⏸",
+  buttonTrackingPauseWeek:     "На 1 неделю ⏸",
+  buttonTrackingPauseDate:     "До даты 📅",
+  buttonTrackingPauseForever:  "До возобновления ⏸",
+  buttonTrackingExpiryReset:   "Без срока ♾",
+  buttonTrackingBulk:          "Добавить все 📋",
+
+  buttonIssueStory:         "Улучшение 👨‍🔧",
+  buttonIssueBug:           "Баг 😟",
+  buttonIssueInsertConfirm: "Подтвердить 📧",
+}
+
+var buttonIds = lo.Invert(buttonTexts)
+
+const unknownInputText = `Не удалось распознать команду 👀
+Воспользуйтесь кнопками меню 💡`
+
+// state состояние диалога с пользователем.
+type state struct {
+  // Enter показывает меню состояния. Требуется для состояний, в которые ведет возврат назад.
+  Enter telegram.HandlerFunc
+  // Buttons переходы по кнопкам, допустимым в состоянии.
+  Buttons map[buttonId]telegram.HandlerFunc
+  // Input обработчик произвольного текста. Если не задан, текст в состоянии не принимается.
+  Input telegram.HandlerFunc
+  // Document обработчик файла. Если не задан, файлы в состоянии не принимаются.
+  Document telegram.HandlerFunc
+  // Back состояние, в которое ведет кнопка «Назад».
+  Back models.SessionMenu
+  // Busy обработчик любых сообщений, пока в состоянии выполняется фоновая операция.
+  // Если задан, кнопки, главное меню и ввод в состоянии не принимаются.
+  Busy telegram.HandlerFunc
+}
+
+type stateMachine struct {
+  // Common переходы главного меню, доступные в любом состоянии.
+  Common map[buttonId]telegram.HandlerFunc
+  States map[models.SessionMenu]state
+}
+
+func (b *Transport) newStateMachine() stateMachine {
+  return stateMachine{
+    Common: map[buttonId]telegram.HandlerFunc{
+      buttonHelp:           b.handleStartMenu,
+      buttonTrackingMy:     b.handleTrackingMyMenu,
+      buttonTrackingInsert: b.handleTrackingInsertMenu,
+      buttonShopList:       b.handleShopList,
+      buttonIssueInsert:    b.handleInsertIssueMenu,
+    },
+    States: map[models.SessionMenu]state{
+      models.StartMenu: {
+        Enter: b.handleStartMenu,
+      },
+      models.StartSilentMenu: {
+        Enter: b.handleStartSilentMenu,
+      },
+      models.ShopListMenu: {
+        Back: models.StartSilentMenu,
+      },
+
+      models.TrackingInsertMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingBulk: b.handleTrackingBulkMenu,
+        },
+        Input:    b.handleTrackingInputUrlMenu,
+        Document: b.handleTrackingInputBulkFileMenu,
+        Back:     models.StartSilentMenu,
+      },
+      models.TrackingInputUrlMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonNext: b.handleTrackingInputFlagMenu,
+        },
+        Input: b.handleTrackingInputSizesMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingInputSizesMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonNext: b.handleTrackingInputFlagMenu,
+        },
+        Input: b.handleTrackingInputSizesMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingInputFlagMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingFlagOn:  b.handleTrackingFlagOnMenu,
+          buttonTrackingFlagOff: b.handleTrackingFlagOffMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingFlagConfirmMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingInsertConfirm: b.handleTrackingInsertConfirmMenu,
+          buttonTrackingComment:       b.handleTrackingCommentMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingCommentMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingInsertConfirm: b.handleTrackingInsertConfirmMenu,
+        },
+        Input: b.handleTrackingInputCommentMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingInputCommentMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingInsertConfirm: b.handleTrackingInsertConfirmMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingInsertConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingBulkMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonNext: b.handleTrackingBulkFlagMenu,
+        },
+        Input: b.handleTrackingInputBulkSizesMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingBulkLoadingMenu: {
+        Busy: b.handleTrackingBulkLoadingMenu,
+      },
+      models.TrackingBulkFlagMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingFlagOn:  b.handleTrackingBulkFlagOnMenu,
+          buttonTrackingFlagOff: b.handleTrackingBulkFlagOffMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingBulkConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+
+      models.TrackingMyMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingList:   b.handleTrackingListMenu,
+          buttonTrackingSearch: b.handleTrackingSearchInputMenu,
+          buttonTrackingTrash:  b.handleTrackingTrashMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingListMenu: {
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingSearchInputMenu: {
+        Input: b.handleTrackingSearchShowMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingSearchSilentInputMenu: {
+        Input: b.handleTrackingSearchShowMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingSearchShowMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingSearchAgain: b.handleTrackingSearchSilentInputMenu,
+          buttonBackToMenu:          b.handleStartSilentMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingEditMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonNext: b.handleTrackingInputFlagMenu,
+        },
+        Input: b.handleTrackingInputSizesMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingPauseMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingPauseDay:     b.handleTrackingPauseDayMenu,
+          buttonTrackingPauseWeek:    b.handleTrackingPauseWeekMenu,
+          buttonTrackingPauseDate:    b.handleTrackingPauseDateMenu,
+          buttonTrackingPauseForever: b.handleTrackingPauseForeverMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingPauseDateMenu: {
+        Input: b.handleTrackingInputPauseDateMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingPauseConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingExpiryMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingExpiryReset: b.handleTrackingExpiryResetMenu,
+        },
+        Input: b.handleTrackingInputExpiryMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.TrackingExpiryConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingDeleteMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonTrackingDeleteConfirm: b.handleTrackingDeleteConfirmMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingDeleteConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+      models.TrackingTrashMenu: {
+        Back: models.StartSilentMenu,
+      },
+
+      models.IssueInsertMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonIssueStory: b.handleIssueInputStoryMenu,
+          buttonIssueBug:   b.handleIssueInputBugMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.IssueInputTypeMenu: {
+        Input: b.handleIssueInputTextMenu,
+        Back:  models.StartSilentMenu,
+      },
+      models.IssueInputTextMenu: {
+        Buttons: map[buttonId]telegram.HandlerFunc{
+          buttonIssueInsertConfirm: b.handleIssueInsertConfirmMenu,
+        },
+        Back: models.StartSilentMenu,
+      },
+      models.IssueInsertConfirmMenu: {
+        Back: models.StartSilentMenu,
+      },
+    },
+  }
+}
+
+// route выбирает обработчик текста в состоянии menu.
+// Кнопки состояния и возврат назад имеют приоритет над главным меню, главное меню над произвольным вводом.
+func (m *stateMachine) route(menu models.SessionMenu, text string) (telegram.HandlerFunc, bool) {
+  current, ok := m.States[menu]
+  if !ok {
+    current = m.States[models.StartMenu]
+  }
+
+  if id, ok := buttonIds[text]; ok {
+    if handler, ok := current.Buttons[id]; ok {
+      return handler, true
+    }
+    if id == buttonBack && current.Back != "" {
+      if back := m.States[current.Back]; back.Enter != nil {
+        return back.Enter, true
+      }
+    }
+    if handler, ok := m.Common[id]; ok {
+      return handler, true
+    }
+  }
+
+  if current.Input != nil {
+    return current.Input, true
+  }
+
+  return nil, false
+}
+
+// routeMessage выбирает обработчик сообщения в состоянии menu.
+// Файл передается обработчику файлов, если состояние их принимает, иначе сообщение маршрутизируется по тексту.
+func (m *stateMachine) routeMessage(menu models.SessionMenu, message *tgmodels.Message) (telegram.HandlerFunc, bool) {
+  current, ok := m.States[menu]
+
+  if ok && current.Busy != nil {
+    return current.Busy, true
+  }
+  if ok && current.Document != nil && message.Document != nil {
+    return current.Document, true
+  }
+  if message.Text == "" {
+    return nil, false
+  }
+  return m.route(menu, message.Text)
+}
+
+// handleStateUpdate загружает сессию один раз на обновление и передает текст обработчику текущего состояния.
+func (b *Transport) handleStateUpdate(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  update = withCaptionAsText(update)
+
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.findSession(ctx, chatId)
+  if err != nil {
+    if !errors.Is(err, models.ErrNotFound) {
+      log.
+        WithField("chat_id", chatId).
+        Errorf("b.findSession: %v", err)
+
+      return
+    }
+
+    session = &models.Session{
+      ChatId:  chatId,
+      Message: models.SessionMessage{Menu: models.StartMenu},
+    }
+  }
+
+  traceMenu(ctx, session.Message.Menu)
+
+  handler, ok := b.states.routeMessage(session.Message.Menu, update.Message)
+  if !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", session.Message.Menu).
+      Debug("unexpected input skipped")
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   unknownInputText,
+      Reply: newReplyKeyboard(
+        buttonHelp,
+        buttonTrackingMy,
+        buttonTrackingInsert,
+      ),
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", session.Message.Menu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  handler(withSession(ctx, session), bot, update)
+}
+
+type sessionContextKey struct{}
+
+func withSession(ctx context.Context, session *models.Session) context.Context {
+  return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// currentSession возвращает сессию, загруженную для обновления, или загружает ее для вызовов вне маршрутизации.
+func (b *Transport) currentSession(ctx context.Context, chatId int64) (*models.Session, error) {
+  if session, ok := ctx.Value(sessionContextKey{}).(*models.Session); ok && session.ChatId == chatId {
+    return session, nil
+  }
+  return b.findSession(ctx, chatId)
+}
+
+func isStateUpdate(update *tgmodels.Update) bool {
+  return update.Message != nil && (update.Message.Text != "" || update.Message.Caption != "" || update.Message.Document != nil)
+}
+
+// newReplyKeyboard создает одноразовую клавиатуру с кнопкой в каждой строке.
+func newReplyKeyboard(ids ...buttonId) *tgmodels.ReplyKeyboardMarkup {
+  rows := make([][]tgmodels.KeyboardButton, 0, len(ids))
+
+  for _, id := range ids {
+    rows = append(rows, []tgmodels.KeyboardButton{{Text: buttonTexts[id]}})
+  }
+
+  return &tgmodels.ReplyKeyboardMarkup{
+    Keyboard:        rows,
+    ResizeKeyboard:  true,
+    OneTimeKeyboard: true,
+  }
+}
@@ -3,54 +3,108 @@ package telegram
 import (
   "context"
   "fmt"
+  "sync"
+  "time"
 
+  "github.com/go-playground/validator/v10"
   telegram "github.com/go-telegram/bot"
   "github.com/ushakovn/outfit/internal/app/tracker"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/deps/resolver"
   "github.com/ushakovn/outfit/internal/models"
   "github.com/ushakovn/outfit/pkg/cache"
 )
 
 type Transport struct {
-  deps Dependencies
+  config Config
+  deps   Dependencies
+  states stateMachine
+  // username имя бота для ссылок на него. Заполняется при запуске.
+  username string
+  // deepLinks выданные токены ссылок, чтобы не сохранять ссылку при каждом показе.
+  deepLinks *cache.Cache[models.DeepLinkId, string]
+  // lifetime контекст работы транспорта. Заполняется при запуске, фоновые операции прерываются при его отмене.
+  lifetime context.Context
+  // background фоновые операции обработчиков, которые дожидается Stop.
+  background   sync.WaitGroup
+  backgroundMu sync.Mutex
+  stopped      bool
 }
 
-type Dependencies struct {
-  Tracker  *tracker.Tracker
-  Telegram *telegram.Bot
-  Mongodb  *mongodb.Client
-
-  cache dependenciesCache
+type Config struct {
+  // ListLimit максимальное количество отслеживаний в списке и результатах поиска.
+  ListLimit int64 `validate:"gt=0"`
+  // SliderTTL время, в течение которого работают кнопки отправленного слайдера.
+  SliderTTL time.Duration `validate:"gt=0"`
+  DeepLink  DeepLinkConfig
+  Trash     TrashConfig
+  Bulk      BulkConfig
 }
 
-type dependenciesCache struct {
-  trackings *cache.Cache[models.ChatId, trackingIndex, models.ProductURL]
+func (c *Config) Validate() error {
+  return validator.New().Struct(c)
 }
 
-type trackingIndex = int
-
-func NewTransport(deps Dependencies) *Transport {
-  deps.cache = makeDependenciesCache()
+type Dependencies struct {
+  Tracker   *tracker.Tracker
+  Telegram  *telegram.Bot
+  Trackings models.TrackingsRepository
+  Sessions  models.SessionsRepository
+  Issues    models.IssuesRepository
+  Sliders   models.SlidersRepository
+  DeepLinks models.DeepLinksRepository
+  Resolver  *resolver.Resolver
+  // Webhook необязательный источник обновлений. Если не задан, используется long polling.
+  Webhook Webhook
+}
 
-  return &Transport{deps: deps}
+type Webhook interface {
+  Start(ctx context.Context) error
+  Stop() error
 }
 
-func makeDependenciesCache() dependenciesCache {
-  return dependenciesCache{
-    trackings: cache.NewCache[
-      models.ChatId,
-      trackingIndex,
-      models.ProductURL,
-    ](),
+func NewTransport(config Config, deps Dependencies) (*Transport, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  b := &Transport{
+    config: config,
+    deps:   deps,
+    // Токен выдается из кэша не дольше половины времени действия ссылки, поэтому выданная ссылка не истекает раньше срока.
+    deepLinks: cache.NewCache[models.DeepLinkId, string](context.Background(), cache.Config{
+      TTL:      config.DeepLink.TTL / 2,
+      Capacity: deepLinkCacheCapacity,
+    }),
   }
+  b.states = b.newStateMachine()
+
+  return b, nil
 }
 
 func (b *Transport) Start(ctx context.Context) error {
+  me, err := b.deps.Telegram.GetMe(ctx)
+  if err != nil {
+    return fmt.Errorf("b.deps.Telegram.GetMe: %w", err)
+  }
+  b.username = me.Username
+  b.lifetime = ctx
+
   b.registerHandlers(ctx)
 
-  err := b.beforeStartChecks(ctx)
-  if err != nil {
-    return fmt.Errorf("b.beforeStartChecks: %w", err)
+  if err := b.setMyCommands(ctx); err != nil {
+    return fmt.Errorf("b.setMyCommands: %w", err)
+  }
+
+  if b.deps.Webhook != nil {
+    if err := b.deps.Webhook.Start(ctx); err != nil {
+      return fmt.Errorf("b.deps.Webhook.Start: %w", err)
+    }
+    return nil
+  }
+
+  // Long polling не работает, пока зарегистрирован webhook.
+  if _, err := b.deps.Telegram.DeleteWebhook(ctx, &telegram.DeleteWebhookParams{}); err != nil {
+    return fmt.Errorf("b.deps.Telegram.DeleteWebhook: %w", err)
   }
 
   go b.deps.Telegram.Start(ctx)
@@ -58,10 +112,40 @@ func (b *Transport) Start(ctx context.Context) error {
   return nil
 }
 
-func (b *Transport) beforeStartChecks(ctx context.Context) error {
-  err := b.checkTrackingIndex(ctx)
-  if err != nil {
-    return fmt.Errorf("b.checkTrackingIndex: %w", err)
+// Stop дожидается выполняющихся обработчиков в режиме webhook и фоновых операций.
+func (b *Transport) Stop() error {
+  b.backgroundMu.Lock()
+  b.stopped = true
+  b.backgroundMu.Unlock()
+
+  defer b.background.Wait()
+
+  if b.deps.Webhook == nil {
+    return nil
+  }
+
+  if err := b.deps.Webhook.Stop(); err != nil {
+    return fmt.Errorf("b.deps.Webhook.Stop: %w", err)
   }
+
   return nil
 }
+
+// goBackground запускает операцию, которая продолжается после ответа на обновление, на контексте работы транспорта.
+// Возвращает false, если транспорт остановлен.
+func (b *Transport) goBackground(fn func(ctx context.Context)) bool {
+  b.backgroundMu.Lock()
+  defer b.backgroundMu.Unlock()
+
+  if b.stopped {
+    return false
+  }
+  b.background.Add(1)
+
+  go func() {
+    defer b.background.Done()
+    fn(b.lifetime)
+  }()
+
+  return true
+}
@@ -0,0 +1,465 @@
+package telegram
+
+import (
+  "context"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func (b *Transport) handleTrackingMyMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingMyMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingList,
+    buttonTrackingSearch,
+    buttonTrackingTrash,
+    buttonBack,
+  )
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Выберите вариант просмотра:
+Список 📋 или Поиск 🔎
+
+Удаленные отслеживания можно восстановить из корзины 🗑️`,
+    Reply: reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingMyMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingMyMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingMyMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingListMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingListMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  list, err := b.listTrackings(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingListMenu).
+      Errorf("b.listTrackings: %v", err)
+
+    return
+  }
+
+  if len(list) > 0 {
+    _, err = b.showSlider(ctx, showSliderParams{
+      ChatId:    chatId,
+      Trackings: list,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingListMenu).
+        Errorf("b.showSlider: %v", err)
+
+      return
+    }
+  } else {
+    reply := newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    )
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   `У вас пока нет отслеживаний 👀`,
+      Reply:  reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingListMenu).
+        Errorf("b.sendMessage: %v", err)
+
+      return
+    }
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingListMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingListMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingSearchInputMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingSearchInputMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text: `Введите ключевые слова для поиска 💬
+Они могут содержаться в названии или описании товара, ссылке или комментарии к отслеживанию 💡`,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchInputMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingSearchInputMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchInputMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingSearchSilentInputMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingSearchSilentInputMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  err = b.deleteSessionMessage(ctx, session)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchSilentInputMenu).
+      Errorf("b.deleteSessionMessage: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Введите ключевые слова для поиска 💬`,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchSilentInputMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingSearchSilentInputMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchSilentInputMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingSearchShowMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingSearchShowMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  reply := newReplyKeyboard(buttonBack)
+
+  query := parseSearchQuery(update.Message.Text)
+
+  list, err := b.searchTracking(ctx, chatId, query)
+
+  if err != nil || len(list) == 0 {
+
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text: `Похожих отслеживаний не найдено 👀
+Попробуйте поискать еще раз 😉`,
+      Reply: reply,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingSearchShowMenu).
+        Errorf("b.sendMessage: %v", err)
+
+      return
+    }
+
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingSearchShowMenu).
+        Errorf("b.searchTracking: %v", err)
+    }
+
+    return
+  }
+
+  message, err := b.showSlider(ctx, showSliderParams{
+    ChatId:    chatId,
+    Trackings: list,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchShowMenu).
+      Errorf("b.showSlider: %v", err)
+
+    return
+  }
+
+  var messageId *int
+  if message != nil {
+    messageId = &message.ID
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:    chatId,
+    Menu:      models.TrackingSearchShowMenu,
+    MessageID: messageId,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchShowMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+
+  const messageDelay = time.Second
+  time.Sleep(messageDelay)
+
+  reply = newReplyKeyboard(
+    buttonTrackingSearchAgain,
+    buttonBackToMenu,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Поискать другие отслеживания?`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingSearchShowMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingDeleteMenu(ctx context.Context, bot *telegram.Bot, chatId int64, url models.ProductURL) {
+  tracking, err := b.findTracking(ctx, chatId, url)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteMenu).
+      Errorf("b.findTracking: %v", err)
+
+    return
+  }
+
+  if tracking == nil {
+    err = b.sendMessage(ctx, sendMessageParams{
+      ChatId: chatId,
+      Text:   trackingDeletedText,
+    })
+    if err != nil {
+      log.
+        WithField("chat_id", chatId).
+        WithField("menu", models.TrackingDeleteMenu).
+        Errorf("b.sendMessage: %v", err)
+    }
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonTrackingDeleteConfirm,
+    buttonBack,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Вы уверены, что хотите удалить отслеживание? 🗑️`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingDeleteMenu,
+    Tracking: tracking,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrackingDeleteConfirmMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  session, err := b.currentSession(ctx, chatId)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.currentSession: %v", err)
+
+    return
+  }
+
+  err = b.deleteTracking(ctx, session)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.deleteTracking: %v", err)
+
+    return
+  }
+
+  reply := newReplyKeyboard(
+    buttonHelp,
+    buttonTrackingMy,
+    buttonTrackingInsert,
+  )
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   `Отслеживание успешно удалено 😉`,
+    Reply:  reply,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   trashUndoText,
+    Reply:  newTrashUndoKeyboard(session.Tracking.Id()),
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingDeleteConfirmMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingDeleteConfirmMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
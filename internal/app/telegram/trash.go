@@ -0,0 +1,319 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "html"
+  "strconv"
+  "strings"
+  "time"
+
+  telegram "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// Данные кнопок корзины имеют вид trash:<команда>:<идентификатор отслеживания>.
+const trashPrefix = "trash:"
+
+const (
+  trashCmdUndo    = "undo"
+  trashCmdRestore = "restore"
+  trashCmdBack    = "back"
+)
+
+// trashLimit максимальное количество отслеживаний, показываемых в корзине.
+const trashLimit = 10
+
+const trashEmptyText = `Корзина пуста 🗑️`
+
+const trashOutdatedText = `Отслеживание уже восстановлено или удалено из корзины 🗑️`
+
+const trashUndoText = `Удалили по ошибке? Удаление можно отменить кнопкой ниже ↩️
+Позже отслеживание можно восстановить из корзины в меню «Мои отслеживания» 🗑️`
+
+const trashUndoExpiredText = `Время отмены истекло 🕙
+Отслеживание можно восстановить из корзины 🗑️`
+
+type TrashConfig struct {
+  // UndoTTL время, в течение которого удаление можно отменить кнопкой под сообщением об удалении.
+  UndoTTL time.Duration `validate:"gt=0"`
+  // TTL время хранения удаленных отслеживаний в корзине.
+  TTL time.Duration `validate:"gt=0"`
+}
+
+func (b *Transport) handleTrackingTrashMenu(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  chatId, ok := findChatIdInUpdate(update)
+  if !ok {
+    log.
+      WithField("update.message", update.Message).
+      WithField("menu", models.TrackingTrashMenu).
+      Warn("chat_id not found")
+
+    return
+  }
+
+  list, err := b.listDeletedTrackings(ctx, chatId, trashLimit)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingTrashMenu).
+      Errorf("b.listDeletedTrackings: %v", err)
+
+    return
+  }
+
+  params := sendMessageParams{
+    ChatId: chatId,
+    Text:   trashEmptyText,
+    Reply: newReplyKeyboard(
+      buttonHelp,
+      buttonTrackingMy,
+      buttonTrackingInsert,
+    ),
+  }
+
+  if len(list) != 0 {
+    params.Text, params.Reply = newTrashMessage(list)
+  }
+
+  if err = b.sendMessage(ctx, params); err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingTrashMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return
+  }
+
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId: chatId,
+    Menu:   models.TrackingTrashMenu,
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingTrashMenu).
+      Errorf("b.upsertSession: %v", err)
+
+    return
+  }
+}
+
+func (b *Transport) handleTrashCallback(ctx context.Context, bot *telegram.Bot, update *tgmodels.Update) {
+  query := update.CallbackQuery
+
+  chatId, ok := findChatIdInMaybeInaccessible(query.Message)
+  if !ok {
+    log.
+      WithField("callback_query.data", query.Data).
+      Warn("chat_id not found")
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  messageId := findMessageIdInMaybeInaccessible(query.Message)
+
+  cmd, id, ok := parseTrashCallbackData(query.Data)
+  if !ok {
+    log.
+      WithField("chat_id", chatId).
+      WithField("callback_query.data", query.Data).
+      Warn("invalid trash callback data")
+
+    b.answerCallback(ctx, query.ID, "")
+    return
+  }
+
+  switch cmd {
+  case trashCmdUndo:
+    b.answerCallback(ctx, query.ID, b.undoTrackingDelete(ctx, chatId, messageId, id))
+
+  case trashCmdRestore:
+    b.answerCallback(ctx, query.ID, b.restoreTrashTracking(ctx, chatId, messageId, id))
+
+  case trashCmdBack:
+    b.answerCallback(ctx, query.ID, "")
+    b.deleteMessage(ctx, chatId, messageId)
+    b.handleTrackingSilentMenu(ctx, bot, query.Message)
+
+  default:
+    log.
+      WithField("chat_id", chatId).
+      WithField("callback_query.data", query.Data).
+      Warn("invalid trash callback data")
+
+    b.answerCallback(ctx, query.ID, "")
+  }
+}
+
+// undoTrackingDelete восстанавливает отслеживание кнопкой под сообщением об удалении. Возвращает текст ответа на нажатие.
+func (b *Transport) undoTrackingDelete(ctx context.Context, chatId int64, messageId int, id models.TrackingId) string {
+  tracking, err := b.findDeletedTracking(ctx, chatId, id)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("tracking.id", id).
+      Errorf("b.findDeletedTracking: %v", err)
+
+    return ""
+  }
+
+  if tracking == nil {
+    return trashOutdatedText
+  }
+
+  if time.Since(*tracking.Timestamps.DeletedAt) > b.config.Trash.UndoTTL {
+    return trashUndoExpiredText
+  }
+
+  if text, ok := b.restoreTracking(ctx, tracking); !ok {
+    return text
+  }
+
+  b.editMessageText(ctx, chatId, messageId, `Удаление отменено ↩️`, nil)
+
+  return ""
+}
+
+// restoreTrashTracking восстанавливает отслеживание из корзины и обновляет список. Возвращает текст ответа на нажатие.
+func (b *Transport) restoreTrashTracking(ctx context.Context, chatId int64, messageId int, id models.TrackingId) string {
+  tracking, err := b.findDeletedTracking(ctx, chatId, id)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("tracking.id", id).
+      Errorf("b.findDeletedTracking: %v", err)
+
+    return ""
+  }
+
+  if tracking == nil {
+    return trashOutdatedText
+  }
+
+  if text, ok := b.restoreTracking(ctx, tracking); !ok {
+    return text
+  }
+
+  list, err := b.listDeletedTrackings(ctx, chatId, trashLimit)
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      Errorf("b.listDeletedTrackings: %v", err)
+  }
+
+  if len(list) == 0 {
+    b.editMessageText(ctx, chatId, messageId, trashEmptyText, nil)
+  } else {
+    text, reply := newTrashMessage(list)
+    b.editMessageText(ctx, chatId, messageId, text, reply)
+  }
+
+  return `Отслеживание восстановлено ↩️`
+}
+
+// restoreTracking возвращает отслеживание из корзины, если у пользователя нет другого отслеживания того же товара.
+// При отказе возвращается текст для пользователя, а ok равен false.
+func (b *Transport) restoreTracking(ctx context.Context, tracking *models.Tracking) (string, bool) {
+  same, err := b.findSameProductTracking(ctx, tracking.ChatId, tracking.URL)
+  if err != nil {
+    log.
+      WithField("chat_id", tracking.ChatId).
+      WithField("tracking.url", tracking.URL).
+      Errorf("b.findSameProductTracking: %v", err)
+
+    return "", false
+  }
+
+  if same != nil {
+    return trackingExistsText, false
+  }
+
+  if err = b.deps.Trackings.Restore(ctx, tracking.ChatId, tracking.URL); err != nil {
+    log.
+      WithField("chat_id", tracking.ChatId).
+      WithField("tracking.url", tracking.URL).
+      Errorf("b.deps.Trackings.Restore: %v", err)
+
+    return "", false
+  }
+
+  return "", true
+}
+
+func (b *Transport) listDeletedTrackings(ctx context.Context, chatId int64, limit int64) ([]*models.Tracking, error) {
+  list, err := b.deps.Trackings.ListDeleted(ctx, chatId, limit)
+  if err != nil {
+    return nil, fmt.Errorf("b.deps.Trackings.ListDeleted: %w", err)
+  }
+
+  return list, nil
+}
+
+// findDeletedTracking ищет отслеживание в корзине по идентификатору. Возвращает nil, если отслеживание не найдено.
+func (b *Transport) findDeletedTracking(ctx context.Context, chatId int64, id models.TrackingId) (*models.Tracking, error) {
+  tracking, err := b.deps.Trackings.GetDeletedById(ctx, chatId, id)
+  if err != nil {
+    if errors.Is(err, models.ErrNotFound) {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("b.deps.Trackings.GetDeletedById: %w", err)
+  }
+
+  return tracking, nil
+}
+
+func newTrashMessage(list []*models.Tracking) (string, tgmodels.InlineKeyboardMarkup) {
+  text := `<b>Корзина 🗑️</b>
+
+Нажмите на отслеживание, чтобы восстановить его ↩️
+`
+
+  rows := make([][]tgmodels.InlineKeyboardButton, 0, len(list)+1)
+
+  for index, tracking := range list {
+    title := strconv.Itoa(index+1) + ". " + makeTrackingTitle(tracking)
+
+    text += fmt.Sprintf(`
+%s
+Будет удалено навсегда %s
+`, html.EscapeString(title), tracking.Timestamps.PurgeAt.Local().Format(dateLayout))
+
+    rows = append(rows, []tgmodels.InlineKeyboardButton{
+      {Text: "↩️ " + title, CallbackData: newTrashCallbackData(trashCmdRestore, tracking.Id())},
+    })
+  }
+
+  rows = append(rows, []tgmodels.InlineKeyboardButton{
+    {Text: "Назад", CallbackData: newTrashCallbackData(trashCmdBack, "")},
+  })
+
+  return text, tgmodels.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func newTrashUndoKeyboard(id models.TrackingId) tgmodels.InlineKeyboardMarkup {
+  return tgmodels.InlineKeyboardMarkup{
+    InlineKeyboard: [][]tgmodels.InlineKeyboardButton{
+      {
+        {Text: "Отменить", CallbackData: newTrashCallbackData(trashCmdUndo, id)},
+      },
+    },
+  }
+}
+
+func newTrashCallbackData(cmd string, id models.TrackingId) string {
+  return trashPrefix + cmd + ":" + id
+}
+
+func parseTrashCallbackData(data string) (cmd string, id models.TrackingId, ok bool) {
+  cmd, id, ok = strings.Cut(strings.TrimPrefix(data, trashPrefix), ":")
+  // Кнопка возврата не относится к отслеживанию и передается без идентификатора.
+  if !ok || cmd == "" || (id == "" && cmd != trashCmdBack) {
+    return "", "", false
+  }
+  return cmd, id, true
+}
@@ -0,0 +1,154 @@
+package telegram
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "unicode/utf16"
+
+  tgmodels "github.com/go-telegram/bot/models"
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/app/tracker"
+  "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/stringer"
+)
+
+// urlChoiceLimit максимальное количество ссылок, предлагаемых на выбор.
+const urlChoiceLimit = 10
+
+const urlChoiceText = `В сообщении несколько ссылок на товары 👀
+Выберите, для какого товара добавить отслеживание, или добавьте все товары сразу 📋`
+
+// extractMessageURLs возвращает ссылки из сообщения без повторов.
+// Ссылки ищутся в сущностях url и text_link, затем в тексте и в превью ссылки.
+// Пересланные посты и фото приходят с текстом и сущностями в самом сообщении, подпись приводится к тексту в withCaptionAsText.
+func extractMessageURLs(message *tgmodels.Message) []string {
+  var urls []string
+
+  for _, entity := range message.Entities {
+    switch entity.Type {
+    case tgmodels.MessageEntityTypeURL:
+      urls = append(urls, stringer.ExtractURL(findEntityText(message.Text, entity)))
+    case tgmodels.MessageEntityTypeTextLink:
+      urls = append(urls, entity.URL)
+    }
+  }
+
+  urls = append(urls, stringer.ExtractURLs(message.Text)...)
+
+  if options := message.LinkPreviewOptions; options != nil && options.URL != nil {
+    urls = append(urls, *options.URL)
+  }
+
+  return lo.Uniq(lo.Compact(urls))
+}
+
+// findEntityText возвращает текст сущности. Смещения сущностей telegram считаются в UTF-16.
+func findEntityText(text string, entity tgmodels.MessageEntity) string {
+  encoded := utf16.Encode([]rune(text))
+
+  if entity.Offset < 0 || entity.Length < 0 || entity.Offset+entity.Length > len(encoded) {
+    return ""
+  }
+  return string(utf16.Decode(encoded[entity.Offset : entity.Offset+entity.Length]))
+}
+
+// findSupportedURLs раскрывает ссылки и оставляет ссылки на поддерживаемые магазины.
+// Временно отключенные магазины остаются, чтобы пользователь получил объяснение при добавлении.
+func (b *Transport) findSupportedURLs(ctx context.Context, urls []string) []string {
+  resolved := lo.Map(urls, func(url string, _ int) string {
+    return b.resolveProductURL(ctx, url)
+  })
+
+  return lo.Uniq(lo.Filter(resolved, func(url string, _ int) bool {
+    return !errors.Is(b.checkProductURL(url), tracker.ErrUnsupportedProductType)
+  }))
+}
+
+// chooseTrackingURL выбирает из сообщения ссылку для нового отслеживания.
+// Если подходящих ссылок несколько, пользователю предлагается выбор, а ok равен false.
+// Если подходящих ссылок нет, возвращается первая найденная ссылка или текст, чтобы пользователь получил объяснение.
+func (b *Transport) chooseTrackingURL(ctx context.Context, chatId int64, message *tgmodels.Message) (text string, ok bool) {
+  urls := extractMessageURLs(message)
+  supported := b.findSupportedURLs(ctx, urls)
+
+  switch {
+  case len(supported) == 1:
+    return supported[0], true
+  case len(supported) == 0 && len(urls) != 0:
+    return urls[0], true
+  case len(supported) == 0:
+    return message.Text, true
+  }
+
+  text = urlChoiceText
+
+  if len(supported) > b.config.Bulk.Limit {
+    text += fmt.Sprintf(`
+За один раз можно добавить до %d товаров, остальные ссылки будут пропущены 👀`, b.config.Bulk.Limit)
+  }
+
+  // Все ссылки сохраняются в сессии для добавления кнопкой «Добавить все».
+  bulk := &models.SessionBulk{
+    URLs: supported[:min(len(supported), b.config.Bulk.Limit)],
+  }
+
+  choice := supported[:min(len(supported), urlChoiceLimit)]
+
+  rows := make([][]tgmodels.KeyboardButton, 0, len(choice)+2)
+  rows = append(rows, []tgmodels.KeyboardButton{{Text: buttonTexts[buttonTrackingBulk]}})
+  for _, url := range choice {
+    rows = append(rows, []tgmodels.KeyboardButton{{Text: url}})
+  }
+  rows = append(rows, []tgmodels.KeyboardButton{{Text: buttonTexts[buttonBack]}})
+
+  err := b.sendMessage(ctx, sendMessageParams{
+    ChatId: chatId,
+    Text:   text,
+    Reply: &tgmodels.ReplyKeyboardMarkup{
+      Keyboard:        rows,
+      ResizeKeyboard:  true,
+      OneTimeKeyboard: true,
+    },
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertMenu).
+      Errorf("b.sendMessage: %v", err)
+
+    return "", false
+  }
+
+  // Выбранная ссылка приходит текстом кнопки и обрабатывается как ввод ссылки.
+  err = b.upsertSession(ctx, upsertSessionParams{
+    ChatId:   chatId,
+    Menu:     models.TrackingInsertMenu,
+    Entities: &models.SessionEntities{Bulk: bulk},
+  })
+  if err != nil {
+    log.
+      WithField("chat_id", chatId).
+      WithField("menu", models.TrackingInsertMenu).
+      Errorf("b.upsertSession: %v", err)
+  }
+
+  return "", false
+}
+
+// withCaptionAsText возвращает копию обновления, в которой подпись к медиа стала текстом сообщения.
+func withCaptionAsText(update *tgmodels.Update) *tgmodels.Update {
+  if update.Message == nil || update.Message.Text != "" || update.Message.Caption == "" {
+    return update
+  }
+
+  message := *update.Message
+  message.Text, message.Entities = message.Caption, message.CaptionEntities
+  message.Caption, message.CaptionEntities = "", nil
+
+  copied := *update
+  copied.Message = &message
+
+  return &copied
+}
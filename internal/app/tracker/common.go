@@ -3,21 +3,60 @@ package tracker
 import (
   "context"
   "fmt"
+  "sort"
+  "strconv"
+  "strings"
   "time"
 
   "github.com/samber/lo"
   log "github.com/sirupsen/logrus"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/cache"
 )
 
-func (c *Tracker) makeTrackingFilters() map[string]any {
-  filters := make(map[string]any)
+func (c *Tracker) makeTrackingFilters() models.TrackingsFilter {
+  filter := models.TrackingsFilter{
+    ProductType:     c.config.ProductType,
+    ExcludePaused:   true,
+    ExcludeArchived: true,
+  }
+
+  for typ := range c.deps.Parsers {
+    if !c.deps.Settings.IsShopEnabled(typ) {
+      filter.ExcludeProductTypes = append(filter.ExcludeProductTypes, typ)
+    }
+  }
 
-  if c.config.ProductType != "" {
-    filters["parsed_product.type"] = c.config.ProductType
+  return filter
+}
+
+func (c *Tracker) claimTrackings(ctx context.Context, handledBefore time.Time) ([]*models.Tracking, error) {
+  list, err := c.deps.Trackings.Claim(ctx, models.ClaimTrackingsParams{
+    Filter:        c.makeTrackingFilters(),
+    Owner:         c.config.Owner,
+    TTL:           c.config.LeaseTTL,
+    Limit:         c.config.BatchSize,
+    HandledBefore: handledBefore,
+  })
+  if err != nil {
+    return nil, fmt.Errorf("c.deps.Trackings.Claim: %w", err)
+  }
+
+  return list, nil
+}
+
+func (c *Tracker) releaseTracking(ctx context.Context, tracking *models.Tracking) error {
+  err := c.deps.Trackings.Release(ctx, models.ReleaseTrackingParams{
+    ChatId:    tracking.ChatId,
+    URL:       tracking.URL,
+    Owner:     c.config.Owner,
+    HandledAt: time.Now(),
+  })
+  if err != nil {
+    return fmt.Errorf("c.deps.Trackings.Release: %w", err)
   }
-  return filters
+
+  return nil
 }
 
 func setTrackingUpdates(tracking *models.Tracking, product *models.Product) {
@@ -26,63 +65,38 @@ func setTrackingUpdates(tracking *models.Tracking, product *models.Product) {
 }
 
 func (c *Tracker) insertMessageIfNotExist(ctx context.Context, message models.SendableMessage) error {
-  common := mongodb.CommonParams{
-    Database:   "outfit",
-    Collection: "messages",
-    StructType: message,
-  }
-
-  res, err := c.deps.Mongodb.Find(ctx, mongodb.FindParams{
-    CommonParams: common,
-    Filters: map[string]any{
-      "chat_id":     message.ChatId,
-      "text.sha256": message.Text.SHA256,
-    },
-    Limit: 1,
-  })
+  inserted, err := c.deps.Messages.InsertIfNotExist(ctx, message)
   if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Find: %w", err)
+    return fmt.Errorf("c.deps.Messages.InsertIfNotExist: %w", err)
   }
 
-  if len(res) != 0 {
+  if !inserted {
     return nil
   }
 
-  _, err = c.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
-    CommonParams: common,
-    Document:     message,
-  })
-  if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Insert: %w", err)
-  }
-
   log.
     WithFields(log.Fields{
       "message.uuid":    message.UUID,
       "message.chat_id": message.ChatId,
     }).
-    Info("new sendable message inserted to trackings mongodb collection")
+    Info("new sendable message inserted to messages collection")
 
   return nil
 }
 
-func (c *Tracker) upsertTracking(ctx context.Context, tracking *models.Tracking) error {
-  _, err := c.deps.Mongodb.Upsert(ctx, mongodb.UpdateParams{
-    GetParams: mongodb.GetParams{
-      CommonParams: mongodb.CommonParams{
-        Database:   "outfit",
-        Collection: "trackings",
-        StructType: tracking,
-      },
-      Filters: map[string]any{
-        "chat_id": tracking.ChatId,
-        "url":     tracking.URL,
-      },
-    },
-    Document: tracking,
-  })
-  if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Upsert: %w", err)
+func (c *Tracker) updateParsedTracking(ctx context.Context, tracking *models.Tracking) error {
+  if err := c.deps.Trackings.UpdateParsed(ctx, tracking); err != nil {
+    return fmt.Errorf("c.deps.Trackings.UpdateParsed: %w", err)
+  }
+
+  return nil
+}
+
+func (c *Tracker) resetTrackingPause(ctx context.Context, tracking *models.Tracking) error {
+  tracking.Pause = models.TrackingPause{}
+
+  if err := c.deps.Trackings.UpdatePause(ctx, tracking); err != nil {
+    return fmt.Errorf("c.deps.Trackings.UpdatePause: %w", err)
   }
 
   return nil
@@ -97,5 +111,66 @@ func (c *Tracker) findParser(productURL string) (models.Parser, error) {
       ErrUnsupportedProductType, productType, productURL)
   }
 
+  if !c.deps.Settings.IsShopEnabled(productType) {
+    return nil, fmt.Errorf("%w: product type: %s. url: %s",
+      ErrDisabledProductType, productType, productURL)
+  }
+
   return parser, nil
 }
+
+// parseCached парсит товар через кэш. Одновременные запросы одного товара выполняются одним запросом к магазину.
+// Товары группируются по ключу товара, поэтому разные ссылки на один товар парсятся один раз.
+func (c *Tracker) parseCached(ctx context.Context, products *cache.Cache[string, *models.Product], parser models.Parser, params models.ParseParams) (*models.Product, error) {
+  // Товар без ключа группируется по ссылке, чтобы старые ссылки нестандартного вида продолжали обрабатываться.
+  productKey, err := parser.ProductKey(params.URL)
+  if err != nil {
+    log.
+      WithField("params.url", params.URL).
+      Warnf("parser.ProductKey: %T: %v", parser, err)
+  }
+
+  load := func(ctx context.Context) (*models.Product, error) {
+    parsed, err := parser.Parse(ctx, params)
+    if err != nil {
+      return nil, fmt.Errorf("parser.Parse: %T: %w", parser, err)
+    }
+    parsed.Key = productKey
+
+    return parsed, nil
+  }
+
+  groupKey := productKey
+  if groupKey == "" {
+    groupKey = params.URL
+  }
+
+  if products == nil {
+    return load(ctx)
+  }
+
+  parsed, err := products.GetOrLoad(ctx, makeParseKey(groupKey, params), load)
+  if err != nil {
+    return nil, fmt.Errorf("products.GetOrLoad: %w", err)
+  }
+
+  // Копия защищает закэшированный товар от изменений вызывающим.
+  // Ссылка остается той, по которой запрошен товар, даже если он распарсен по другой ссылке с тем же ключом.
+  product := *parsed
+  product.URL = params.URL
+
+  return &product, nil
+}
+
+func makeParseKey(groupKey string, params models.ParseParams) string {
+  sizes := append([]string(nil), params.Sizes.Values...)
+  sort.Strings(sizes)
+
+  key := groupKey + "|" + strings.Join(sizes, ",")
+
+  if params.HasDiscount() {
+    key += "|" + strconv.FormatInt(params.Discount.Percent, 10)
+  }
+
+  return key
+}
@@ -0,0 +1,114 @@
+package tracker
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  "github.com/samber/lo"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// archiveTracking переносит отслеживание в архив и однократно оповещает пользователя.
+// Архивные отслеживания не захватываются трекером, пока пользователь их не возобновит.
+func (c *Tracker) archiveTracking(ctx context.Context, tracking *models.Tracking, reason models.ArchiveReason) error {
+  tracking.Archive = &models.TrackingArchive{
+    Reason:     reason,
+    ArchivedAt: time.Now(),
+  }
+  tracking.Timestamps.FailedSince = nil
+
+  result := models.Sendable(tracking.ChatId).
+    SetTrackingPtr(tracking).
+    BuildTrackingArchivedMessage()
+
+  if err := c.insertMessageIfNotExist(ctx, result.Message); err != nil {
+    return fmt.Errorf("c.insertMessageIfNotExist: %w", err)
+  }
+
+  if err := c.updateTrackingArchive(ctx, tracking); err != nil {
+    return fmt.Errorf("c.updateTrackingArchive: %w", err)
+  }
+
+  log.
+    WithFields(log.Fields{
+      "tracking.url":     tracking.URL,
+      "tracking.chat_id": tracking.ChatId,
+      "archive.reason":   reason,
+    }).
+    Info("tracking archived")
+
+  return nil
+}
+
+// handleFailedTracking запоминает начало ошибок парсинга.
+// Если магазин не отвечает дольше FailingTTL, отслеживание архивируется.
+func (c *Tracker) handleFailedTracking(ctx context.Context, tracking *models.Tracking) {
+  // Ошибки из-за остановки трекера не относятся к магазину.
+  if ctx.Err() != nil {
+    return
+  }
+
+  fields := log.Fields{
+    "tracking.url":     tracking.URL,
+    "tracking.chat_id": tracking.ChatId,
+  }
+
+  failedSince := tracking.Timestamps.FailedSince
+
+  if failedSince == nil {
+    tracking.Timestamps.FailedSince = lo.ToPtr(time.Now())
+
+    if err := c.updateTrackingArchive(ctx, tracking); err != nil {
+      log.WithFields(fields).Errorf("c.updateTrackingArchive: %v", err)
+    }
+    return
+  }
+
+  if time.Since(*failedSince) < c.config.FailingTTL {
+    return
+  }
+
+  if err := c.archiveTracking(ctx, tracking, models.ArchiveReasonFailing); err != nil {
+    log.WithFields(fields).Errorf("c.archiveTracking: %v", err)
+  }
+}
+
+// resetFailedTracking сбрасывает время начала ошибок после успешного парсинга.
+func (c *Tracker) resetFailedTracking(ctx context.Context, tracking *models.Tracking) error {
+  if tracking.Timestamps.FailedSince == nil {
+    return nil
+  }
+
+  tracking.Timestamps.FailedSince = nil
+
+  if err := c.updateTrackingArchive(ctx, tracking); err != nil {
+    return fmt.Errorf("c.updateTrackingArchive: %w", err)
+  }
+
+  return nil
+}
+
+// isStaleTracking проверяет, что товар не менялся дольше StaleTTL.
+// Сохраненный товар обновляется только при изменениях, о которых отправлено оповещение.
+func (c *Tracker) isStaleTracking(tracking *models.Tracking, now time.Time) bool {
+  changedAt := tracking.Timestamps.CreatedAt
+
+  if parsedAt := tracking.ParsedProduct.ParsedAt; parsedAt.After(changedAt) {
+    changedAt = parsedAt
+  }
+  if reactivatedAt := tracking.Timestamps.ReactivatedAt; reactivatedAt != nil && reactivatedAt.After(changedAt) {
+    changedAt = *reactivatedAt
+  }
+
+  return now.Sub(changedAt) >= c.config.StaleTTL
+}
+
+func (c *Tracker) updateTrackingArchive(ctx context.Context, tracking *models.Tracking) error {
+  if err := c.deps.Trackings.UpdateArchive(ctx, tracking); err != nil {
+    return fmt.Errorf("c.deps.Trackings.UpdateArchive: %w", err)
+  }
+
+  return nil
+}
@@ -2,11 +2,13 @@ package tracker
 
 import (
   "context"
+  "errors"
   "fmt"
+  "time"
 
   log "github.com/sirupsen/logrus"
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
   "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/cache"
   "github.com/ushakovn/outfit/pkg/worker"
 )
 
@@ -19,69 +21,31 @@ func (c *Tracker) Start(ctx context.Context) error {
     WithField("product_type", c.config.ProductType).
     Info("tracker cron starting")
 
-  pool := worker.NewPool(ctx, worker.DefaultCount)
-
-  err := c.deps.Mongodb.Scan(ctx, mongodb.ScanParams{
-    CommonParams: mongodb.CommonParams{
-      Database:   "outfit",
-      Collection: "trackings",
-      StructType: models.Tracking{},
-    },
-    Sorting: []mongodb.SortParams{
-      {
-        Field: "handled_at",
-        Order: mongodb.SortOrderAsc,
-      },
-    },
-    Filters: c.makeTrackingFilters(),
-
-    Callback: func(ctx context.Context, value any) error {
-      tracking, ok := value.(*models.Tracking)
-      if !ok {
-        log.
-          WithField("tracking.value", value).
-          Errorf("cast tracking %v with type: %[1]T to: %T failed", value, new(models.Tracking))
-
-        return nil
-      }
+  if c.deps.Settings.MaintenanceMode() {
+    log.Warn("maintenance mode enabled. tracker cron skipped")
+    return nil
+  }
 
-      log.
-        WithFields(log.Fields{
-          "tracking.url":     tracking.URL,
-          "tracking.chat_id": tracking.ChatId,
-        }).
-        Info("scanned tracking from mongodb collection")
-
-      pool.Push(func(ctx context.Context) error {
-        if err := c.handleTracking(ctx, tracking); err != nil {
-          log.
-            WithFields(log.Fields{
-              "tracking.url":     tracking.URL,
-              "tracking.chat_id": tracking.ChatId,
-            }).
-            Errorf("tracking handle failed: %v", err)
-
-          return nil
-        }
-
-        log.
-          WithFields(log.Fields{
-            "tracking.url":     tracking.URL,
-            "tracking.chat_id": tracking.ChatId,
-          }).
-          Info("tracking handled successfully")
+  if c.config.ProductType != "" && !c.deps.Settings.IsShopEnabled(c.config.ProductType) {
+    log.
+      WithField("product_type", c.config.ProductType).
+      Warn("shop disabled. tracker cron skipped")
 
-        return nil
-      })
+    return nil
+  }
 
-      return nil
-    },
-  })
+  run, err := c.deps.Runs.Start(ctx, models.TrackerCronName, c.config.ProductType)
   if err != nil {
-    return fmt.Errorf("c.deps.Mongodb.Scan: %w", err)
+    return fmt.Errorf("c.deps.Runs.Start: %w", err)
   }
 
-  pool.StopWait()
+  err = c.scan(ctx)
+
+  c.deps.Runs.Finish(ctx, run, err)
+
+  if err != nil {
+    return fmt.Errorf("c.scan: %w", err)
+  }
 
   log.
     WithField("product_type", c.config.ProductType).
@@ -90,6 +54,100 @@ func (c *Tracker) Start(ctx context.Context) error {
   return nil
 }
 
+// scan обрабатывает отслеживания пачками, захваченными в аренду.
+// Несколько экземпляров трекера могут работать одновременно, не обрабатывая одно отслеживание дважды.
+func (c *Tracker) scan(ctx context.Context) error {
+  pool := worker.NewPool(ctx, worker.Config{
+    Count: c.deps.Settings.TrackerWorkers(),
+  })
+
+  // Граница не зависит от времени запуска экземпляра, поэтому экземпляр, запущенный позже,
+  // не захватывает отслеживания, уже обработанные другими экземплярами в этом запуске.
+  handledBefore := time.Now().Add(-c.config.HandleInterval)
+
+  // Отслеживания одного товара с одинаковыми размерами в разных чатах парсятся один раз за сканирование.
+  products := cache.NewCache[string, *models.Product](ctx, cache.Config{})
+
+  for {
+    // Настройки могли измениться во время сканирования.
+    if c.deps.Settings.MaintenanceMode() {
+      log.Warn("maintenance mode enabled. tracker cron scan stopped")
+      break
+    }
+
+    batch, err := c.claimTrackings(ctx, handledBefore)
+    if err != nil {
+      pool.Cancel()
+      return fmt.Errorf("c.claimTrackings: %w", err)
+    }
+
+    if len(batch) == 0 {
+      break
+    }
+
+    log.
+      WithFields(log.Fields{
+        "tracker.owner":   c.config.Owner,
+        "trackings.count": len(batch),
+      }).
+      Info("trackings batch claimed")
+
+    for _, tracking := range batch {
+      tracking := tracking
+
+      err = pool.Push(ctx, func(ctx context.Context) error {
+        c.handleClaimedTracking(ctx, products, tracking)
+        return nil
+      })
+      if err != nil {
+        pool.Cancel()
+        return fmt.Errorf("pool.Push: %w", err)
+      }
+    }
+  }
+
+  if err := pool.Drain(); err != nil {
+    return fmt.Errorf("pool.Drain: %w", err)
+  }
+
+  logPoolStats(pool.Stats())
+
+  return nil
+}
+
+func logPoolStats(stats worker.Stats) {
+  log.
+    WithFields(log.Fields{
+      "pool.pushed":      stats.Pushed,
+      "pool.completed":   stats.Completed,
+      "pool.failed":      stats.Failed,
+      "pool.avg_wait":    stats.AvgWait.String(),
+      "pool.avg_latency": stats.AvgLatency.String(),
+      "pool.max_latency": stats.MaxLatency.String(),
+    }).
+    Info("tracker pool drained")
+}
+
+func (c *Tracker) handleClaimedTracking(ctx context.Context, products *cache.Cache[string, *models.Product], tracking *models.Tracking) {
+  fields := log.Fields{
+    "tracking.url":     tracking.URL,
+    "tracking.chat_id": tracking.ChatId,
+  }
+
+  // Время обработки сохраняется и при ошибке или панике, чтобы отслеживание не захватывалось повторно в этом запуске.
+  defer func() {
+    if err := c.releaseTracking(ctx, tracking); err != nil {
+      log.WithFields(fields).Errorf("c.releaseTracking: %v", err)
+    }
+  }()
+
+  if err := c.handleTracking(ctx, products, tracking); err != nil {
+    log.WithFields(fields).Errorf("tracking handle failed: %v", err)
+  } else {
+    log.WithFields(fields).Info("tracking handled successfully")
+  }
+}
+
 func (c *Tracker) CheckProductURL(url string) error {
   if _, err := c.findParser(url); err != nil {
     return fmt.Errorf("c.findParser: %w", err)
@@ -97,6 +155,21 @@ func (c *Tracker) CheckProductURL(url string) error {
   return nil
 }
 
+// FindProductKey возвращает канонический ключ товара по ссылке.
+func (c *Tracker) FindProductKey(url string) (models.ProductKey, error) {
+  parser, err := c.findParser(url)
+  if err != nil {
+    return "", fmt.Errorf("c.findParser: %w", err)
+  }
+
+  key, err := parser.ProductKey(url)
+  if err != nil {
+    return "", fmt.Errorf("parser.ProductKey: %T: %w", parser, err)
+  }
+
+  return key, nil
+}
+
 type CreateMessageParams struct {
   ChatId   int64
   URL      string
@@ -110,13 +183,13 @@ func (c *Tracker) CreateMessage(ctx context.Context, params CreateMessageParams)
     return nil, fmt.Errorf("c.findParser: %w", err)
   }
 
-  parsed, err := parser.Parse(ctx, models.ParseParams{
+  parsed, err := c.parseCached(ctx, c.deps.Products, parser, models.ParseParams{
     URL:      params.URL,
     Sizes:    params.Sizes,
     Discount: params.Discount,
   })
   if err != nil {
-    return nil, fmt.Errorf("parser.Parse: %T: %w", parser, err)
+    return nil, fmt.Errorf("c.parseCached: %w", err)
   }
 
   result := models.Sendable(params.ChatId).
@@ -126,21 +199,39 @@ func (c *Tracker) CreateMessage(ctx context.Context, params CreateMessageParams)
   return &result.Message, nil
 }
 
-func (c *Tracker) handleTracking(ctx context.Context, tracking *models.Tracking) error {
+func (c *Tracker) handleTracking(ctx context.Context, products *cache.Cache[string, *models.Product], tracking *models.Tracking) error {
+  if tracking.Timestamps.IsExpired(time.Now()) {
+    return c.archiveTracking(ctx, tracking, models.ArchiveReasonExpired)
+  }
+
   parser, err := c.findParser(tracking.ParsedProduct.URL)
   if err != nil {
     return fmt.Errorf("c.findParser: %w", err)
   }
 
-  parsed, err := parser.Parse(ctx, models.ParseParams{
+  parsed, err := c.parseCached(ctx, products, parser, models.ParseParams{
     URL:   tracking.URL,
     Sizes: tracking.Sizes,
   })
   if err != nil {
-    return fmt.Errorf("parser.Parse: %T: %w", parser, err)
+    if errors.Is(err, models.ErrProductNotFound) {
+      return c.archiveTracking(ctx, tracking, models.ArchiveReasonDelisted)
+    }
+
+    c.handleFailedTracking(ctx, tracking)
+
+    return fmt.Errorf("c.parseCached: %w", err)
+  }
+
+  if err = c.resetFailedTracking(ctx, tracking); err != nil {
+    return fmt.Errorf("c.resetFailedTracking: %w", err)
+  }
+
+  if tracking.Pause.IsEnded(time.Now()) {
+    return c.handleResumedTracking(ctx, tracking, parsed)
   }
 
-  diff := models.NewProductDiff(tracking.ParsedProduct, *parsed)
+  diff := models.NewProductDiff(tracking.ParsedProduct, *parsed, c.deps.Settings.SellUpThreshold())
 
   result := models.Sendable(tracking.ChatId).
     SetTrackingPtr(tracking).
@@ -149,6 +240,9 @@ func (c *Tracker) handleTracking(ctx context.Context, tracking *models.Tracking)
     BuildProductDiffMessage()
 
   if !result.IsValid {
+    if c.isStaleTracking(tracking, time.Now()) {
+      return c.archiveTracking(ctx, tracking, models.ArchiveReasonStale)
+    }
     return nil
   }
 
@@ -158,8 +252,36 @@ func (c *Tracker) handleTracking(ctx context.Context, tracking *models.Tracking)
 
   setTrackingUpdates(tracking, parsed)
 
-  if err = c.upsertTracking(ctx, tracking); err != nil {
-    return fmt.Errorf("c.upsertTracking: %w", err)
+  if err = c.updateParsedTracking(ctx, tracking); err != nil {
+    return fmt.Errorf("c.updateParsedTracking: %w", err)
+  }
+
+  return nil
+}
+
+// handleResumedTracking отправляет сводку изменений за время паузы и обновляет сохраненный товар.
+// Следующие оповещения сравнивают товар уже с состоянием после паузы.
+func (c *Tracker) handleResumedTracking(ctx context.Context, tracking *models.Tracking, parsed *models.Product) error {
+  diff := models.NewProductDiff(tracking.ParsedProduct, *parsed, c.deps.Settings.SellUpThreshold())
+
+  result := models.Sendable(tracking.ChatId).
+    SetTrackingPtr(tracking).
+    SetProductPtr(parsed).
+    SetProductDiffPtr(diff).
+    BuildProductSummaryMessage()
+
+  if err := c.insertMessageIfNotExist(ctx, result.Message); err != nil {
+    return fmt.Errorf("c.insertMessageIfNotExist: %w", err)
+  }
+
+  setTrackingUpdates(tracking, parsed)
+
+  if err := c.updateParsedTracking(ctx, tracking); err != nil {
+    return fmt.Errorf("c.updateParsedTracking: %w", err)
+  }
+
+  if err := c.resetTrackingPause(ctx, tracking); err != nil {
+    return fmt.Errorf("c.resetTrackingPause: %w", err)
   }
 
   return nil
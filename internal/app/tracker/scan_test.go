@@ -0,0 +1,304 @@
+package tracker
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "os"
+  "sync"
+  "testing"
+  "time"
+
+  "github.com/google/uuid"
+  "go.uber.org/atomic"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/memrepo"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongorepo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+// testMongodbURIEnv строка подключения к тестовой MongoDB. Без нее тесты с MongoDB пропускаются.
+const testMongodbURIEnv = "OUTFIT_TEST_MONGODB_URI"
+
+// countingMessages считает вставленные сообщения и попытки вставить уже существующие.
+type countingMessages struct {
+  models.MessagesRepository
+
+  inserted   atomic.Int64
+  duplicated atomic.Int64
+}
+
+func (m *countingMessages) InsertIfNotExist(ctx context.Context, message models.SendableMessage) (bool, error) {
+  inserted, err := m.MessagesRepository.InsertIfNotExist(ctx, message)
+  if err != nil {
+    return false, err
+  }
+
+  if inserted {
+    m.inserted.Inc()
+  } else {
+    m.duplicated.Inc()
+  }
+
+  return inserted, nil
+}
+
+func TestScanConcurrentTrackersMemrepo(t *testing.T) {
+  repos := memrepo.New()
+
+  testScanConcurrentTrackers(t, repos.Trackings, repos.Messages)
+}
+
+func TestScanReclaimsExpiredLeaseMemrepo(t *testing.T) {
+  repos := memrepo.New()
+
+  testScanReclaimsExpiredLease(t, repos.Trackings, repos.Messages)
+}
+
+func TestScanConcurrentTrackersMongodb(t *testing.T) {
+  repos := newTestMongoRepositories(t)
+
+  testScanConcurrentTrackers(t, repos.Trackings, repos.Messages)
+}
+
+func TestScanReclaimsExpiredLeaseMongodb(t *testing.T) {
+  repos := newTestMongoRepositories(t)
+
+  testScanReclaimsExpiredLease(t, repos.Trackings, repos.Messages)
+}
+
+// testScanConcurrentTrackers запускает несколько трекеров с разными владельцами над одним хранилищем.
+func testScanConcurrentTrackers(t *testing.T, trackings models.TrackingsRepository, messages models.MessagesRepository) {
+  const (
+    count    = 60
+    trackers = 3
+  )
+
+  ctx := context.Background()
+
+  parser := newFakeParser()
+  // Задержка магазина позволяет трекерам захватывать пачки одновременно.
+  parser.delay = 2 * time.Millisecond
+
+  urls := make([]string, 0, count)
+
+  for index := 0; index < count; index++ {
+    productURL := fmt.Sprintf("https://www.lamoda.ru/p/item%03d/", index)
+    urls = append(urls, productURL)
+
+    tracking := newTestTracking(productURL, 1000)
+    tracking.ChatId = int64(index%5 + 1)
+
+    if err := trackings.Insert(ctx, tracking); err != nil {
+      t.Fatalf("trackings.Insert: %v", err)
+    }
+    parser.setProduct(newTestProduct(productURL, 800))
+  }
+
+  counting := &countingMessages{MessagesRepository: messages}
+
+  var wg sync.WaitGroup
+
+  errs := make(chan error, trackers)
+
+  for index := 0; index < trackers; index++ {
+    tracker := newTestTracker(trackings, counting, parser, Config{
+      Owner:     fmt.Sprintf("tracker-%d", index),
+      BatchSize: 4,
+    })
+
+    wg.Add(1)
+
+    go func() {
+      defer wg.Done()
+      errs <- tracker.scan(ctx)
+    }()
+  }
+
+  wg.Wait()
+  close(errs)
+
+  for err := range errs {
+    if err != nil {
+      t.Fatalf("scan() error = %v", err)
+    }
+  }
+
+  for index, productURL := range urls {
+    if calls := parser.callsCount(productURL); calls != 1 {
+      t.Fatalf("tracking %s handled %d times, want once", productURL, calls)
+    }
+
+    tracking, err := trackings.Get(ctx, int64(index%5+1), productURL)
+    if err != nil {
+      t.Fatalf("trackings.Get: %v", err)
+    }
+    if tracking.Lease != nil || tracking.Timestamps.HandledAt == nil {
+      t.Fatalf("tracking %s lease = %+v, handled at = %v, want released", productURL, tracking.Lease, tracking.Timestamps.HandledAt)
+    }
+  }
+
+  if inserted, duplicated := counting.inserted.Load(), counting.duplicated.Load(); inserted != count || duplicated != 0 {
+    t.Fatalf("messages inserted = %d, duplicated = %d, want %d inserted and none duplicated", inserted, duplicated, count)
+  }
+
+  // Трекер, запущенный после остальных, не обрабатывает отслеживания, уже обработанные в этом запуске.
+  late := newTestTracker(trackings, counting, parser, Config{Owner: "tracker-late"})
+
+  if err := late.scan(ctx); err != nil {
+    t.Fatalf("scan() error = %v", err)
+  }
+  for _, productURL := range urls {
+    if calls := parser.callsCount(productURL); calls != 1 {
+      t.Fatalf("tracking %s handled %d times by late tracker, want once", productURL, calls)
+    }
+  }
+}
+
+// testScanReclaimsExpiredLease проверяет, что отслеживание упавшего трекера обрабатывается после истечения аренды.
+func testScanReclaimsExpiredLease(t *testing.T, trackings models.TrackingsRepository, messages models.MessagesRepository) {
+  const (
+    productURL = "https://www.lamoda.ru/p/rtlacv500501/"
+    leaseTTL   = 300 * time.Millisecond
+  )
+
+  ctx := context.Background()
+
+  parser := newFakeParser()
+  parser.setProduct(newTestProduct(productURL, 800))
+
+  if err := trackings.Insert(ctx, newTestTracking(productURL, 1000)); err != nil {
+    t.Fatalf("trackings.Insert: %v", err)
+  }
+
+  // Упавший трекер захватил отслеживание и не освободил его.
+  claimed, err := trackings.Claim(ctx, models.ClaimTrackingsParams{
+    Owner:         "dead",
+    TTL:           leaseTTL,
+    Limit:         1,
+    HandledBefore: time.Now(),
+  })
+  if err != nil {
+    t.Fatalf("trackings.Claim: %v", err)
+  }
+  if len(claimed) != 1 {
+    t.Fatalf("claimed = %d, want 1", len(claimed))
+  }
+  claimedAt := time.Now()
+
+  counting := &countingMessages{MessagesRepository: messages}
+  tracker := newTestTracker(trackings, counting, parser, Config{Owner: "alive"})
+
+  if err = tracker.scan(ctx); err != nil {
+    t.Fatalf("scan() error = %v", err)
+  }
+  if calls := parser.callsCount(productURL); calls != 0 {
+    t.Fatalf("tracking handled %d times under active lease, want 0", calls)
+  }
+
+  time.Sleep(leaseTTL - time.Since(claimedAt) + 50*time.Millisecond)
+
+  if err = tracker.scan(ctx); err != nil {
+    t.Fatalf("scan() error = %v", err)
+  }
+  if calls := parser.callsCount(productURL); calls != 1 {
+    t.Fatalf("tracking handled %d times after lease expiry, want 1", calls)
+  }
+  if inserted := counting.inserted.Load(); inserted != 1 {
+    t.Fatalf("messages inserted = %d, want 1", inserted)
+  }
+
+  handled, err := trackings.Get(ctx, testChatId, productURL)
+  if err != nil {
+    t.Fatalf("trackings.Get: %v", err)
+  }
+  if handled.Lease != nil || handled.Timestamps.HandledAt == nil {
+    t.Fatalf("lease = %+v, handled at = %v, want released by alive owner", handled.Lease, handled.Timestamps.HandledAt)
+  }
+
+  // Освобождение прежним владельцем не затирает результат нового.
+  err = trackings.Release(ctx, models.ReleaseTrackingParams{
+    ChatId:    testChatId,
+    URL:       productURL,
+    Owner:     "dead",
+    HandledAt: time.Now().Add(time.Hour),
+  })
+  if err != nil {
+    t.Fatalf("trackings.Release: %v", err)
+  }
+
+  got, err := trackings.Get(ctx, testChatId, productURL)
+  if err != nil {
+    t.Fatalf("trackings.Get: %v", err)
+  }
+  if !got.Timestamps.HandledAt.Equal(*handled.Timestamps.HandledAt) {
+    t.Fatalf("handled at = %v after stale release, want %v", got.Timestamps.HandledAt, handled.Timestamps.HandledAt)
+  }
+}
+
+// newTestMongoRepositories создает репозитории в отдельной базе и очищает ее после теста.
+func newTestMongoRepositories(t *testing.T) *mongorepo.Repositories {
+  t.Helper()
+
+  uri := os.Getenv(testMongodbURIEnv)
+  if uri == "" {
+    t.Skipf("%s is not set", testMongodbURIEnv)
+  }
+
+  ctx := context.Background()
+
+  client, err := mongodb.NewClient(ctx,
+    mongodb.Config{
+      URI:                    uri,
+      ServerSelectionTimeout: 5 * time.Second,
+    },
+    mongodb.Dependencies{
+      Client: http.DefaultClient,
+    })
+  if err != nil {
+    t.Fatalf("mongodb.NewClient: %v", err)
+  }
+
+  config := mongorepo.Config{
+    Database: "outfit_test_" + uuid.NewString()[:8],
+    Collections: mongorepo.Collections{
+      Trackings:      "trackings",
+      Sessions:       "sessions",
+      Messages:       "messages",
+      Issues:         "issues",
+      Runs:           "runs",
+      Migrations:     "migrations",
+      MigrationsLock: "migrations_lock",
+      Sliders:        "sliders",
+      DeepLinks:      "deep_links",
+    },
+  }
+
+  repos, err := mongorepo.New(config, mongorepo.Dependencies{Mongodb: client})
+  if err != nil {
+    t.Fatalf("mongorepo.New: %v", err)
+  }
+
+  if err = repos.Migrate(ctx, mongorepo.MigrateParams{
+    ProductKey: newFakeParser().ProductKey,
+  }); err != nil {
+    t.Fatalf("repos.Migrate: %v", err)
+  }
+
+  t.Cleanup(func() {
+    for _, collection := range []string{config.Collections.Trackings, config.Collections.Messages} {
+      _, err := client.Delete(ctx, mongodb.DeleteParams{
+        CommonParams: mongodb.CommonParams{
+          Database:   config.Database,
+          Collection: collection,
+        },
+      })
+      if err != nil {
+        t.Errorf("client.Delete: %v", err)
+      }
+    }
+  })
+
+  return repos
+}
@@ -2,12 +2,30 @@ package tracker
 
 import (
   "errors"
+  "fmt"
+  "os"
+  "time"
 
-  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/google/uuid"
+
+  "github.com/ushakovn/outfit/internal/app/runs"
   "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/cache"
 )
 
-var ErrUnsupportedProductType = errors.New("unsupported product type")
+const (
+  defaultLeaseTTL  = 10 * time.Minute
+  defaultBatchSize = 20
+  // defaultHandleInterval меньше периода запуска крона, чтобы отслеживание обрабатывалось в каждом запуске.
+  defaultHandleInterval = 30 * time.Minute
+  defaultFailingTTL     = 14 * 24 * time.Hour
+  defaultStaleTTL       = 182 * 24 * time.Hour
+)
+
+var (
+  ErrUnsupportedProductType = errors.New("unsupported product type")
+  ErrDisabledProductType    = errors.New("disabled product type")
+)
 
 type Tracker struct {
   config Config
@@ -17,23 +35,74 @@ type Tracker struct {
 type Config struct {
   IsCron      bool
   ProductType models.ProductType
+  // Owner идентификатор экземпляра для аренды отслеживаний.
+  // Должен быть уникальным среди одновременно работающих трекеров.
+  Owner     string
+  LeaseTTL  time.Duration
+  BatchSize int
+  // HandleInterval минимальный интервал между обработками одного отслеживания.
+  // Отслеживания, обработанные позже этого интервала назад, не захватываются ни одним экземпляром.
+  HandleInterval time.Duration
+  // FailingTTL время ошибок парсинга подряд, после которого отслеживание архивируется.
+  FailingTTL time.Duration
+  // StaleTTL время без изменений товара, после которого отслеживание архивируется.
+  StaleTTL time.Duration
 }
 
 type Dependencies struct {
-  Mongodb *mongodb.Client
-  Parsers map[models.ProductType]models.Parser
+  Trackings models.TrackingsRepository
+  Messages  models.MessagesRepository
+  Parsers   map[models.ProductType]models.Parser
+  Runs      *runs.Recorder
+  Settings  Settings
+  // Products необязательный кэш распарсенных товаров для запросов из бота.
+  Products *cache.Cache[string, *models.Product]
+}
+
+// Settings настройки, изменяемые без перезапуска.
+type Settings interface {
+  MaintenanceMode() bool
+  IsShopEnabled(typ models.ProductType) bool
+  SellUpThreshold() int64
+  TrackerWorkers() int
 }
 
 func NewTracker(deps Dependencies) *Tracker {
   return &Tracker{deps: deps}
 }
 
-func NewTrackerCron(typ models.ProductType, deps Dependencies) *Tracker {
+func NewTrackerCron(config Config, deps Dependencies) *Tracker {
+  config.IsCron = true
+
+  if config.Owner == "" {
+    config.Owner = defaultOwner()
+  }
+  if config.LeaseTTL == 0 {
+    config.LeaseTTL = defaultLeaseTTL
+  }
+  if config.BatchSize == 0 {
+    config.BatchSize = defaultBatchSize
+  }
+  if config.HandleInterval == 0 {
+    config.HandleInterval = defaultHandleInterval
+  }
+  if config.FailingTTL == 0 {
+    config.FailingTTL = defaultFailingTTL
+  }
+  if config.StaleTTL == 0 {
+    config.StaleTTL = defaultStaleTTL
+  }
+
   return &Tracker{
-    config: Config{
-      IsCron:      true,
-      ProductType: typ,
-    },
-    deps: deps,
+    config: config,
+    deps:   deps,
+  }
+}
+
+func defaultOwner() string {
+  hostname, err := os.Hostname()
+  if err != nil {
+    hostname = "unknown"
   }
+  return fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), uuid.NewString()[:8])
 }
@@ -0,0 +1,391 @@
+package tracker
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net/url"
+  "slices"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+
+  "github.com/samber/lo"
+  "github.com/ushakovn/outfit/internal/deps/storage/memrepo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+const testChatId int64 = 1
+
+var errShopUnavailable = errors.New("shop unavailable")
+
+// fakeParser возвращает товары по ссылке и считает запросы к магазину.
+type fakeParser struct {
+  mu       sync.Mutex
+  products map[models.ProductURL]models.Product
+  errs     map[models.ProductURL]error
+  calls    map[models.ProductURL]int
+  // delay имитирует время ответа магазина.
+  delay time.Duration
+}
+
+func newFakeParser() *fakeParser {
+  return &fakeParser{
+    products: make(map[models.ProductURL]models.Product),
+    errs:     make(map[models.ProductURL]error),
+    calls:    make(map[models.ProductURL]int),
+  }
+}
+
+func (p *fakeParser) Parse(ctx context.Context, params models.ParseParams) (*models.Product, error) {
+  time.Sleep(p.delay)
+
+  p.mu.Lock()
+  defer p.mu.Unlock()
+
+  p.calls[params.URL]++
+
+  if err := p.errs[params.URL]; err != nil {
+    return nil, err
+  }
+
+  product, ok := p.products[params.URL]
+  if !ok {
+    return nil, fmt.Errorf("product %s: %w", params.URL, models.ErrProductNotFound)
+  }
+  product.ParsedAt = time.Now()
+
+  return &product, nil
+}
+
+func (p *fakeParser) ProductKey(productURL string) (models.ProductKey, error) {
+  parsed, err := url.Parse(productURL)
+  if err != nil {
+    return "", err
+  }
+  return models.ProductTypeLamoda + ":" + strings.Trim(parsed.Path, "/"), nil
+}
+
+func (p *fakeParser) setProduct(product models.Product) {
+  p.mu.Lock()
+  defer p.mu.Unlock()
+
+  p.products[product.URL] = product
+}
+
+func (p *fakeParser) setErr(productURL string, err error) {
+  p.mu.Lock()
+  defer p.mu.Unlock()
+
+  p.errs[productURL] = err
+}
+
+func (p *fakeParser) callsCount(productURL string) int {
+  p.mu.Lock()
+  defer p.mu.Unlock()
+
+  return p.calls[productURL]
+}
+
+type fakeSettings struct {
+  workers int
+}
+
+func (s fakeSettings) MaintenanceMode() bool                   { return false }
+func (s fakeSettings) IsShopEnabled(_ models.ProductType) bool { return true }
+func (s fakeSettings) SellUpThreshold() int64                  { return models.DefaultSellUpThreshold }
+func (s fakeSettings) TrackerWorkers() int                     { return s.workers }
+
+func newTestTracker(trackings models.TrackingsRepository, messages models.MessagesRepository, parser *fakeParser, config Config) *Tracker {
+  return NewTrackerCron(config, Dependencies{
+    Trackings: trackings,
+    Messages:  messages,
+    Parsers:   map[models.ProductType]models.Parser{models.ProductTypeLamoda: parser},
+    Settings:  fakeSettings{workers: 4},
+  })
+}
+
+func newTestProduct(productURL string, price int64) models.Product {
+  return models.Product{
+    URL:      productURL,
+    Type:     models.ProductTypeLamoda,
+    Brand:    "Carhartt WIP",
+    Category: "Джинсы",
+    Options: []models.ProductOption{
+      {
+        Size:  models.ProductSizeOptions{Base: models.ProductSize{Value: "M"}},
+        Stock: models.ProductStock{Quantity: 10},
+        Price: models.ProductPriceOptions{Discount: models.ProductPrice{IntValue: price}},
+      },
+    },
+  }
+}
+
+func newTestTracking(productURL string, price int64) models.Tracking {
+  return models.Tracking{
+    ChatId:        testChatId,
+    URL:           productURL,
+    Sizes:         models.ParseSizesParams{Values: []string{"M"}},
+    ParsedProduct: newTestProduct(productURL, price),
+    Timestamps:    models.TrackingTimestamps{CreatedAt: time.Now()},
+  }
+}
+
+func TestHandleTracking(t *testing.T) {
+  const productURL = "https://www.lamoda.ru/p/rtlacv500501/"
+
+  daysAgo := func(days int) *time.Time {
+    return lo.ToPtr(time.Now().AddDate(0, 0, -days))
+  }
+
+  tests := []struct {
+    name string
+    // prepare изменяет сохраненное отслеживание и ответ магазина.
+    prepare func(tracking *models.Tracking, parser *fakeParser)
+
+    wantErr      bool
+    wantMessages []models.SendableType
+    wantPrice    int64
+    wantArchive  models.ArchiveReason
+    wantFailed   bool
+    wantHandled  bool
+  }{
+    {
+      name: "price drop inserts message and stores parsed product",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        parser.setProduct(newTestProduct(productURL, 800))
+      },
+      wantMessages: []models.SendableType{models.ProductDiffSendableType},
+      wantPrice:    800,
+      wantHandled:  true,
+    },
+    {
+      name: "no changes inserts nothing",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        parser.setProduct(newTestProduct(productURL, 1000))
+      },
+      wantPrice: 1000,
+    },
+    {
+      name: "first failure records failed since",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        parser.setErr(productURL, errShopUnavailable)
+      },
+      wantErr:    true,
+      wantPrice:  1000,
+      wantFailed: true,
+    },
+    {
+      name: "repeated failure within failing ttl keeps failed since",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        tracking.Timestamps.FailedSince = daysAgo(1)
+        parser.setErr(productURL, errShopUnavailable)
+      },
+      wantErr:    true,
+      wantPrice:  1000,
+      wantFailed: true,
+    },
+    {
+      name: "failure longer than failing ttl archives",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        tracking.Timestamps.FailedSince = daysAgo(15)
+        parser.setErr(productURL, errShopUnavailable)
+      },
+      wantErr:      true,
+      wantMessages: []models.SendableType{models.TrackingArchivedSendableType},
+      wantPrice:    1000,
+      wantArchive:  models.ArchiveReasonFailing,
+    },
+    {
+      name: "success after failures resets failed since",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        tracking.Timestamps.FailedSince = daysAgo(3)
+        parser.setProduct(newTestProduct(productURL, 1000))
+      },
+      wantPrice: 1000,
+    },
+    {
+      name:         "delisted product archives",
+      prepare:      func(tracking *models.Tracking, parser *fakeParser) {},
+      wantMessages: []models.SendableType{models.TrackingArchivedSendableType},
+      wantPrice:    1000,
+      wantArchive:  models.ArchiveReasonDelisted,
+    },
+    {
+      name: "expired tracking archives without parsing",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        tracking.Timestamps.ExpiresAt = daysAgo(1)
+        parser.setProduct(newTestProduct(productURL, 800))
+      },
+      wantMessages: []models.SendableType{models.TrackingArchivedSendableType},
+      wantPrice:    1000,
+      wantArchive:  models.ArchiveReasonExpired,
+    },
+    {
+      name: "unchanged product longer than stale ttl archives",
+      prepare: func(tracking *models.Tracking, parser *fakeParser) {
+        tracking.Timestamps.CreatedAt = *daysAgo(200)
+        tracking.ParsedProduct.ParsedAt = *daysAgo(200)
+        parser.setProduct(newTestProduct(productURL, 1000))
+      },
+      wantMessages: []models.SendableType{models.TrackingArchivedSendableType},
+      wantPrice:    1000,
+      wantArchive:  models.ArchiveReasonStale,
+    },
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      ctx := context.Background()
+
+      repos := memrepo.New()
+      parser := newFakeParser()
+      tracker := newTestTracker(repos.Trackings, repos.Messages, parser, Config{})
+
+      stored := newTestTracking(productURL, 1000)
+      tt.prepare(&stored, parser)
+
+      if err := repos.Trackings.Insert(ctx, stored); err != nil {
+        t.Fatalf("repos.Trackings.Insert: %v", err)
+      }
+
+      tracking, err := repos.Trackings.Get(ctx, testChatId, productURL)
+      if err != nil {
+        t.Fatalf("repos.Trackings.Get: %v", err)
+      }
+
+      err = tracker.handleTracking(ctx, nil, tracking)
+      if (err != nil) != tt.wantErr {
+        t.Fatalf("handleTracking() error = %v, wantErr %v", err, tt.wantErr)
+      }
+
+      messages := lo.Map(repos.Messages.List(), func(message models.SendableMessage, _ int) models.SendableType {
+        return message.Type
+      })
+      if !slices.Equal(messages, tt.wantMessages) {
+        t.Fatalf("messages = %v, want %v", messages, tt.wantMessages)
+      }
+
+      got, err := repos.Trackings.Get(ctx, testChatId, productURL)
+      if err != nil {
+        t.Fatalf("repos.Trackings.Get: %v", err)
+      }
+
+      if price := got.ParsedProduct.Options[0].Price.Discount.IntValue; price != tt.wantPrice {
+        t.Fatalf("stored price = %d, want %d", price, tt.wantPrice)
+      }
+
+      switch {
+      case tt.wantArchive == "" && got.Archive != nil:
+        t.Fatalf("tracking archived with reason %s, want active", got.Archive.Reason)
+      case tt.wantArchive != "" && (got.Archive == nil || got.Archive.Reason != tt.wantArchive):
+        t.Fatalf("archive = %+v, want reason %s", got.Archive, tt.wantArchive)
+      }
+
+      if failed := got.Timestamps.FailedSince != nil; failed != tt.wantFailed {
+        t.Fatalf("failed since = %v, want set %v", got.Timestamps.FailedSince, tt.wantFailed)
+      }
+
+      // Сохраненный товар обновляется только при изменениях, о которых отправлено оповещение.
+      if handled := got.Timestamps.HandledAt != nil; handled != tt.wantHandled {
+        t.Fatalf("handled at = %v, want set %v", got.Timestamps.HandledAt, tt.wantHandled)
+      }
+    })
+  }
+}
+
+func TestHandleTrackingKeepsFirstFailure(t *testing.T) {
+  const productURL = "https://www.lamoda.ru/p/rtlacv500501/"
+
+  ctx := context.Background()
+
+  repos := memrepo.New()
+  parser := newFakeParser()
+  tracker := newTestTracker(repos.Trackings, repos.Messages, parser, Config{})
+
+  parser.setErr(productURL, errShopUnavailable)
+
+  if err := repos.Trackings.Insert(ctx, newTestTracking(productURL, 1000)); err != nil {
+    t.Fatalf("repos.Trackings.Insert: %v", err)
+  }
+
+  var first time.Time
+
+  for attempt := 0; attempt < 3; attempt++ {
+    tracking, err := repos.Trackings.Get(ctx, testChatId, productURL)
+    if err != nil {
+      t.Fatalf("repos.Trackings.Get: %v", err)
+    }
+
+    if err = tracker.handleTracking(ctx, nil, tracking); err == nil {
+      t.Fatal("handleTracking() error = nil, want parse error")
+    }
+
+    got, err := repos.Trackings.Get(ctx, testChatId, productURL)
+    if err != nil {
+      t.Fatalf("repos.Trackings.Get: %v", err)
+    }
+    if got.Timestamps.FailedSince == nil {
+      t.Fatalf("attempt %d: failed since not set", attempt)
+    }
+
+    if attempt == 0 {
+      first = *got.Timestamps.FailedSince
+    } else if !got.Timestamps.FailedSince.Equal(first) {
+      t.Fatalf("attempt %d: failed since moved from %v to %v", attempt, first, *got.Timestamps.FailedSince)
+    }
+  }
+
+  if calls := parser.callsCount(productURL); calls != 3 {
+    t.Fatalf("parser calls = %d, want 3", calls)
+  }
+  if messages := repos.Messages.List(); len(messages) != 0 {
+    t.Fatalf("messages = %d, want none before failing ttl", len(messages))
+  }
+}
+
+func TestHandleTrackingKeepsTrashedTracking(t *testing.T) {
+  const productURL = "https://www.lamoda.ru/p/rtlacv500501/"
+
+  ctx := context.Background()
+
+  repos := memrepo.New()
+  parser := newFakeParser()
+  tracker := newTestTracker(repos.Trackings, repos.Messages, parser, Config{})
+
+  parser.setProduct(newTestProduct(productURL, 800))
+
+  if err := repos.Trackings.Insert(ctx, newTestTracking(productURL, 1000)); err != nil {
+    t.Fatalf("repos.Trackings.Insert: %v", err)
+  }
+
+  tracking, err := repos.Trackings.Get(ctx, testChatId, productURL)
+  if err != nil {
+    t.Fatalf("repos.Trackings.Get: %v", err)
+  }
+
+  // Пользователь перенес отслеживание в корзину, пока трекер его обрабатывал.
+  err = repos.Trackings.Delete(ctx, models.DeleteTrackingParams{
+    ChatId:    testChatId,
+    URL:       productURL,
+    DeletedAt: time.Now(),
+    PurgeAt:   time.Now().Add(time.Hour),
+  })
+  if err != nil {
+    t.Fatalf("repos.Trackings.Delete: %v", err)
+  }
+
+  _ = tracker.handleTracking(ctx, nil, tracking)
+
+  trashed, err := repos.Trackings.GetDeletedById(ctx, testChatId, tracking.Id())
+  if err != nil {
+    t.Fatalf("repos.Trackings.GetDeletedById: %v", err)
+  }
+  if price := trashed.ParsedProduct.Options[0].Price.Discount.IntValue; price != 1000 {
+    t.Fatalf("trashed tracking price = %d, want unchanged 1000", price)
+  }
+  if trashed.Timestamps.HandledAt != nil {
+    t.Fatalf("trashed tracking handled at = %v, want unchanged", trashed.Timestamps.HandledAt)
+  }
+}
@@ -17,11 +17,141 @@ const (
 	MongodbPassword configKey = "mongodb_password"
 	// Хост mongodb
 	MongodbHost configKey = "mongodb_host"
+	// Строка подключения к mongodb. Если указана, хост, порт и пользователь не используются
+	MongodbUri configKey = "mongodb_uri"
+	// База данных аутентификации пользователя mongodb
+	MongodbAuthSource configKey = "mongodb_auth_source"
+	// Имя набора реплик mongodb
+	MongodbReplicaSet configKey = "mongodb_replica_set"
+	// Подключение к mongodb по tls
+	MongodbTls configKey = "mongodb_tls"
+	// Таймаут подключения к mongodb
+	MongodbConnectTimeout configKey = "mongodb_connect_timeout"
+	// Таймаут выбора сервера mongodb
+	MongodbServerSelectionTimeout configKey = "mongodb_server_selection_timeout"
+	// База данных mongodb
+	MongodbDatabase configKey = "mongodb_database"
+	// Коллекция отслеживаний
+	MongodbCollectionTrackings configKey = "mongodb_collection_trackings"
+	// Коллекция сессий пользователей
+	MongodbCollectionSessions configKey = "mongodb_collection_sessions"
+	// Коллекция сообщений для отправки
+	MongodbCollectionMessages configKey = "mongodb_collection_messages"
+	// Коллекция обращений пользователей
+	MongodbCollectionIssues configKey = "mongodb_collection_issues"
+	// Коллекция запусков кронов
+	MongodbCollectionRuns configKey = "mongodb_collection_runs"
+	// Коллекция примененных миграций
+	MongodbCollectionMigrations configKey = "mongodb_collection_migrations"
+	// Коллекция блокировки миграций
+	MongodbCollectionMigrationsLock configKey = "mongodb_collection_migrations_lock"
+	// Коллекция состояний слайдеров отслеживаний
+	MongodbCollectionSliders configKey = "mongodb_collection_sliders"
+	// Коллекция ссылок на добавление отслеживания
+	MongodbCollectionDeepLinks configKey = "mongodb_collection_deep_links"
 )
 
 const (
 	// Токен telegram бота
 	TelegramToken configKey = "telegram_token"
+	// Максимальное количество отслеживаний в списке и результатах поиска
+	TelegramListLimit configKey = "telegram_list_limit"
+	// Время хранения состояния слайдера отслеживаний
+	TelegramSliderTtl configKey = "telegram_slider_ttl"
+	// Секрет подписи ссылок на добавление отслеживания
+	TelegramDeepLinkSecret configKey = "telegram_deep_link_secret"
+	// Время действия ссылки на добавление отслеживания
+	TelegramDeepLinkTtl configKey = "telegram_deep_link_ttl"
+	// Время, в течение которого удаление отслеживания можно отменить кнопкой
+	TelegramTrashUndoTtl configKey = "telegram_trash_undo_ttl"
+	// Время хранения удаленных отслеживаний в корзине
+	TelegramTrashTtl configKey = "telegram_trash_ttl"
+	// Количество одновременно загружаемых товаров при добавлении нескольких ссылок
+	TelegramBulkWorkers configKey = "telegram_bulk_workers"
+	// Максимальное количество ссылок, добавляемых одним сообщением
+	TelegramBulkLimit configKey = "telegram_bulk_limit"
+	// Получение обновлений через webhook вместо long polling
+	TelegramWebhookEnabled configKey = "telegram_webhook_enabled"
+	// Публичный адрес webhook, регистрируемый в telegram
+	TelegramWebhookUrl configKey = "telegram_webhook_url"
+	// Порт http сервера webhook
+	TelegramWebhookPort configKey = "telegram_webhook_port"
+	// Секретный токен, проверяемый в заголовке X-Telegram-Bot-Api-Secret-Token
+	TelegramWebhookSecretToken configKey = "telegram_webhook_secret_token"
+	// Максимальное количество одновременных запросов webhook от telegram
+	TelegramWebhookMaxConnections configKey = "telegram_webhook_max_connections"
+	// Время ожидания выполняющихся обработчиков при остановке
+	TelegramWebhookDrainTimeout configKey = "telegram_webhook_drain_timeout"
+	// Удалять webhook при остановке. Не включать при нескольких репликах
+	TelegramWebhookDeleteOnStop configKey = "telegram_webhook_delete_on_stop"
+	// Время восстановления одного обновления в лимите чата
+	TelegramRateLimitInterval configKey = "telegram_rate_limit_interval"
+	// Количество обновлений, которые чат может отправить подряд
+	TelegramRateLimitBurst configKey = "telegram_rate_limit_burst"
+	// Длительность обработки, начиная с которой обновление логируется как медленное
+	TelegramSlowUpdateThreshold configKey = "telegram_slow_update_threshold"
+)
+
+const (
+	// Порт http сервера проверок healthz, readyz и version всех приложений. 0 отключает сервер
+	ProbesPort configKey = "probes_port"
+)
+
+const (
+	// Количество воркеров крона трекера
+	WorkerTrackerCount configKey = "worker_tracker_count"
+	// Количество воркеров крона отправки
+	WorkerSenderCount configKey = "worker_sender_count"
+)
+
+const (
+	// Таймаут http запросов к магазинам
+	HttpClientTimeout configKey = "http_client_timeout"
+	// Максимальное количество перенаправлений при раскрытии короткой ссылки
+	HttpResolverMaxHops configKey = "http_resolver_max_hops"
+	// Таймаут раскрытия короткой ссылки
+	HttpResolverTimeout configKey = "http_resolver_timeout"
+)
+
+const (
+	// Магазин lamoda включен
+	ShopLamodaEnabled configKey = "shop_lamoda_enabled"
+	// Магазин kixbox включен
+	ShopKixboxEnabled configKey = "shop_kixbox_enabled"
+	// Магазин oktyabr включен
+	ShopOktyabrEnabled configKey = "shop_oktyabr_enabled"
+	// Магазин lime включен
+	ShopLimeEnabled configKey = "shop_lime_enabled"
+	// Магазин ridestep включен
+	ShopRidestepEnabled configKey = "shop_ridestep_enabled"
+	// Магазин traektoria включен
+	ShopTraektoriaEnabled configKey = "shop_traektoria_enabled"
+)
+
+const (
+	// Режим технического обслуживания. Бот отвечает уведомлением, кроны пропускают запуски
+	MaintenanceMode configKey = "maintenance_mode"
+)
+
+const (
+	// Остаток товара, при снижении до которого отправляется уведомление о распродаже
+	TrackerSellUpThreshold configKey = "tracker_sell_up_threshold"
+	// Идентификатор экземпляра трекера для аренды отслеживаний. По умолчанию hostname/pid/случайный суффикс
+	TrackerInstanceId configKey = "tracker_instance_id"
+	// Время аренды пачки отслеживаний экземпляром трекера
+	TrackerLeaseTtl configKey = "tracker_lease_ttl"
+	// Размер пачки отслеживаний, захватываемой экземпляром трекера
+	TrackerBatchSize configKey = "tracker_batch_size"
+	// Минимальный интервал между обработками одного отслеживания. Общий для всех экземпляров трекера
+	TrackerHandleInterval configKey = "tracker_handle_interval"
+	// Время хранения распарсенного товара в кэше бота
+	TrackerProductsCacheTtl configKey = "tracker_products_cache_ttl"
+	// Максимальное количество товаров в кэше бота
+	TrackerProductsCacheCapacity configKey = "tracker_products_cache_capacity"
+	// Время ошибок парсинга подряд, после которого отслеживание архивируется
+	TrackerArchiveFailingTtl configKey = "tracker_archive_failing_ttl"
+	// Время без изменений товара, после которого отслеживание архивируется
+	TrackerArchiveStaleTtl configKey = "tracker_archive_stale_ttl"
 )
 
 // configKey strict type for config key
@@ -0,0 +1,104 @@
+package config
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/models"
+  "github.com/ushakovn/outfit/pkg/worker"
+)
+
+const maxWorkerCount = 255
+
+// Runtime настройки, изменяемые без перезапуска приложений.
+// Значения читаются при каждом обращении из провайдеров, отслеживающих изменения.
+type Runtime struct {
+  maintenanceMode *Provider
+  sellUpThreshold *Provider
+  trackerWorkers  *Provider
+  senderWorkers   *Provider
+  shopsEnabled    map[models.ProductType]*Provider
+}
+
+var shopEnabledKeys = map[models.ProductType]configKey{
+  models.ProductTypeLamoda:     ShopLamodaEnabled,
+  models.ProductTypeKixbox:     ShopKixboxEnabled,
+  models.ProductTypeOktyabr:    ShopOktyabrEnabled,
+  models.ProductTypeLime:       ShopLimeEnabled,
+  models.ProductTypeRidestep:   ShopRidestepEnabled,
+  models.ProductTypeTraektoria: ShopTraektoriaEnabled,
+}
+
+func NewRuntime(ctx context.Context) (*Runtime, error) {
+  r := &Runtime{
+    maintenanceMode: NewProvider(ctx, MaintenanceMode).Watch(ctx),
+    sellUpThreshold: NewProvider(ctx, TrackerSellUpThreshold).Watch(ctx),
+    trackerWorkers:  NewProvider(ctx, WorkerTrackerCount).Watch(ctx),
+    senderWorkers:   NewProvider(ctx, WorkerSenderCount).Watch(ctx),
+    shopsEnabled:    make(map[models.ProductType]*Provider, len(shopEnabledKeys)),
+  }
+
+  for typ, key := range shopEnabledKeys {
+    r.shopsEnabled[typ] = NewProvider(ctx, key).Watch(ctx)
+  }
+
+  if err := r.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid runtime settings: %w", err)
+  }
+
+  return r, nil
+}
+
+// Validate проверяет текущие значения. Некорректные значения, полученные
+// после запуска, не приводят к ошибке: вместо них используются значения по умолчанию.
+func (r *Runtime) Validate() error {
+  if value := r.trackerWorkers.Provide().Int(); !isValidWorkerCount(value) {
+    return fmt.Errorf("%s: must be in range [1, %d]: got %d", WorkerTrackerCount, maxWorkerCount, value)
+  }
+  if value := r.senderWorkers.Provide().Int(); !isValidWorkerCount(value) {
+    return fmt.Errorf("%s: must be in range [1, %d]: got %d", WorkerSenderCount, maxWorkerCount, value)
+  }
+  if value := r.sellUpThreshold.Provide().Int64(); value < 0 {
+    return fmt.Errorf("%s: must not be negative: got %d", TrackerSellUpThreshold, value)
+  }
+  return nil
+}
+
+func (r *Runtime) MaintenanceMode() bool {
+  return r.maintenanceMode.Provide().Bool()
+}
+
+func (r *Runtime) SellUpThreshold() int64 {
+  if value := r.sellUpThreshold.Provide().Int64(); value >= 0 {
+    return value
+  }
+  return models.DefaultSellUpThreshold
+}
+
+func (r *Runtime) TrackerWorkers() int {
+  return workerCount(r.trackerWorkers.Provide().Int())
+}
+
+func (r *Runtime) SenderWorkers() int {
+  return workerCount(r.senderWorkers.Provide().Int())
+}
+
+// IsShopEnabled сообщает, включен ли магазин. Неизвестные магазины считаются выключенными.
+func (r *Runtime) IsShopEnabled(typ models.ProductType) bool {
+  provider, ok := r.shopsEnabled[typ]
+  if !ok {
+    return false
+  }
+  return provider.Provide().Bool()
+}
+
+func isValidWorkerCount(value int) bool {
+  return value > 0 && value <= maxWorkerCount
+}
+
+func workerCount(value int) int {
+  if isValidWorkerCount(value) {
+    return value
+  }
+  return worker.DefaultCount
+}
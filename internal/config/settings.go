@@ -0,0 +1,221 @@
+package config
+
+import (
+  "context"
+  "fmt"
+  "os"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+)
+
+// DeepLinkSecretEnv переменная окружения с секретом подписи ссылок. Имеет приоритет над значением из конфига.
+const DeepLinkSecretEnv = "OUTFIT_TELEGRAM_DEEP_LINK_SECRET"
+
+// Settings типизированная конфигурация приложений, читаемая при запуске.
+// Настройки, изменяемые без перезапуска, находятся в Runtime.
+type Settings struct {
+  Mongodb  MongodbSettings
+  Telegram TelegramSettings
+  Probes   ProbesSettings
+  HTTP     HTTPSettings
+  Tracker  TrackerSettings
+}
+
+type MongodbSettings struct {
+  URI                    string
+  Host                   string `validate:"required_without=URI"`
+  Port                   string `validate:"required_without=URI"`
+  User                   string
+  Password               string `validate:"required_with=User"`
+  AuthSource             string
+  ReplicaSet             string
+  TLS                    bool
+  ConnectTimeout         time.Duration `validate:"gt=0"`
+  ServerSelectionTimeout time.Duration `validate:"gt=0"`
+  Database               string        `validate:"required"`
+  Collections            MongodbCollections
+}
+
+type MongodbCollections struct {
+  Trackings      string `validate:"required"`
+  Sessions       string `validate:"required"`
+  Messages       string `validate:"required"`
+  Issues         string `validate:"required"`
+  Runs           string `validate:"required"`
+  Migrations     string `validate:"required"`
+  MigrationsLock string `validate:"required"`
+  Sliders        string `validate:"required"`
+  DeepLinks      string `validate:"required"`
+}
+
+type TelegramSettings struct {
+  Token               string        `validate:"required"`
+  ListLimit           int64         `validate:"gt=0"`
+  SliderTTL           time.Duration `validate:"gt=0"`
+  DeepLink            TelegramDeepLinkSettings
+  Trash               TelegramTrashSettings
+  Bulk                TelegramBulkSettings
+  Webhook             TelegramWebhookSettings
+  RateLimit           TelegramRateLimitSettings
+  SlowUpdateThreshold time.Duration `validate:"gt=0"`
+}
+
+type TelegramDeepLinkSettings struct {
+  // Secret обязателен только для бота и проверяется при его запуске.
+  Secret string        `validate:"omitempty,min=32"`
+  TTL    time.Duration `validate:"gt=0"`
+}
+
+type TelegramTrashSettings struct {
+  UndoTTL time.Duration `validate:"gt=0"`
+  TTL     time.Duration `validate:"gt=0"`
+}
+
+type TelegramBulkSettings struct {
+  Workers int `validate:"gt=0"`
+  Limit   int `validate:"gt=0"`
+}
+
+type TelegramRateLimitSettings struct {
+  Interval time.Duration `validate:"gt=0"`
+  Burst    int           `validate:"gt=0"`
+}
+
+type TelegramWebhookSettings struct {
+  Enabled        bool
+  URL            string `validate:"required_if=Enabled true,omitempty,url"`
+  Port           int    `validate:"required_if=Enabled true"`
+  SecretToken    string `validate:"required_if=Enabled true,max=256"`
+  MaxConnections int    `validate:"gte=0,lte=100"`
+  DrainTimeout   time.Duration
+  DeleteOnStop   bool
+}
+
+type ProbesSettings struct {
+  Port int `validate:"gte=0"`
+}
+
+type TrackerSettings struct {
+  InstanceId string
+  LeaseTTL   time.Duration `validate:"gt=0"`
+  BatchSize  int           `validate:"gt=0"`
+  // HandleInterval минимальный интервал между обработками одного отслеживания.
+  HandleInterval time.Duration `validate:"gt=0"`
+  // ProductsCacheTTL время хранения распарсенного товара в кэше бота.
+  ProductsCacheTTL      time.Duration `validate:"gt=0"`
+  ProductsCacheCapacity int           `validate:"gt=0"`
+  // ArchiveFailingTTL время ошибок парсинга подряд, после которого отслеживание архивируется.
+  ArchiveFailingTTL time.Duration `validate:"gt=0"`
+  // ArchiveStaleTTL время без изменений товара, после которого отслеживание архивируется.
+  ArchiveStaleTTL time.Duration `validate:"gt=0"`
+}
+
+type HTTPSettings struct {
+  ClientTimeout time.Duration `validate:"gt=0"`
+  Resolver      HTTPResolverSettings
+}
+
+type HTTPResolverSettings struct {
+  MaxHops int           `validate:"gt=0"`
+  Timeout time.Duration `validate:"gt=0"`
+}
+
+func (s *Settings) Validate() error {
+  return validator.New().Struct(s)
+}
+
+// LoadSettings читает и валидирует конфигурацию.
+func LoadSettings(ctx context.Context) (*Settings, error) {
+  settings := &Settings{
+    Mongodb: MongodbSettings{
+      URI:                    Get(ctx, MongodbUri).String(),
+      Host:                   Get(ctx, MongodbHost).String(),
+      Port:                   Get(ctx, MongodbPort).String(),
+      User:                   Get(ctx, MongodbUser).String(),
+      Password:               Get(ctx, MongodbPassword).String(),
+      AuthSource:             Get(ctx, MongodbAuthSource).String(),
+      ReplicaSet:             Get(ctx, MongodbReplicaSet).String(),
+      TLS:                    Get(ctx, MongodbTls).Bool(),
+      ConnectTimeout:         Get(ctx, MongodbConnectTimeout).Duration(),
+      ServerSelectionTimeout: Get(ctx, MongodbServerSelectionTimeout).Duration(),
+      Database:               Get(ctx, MongodbDatabase).String(),
+      Collections: MongodbCollections{
+        Trackings:      Get(ctx, MongodbCollectionTrackings).String(),
+        Sessions:       Get(ctx, MongodbCollectionSessions).String(),
+        Messages:       Get(ctx, MongodbCollectionMessages).String(),
+        Issues:         Get(ctx, MongodbCollectionIssues).String(),
+        Runs:           Get(ctx, MongodbCollectionRuns).String(),
+        Migrations:     Get(ctx, MongodbCollectionMigrations).String(),
+        MigrationsLock: Get(ctx, MongodbCollectionMigrationsLock).String(),
+        Sliders:        Get(ctx, MongodbCollectionSliders).String(),
+        DeepLinks:      Get(ctx, MongodbCollectionDeepLinks).String(),
+      },
+    },
+    Telegram: TelegramSettings{
+      Token:     Get(ctx, TelegramToken).String(),
+      ListLimit: Get(ctx, TelegramListLimit).Int64(),
+      SliderTTL: Get(ctx, TelegramSliderTtl).Duration(),
+      DeepLink: TelegramDeepLinkSettings{
+        Secret: getSecret(ctx, TelegramDeepLinkSecret, DeepLinkSecretEnv),
+        TTL:    Get(ctx, TelegramDeepLinkTtl).Duration(),
+      },
+      Trash: TelegramTrashSettings{
+        UndoTTL: Get(ctx, TelegramTrashUndoTtl).Duration(),
+        TTL:     Get(ctx, TelegramTrashTtl).Duration(),
+      },
+      Bulk: TelegramBulkSettings{
+        Workers: Get(ctx, TelegramBulkWorkers).Int(),
+        Limit:   Get(ctx, TelegramBulkLimit).Int(),
+      },
+      Webhook: TelegramWebhookSettings{
+        Enabled:        Get(ctx, TelegramWebhookEnabled).Bool(),
+        URL:            Get(ctx, TelegramWebhookUrl).String(),
+        Port:           Get(ctx, TelegramWebhookPort).Int(),
+        SecretToken:    Get(ctx, TelegramWebhookSecretToken).String(),
+        MaxConnections: Get(ctx, TelegramWebhookMaxConnections).Int(),
+        DrainTimeout:   Get(ctx, TelegramWebhookDrainTimeout).Duration(),
+        DeleteOnStop:   Get(ctx, TelegramWebhookDeleteOnStop).Bool(),
+      },
+      RateLimit: TelegramRateLimitSettings{
+        Interval: Get(ctx, TelegramRateLimitInterval).Duration(),
+        Burst:    Get(ctx, TelegramRateLimitBurst).Int(),
+      },
+      SlowUpdateThreshold: Get(ctx, TelegramSlowUpdateThreshold).Duration(),
+    },
+    Probes: ProbesSettings{
+      Port: Get(ctx, ProbesPort).Int(),
+    },
+    HTTP: HTTPSettings{
+      ClientTimeout: Get(ctx, HttpClientTimeout).Duration(),
+      Resolver: HTTPResolverSettings{
+        MaxHops: Get(ctx, HttpResolverMaxHops).Int(),
+        Timeout: Get(ctx, HttpResolverTimeout).Duration(),
+      },
+    },
+    Tracker: TrackerSettings{
+      InstanceId:            Get(ctx, TrackerInstanceId).String(),
+      LeaseTTL:              Get(ctx, TrackerLeaseTtl).Duration(),
+      BatchSize:             Get(ctx, TrackerBatchSize).Int(),
+      HandleInterval:        Get(ctx, TrackerHandleInterval).Duration(),
+      ProductsCacheTTL:      Get(ctx, TrackerProductsCacheTtl).Duration(),
+      ProductsCacheCapacity: Get(ctx, TrackerProductsCacheCapacity).Int(),
+      ArchiveFailingTTL:     Get(ctx, TrackerArchiveFailingTtl).Duration(),
+      ArchiveStaleTTL:       Get(ctx, TrackerArchiveStaleTtl).Duration(),
+    },
+  }
+
+  if err := settings.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid settings: %w", err)
+  }
+
+  return settings, nil
+}
+
+// getSecret возвращает секрет из переменной окружения или, если она не задана, из конфига.
+func getSecret(ctx context.Context, key configKey, env string) string {
+  if value, ok := os.LookupEnv(env); ok {
+    return value
+  }
+  return Get(ctx, key).String()
+}
@@ -0,0 +1,37 @@
+package parsers
+
+import (
+  "errors"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/parsers/kixbox"
+  "github.com/ushakovn/outfit/internal/deps/parsers/lamoda"
+  "github.com/ushakovn/outfit/internal/deps/parsers/lime"
+  "github.com/ushakovn/outfit/internal/deps/parsers/oktyabr"
+  "github.com/ushakovn/outfit/internal/deps/parsers/ridestep"
+  "github.com/ushakovn/outfit/internal/deps/parsers/traektoria"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+var ErrUnknownProductType = errors.New("unknown product type")
+
+// ProductKey возвращает ключ товара по ссылке без создания парсеров.
+// Используется там, где парсеры не нужны, например в миграциях.
+func ProductKey(url string) (models.ProductKey, error) {
+  switch productType := models.FindProductType(url); productType {
+  case models.ProductTypeLamoda:
+    return lamoda.ProductKey(url)
+  case models.ProductTypeKixbox:
+    return kixbox.ProductKey(url)
+  case models.ProductTypeOktyabr:
+    return oktyabr.ProductKey(url)
+  case models.ProductTypeLime:
+    return lime.ProductKey(url)
+  case models.ProductTypeRidestep:
+    return ridestep.ProductKey(url)
+  case models.ProductTypeTraektoria:
+    return traektoria.ProductKey(url)
+  default:
+    return "", fmt.Errorf("%w: %s. url: %s", ErrUnknownProductType, productType, url)
+  }
+}
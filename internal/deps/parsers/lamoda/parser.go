@@ -3,6 +3,7 @@ package lamoda
 import (
   "context"
   "encoding/json"
+  "errors"
   "fmt"
   neturl "net/url"
   "regexp"
@@ -37,6 +38,34 @@ func NewParser(deps Dependencies) *Parser {
   return &Parser{deps: deps}
 }
 
+func (p *Parser) ProductKey(url string) (models.ProductKey, error) {
+  return ProductKey(url)
+}
+
+// ProductKey возвращает ключ товара по артикулу lamoda. Артикул различается для разных цветов товара.
+func ProductKey(url string) (models.ProductKey, error) {
+  // Пример: https://www.lamoda.ru/p/rtlacv500501/clothes-carharttwip-dzhinsy/.
+  // Артикул товара: rtlacv500501.
+
+  if err := validateURL(url); err != nil {
+    return "", fmt.Errorf("invalid url: %s. error: %w", url, err)
+  }
+
+  parsed, err := neturl.Parse(url)
+  if err != nil {
+    return "", fmt.Errorf("neturl.Parse: %w", err)
+  }
+
+  _, slug, _ := strings.Cut(parsed.Path, "/p/")
+  sku, _, _ := strings.Cut(slug, "/")
+
+  if sku = strings.TrimSpace(sku); sku == "" {
+    return "", fmt.Errorf("product sku not found in url: %s", url)
+  }
+
+  return models.NewProductKey(models.ProductTypeLamoda, sku), nil
+}
+
 func (p *Parser) findProductJSON(ctx context.Context, url string) (*ParsedProduct, error) {
   content, err := p.findProductNodeContent(ctx, url)
   if err != nil {
@@ -59,6 +88,9 @@ func (p *Parser) findProductJSON(ctx context.Context, url string) (*ParsedProduc
 
 func (p *Parser) findProductNodeContent(ctx context.Context, url string) (string, error) {
   doc, err := p.deps.Xpath.GetHtmlDoc(ctx, url)
+  if errors.Is(err, xpath.ErrPageNotFound) {
+    return "", fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", models.ErrProductNotFound)
+  }
   if err != nil {
     return "", fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", err)
   }
@@ -4,6 +4,7 @@ import (
   "context"
   "encoding/json"
   "fmt"
+  "net/http"
   neturl "net/url"
   "regexp"
   "strings"
@@ -33,6 +34,39 @@ func NewParser(deps Dependencies) *Parser {
   return &Parser{deps: deps}
 }
 
+func (p *Parser) ProductKey(url string) (models.ProductKey, error) {
+  return ProductKey(url)
+}
+
+// ProductKey возвращает ключ товара по коду и цвету: цвета одного товара отслеживаются отдельно.
+func ProductKey(url string) (models.ProductKey, error) {
+  if err := validateURL(url); err != nil {
+    return "", fmt.Errorf("invalid url: %s. error: %w", url, err)
+  }
+
+  parsed, err := neturl.Parse(url)
+  if err != nil {
+    return "", fmt.Errorf("neturl.Parse: %w", err)
+  }
+  path := strings.TrimRight(parsed.Path, "/")
+
+  code, err := findProductCode(path)
+  if err != nil {
+    return "", fmt.Errorf("findProductCode: %w", err)
+  }
+
+  color, err := findProductColor(path)
+  if err != nil {
+    return "", fmt.Errorf("findProductColor: %w", err)
+  }
+
+  if code == "" || color == "" {
+    return "", fmt.Errorf("product code or color not found in url: %s", url)
+  }
+
+  return models.NewProductKey(models.ProductTypeLime, code, color), nil
+}
+
 func findProductCode(url string) (code string, err error) {
   // Пример: https://lime-shop.com/ru_ru/product/21261_0428_887-temno_seryi_melanz.
   // Код товара: 21261_0428_887.
@@ -111,6 +145,10 @@ func (p *Parser) findProductJSON(ctx context.Context, url string) (*ParsedProduc
     return nil, fmt.Errorf("resty.Client.Get: %w", err)
   }
 
+  if resp.StatusCode() == http.StatusNotFound {
+    return nil, fmt.Errorf("resty.Client.Get: %w", models.ErrProductNotFound)
+  }
+
   body := resp.Body()
   parsed := new(ParsedPage)
 
@@ -3,6 +3,7 @@ package oktyabr
 import (
   "context"
   "encoding/json"
+  "errors"
   "fmt"
   "regexp"
   "sort"
@@ -39,6 +40,24 @@ func NewParser(deps Dependencies) *Parser {
   return &Parser{deps: deps}
 }
 
+func (p *Parser) ProductKey(url string) (models.ProductKey, error) {
+  return ProductKey(url)
+}
+
+// ProductKey возвращает ключ товара по пути ссылки: код товара в ссылке магазина не выделяется.
+func ProductKey(url string) (models.ProductKey, error) {
+  if err := validateURL(url); err != nil {
+    return "", fmt.Errorf("invalid url: %s. error: %w", url, err)
+  }
+
+  key, err := models.NewProductPathKey(url)
+  if err != nil {
+    return "", fmt.Errorf("models.NewProductPathKey: %w", err)
+  }
+
+  return key, nil
+}
+
 func validateURL(url string) error {
   if err := validator.URL(url); err != nil {
     return fmt.Errorf("url invalid: %w", err)
@@ -212,6 +231,9 @@ func findProductJSON(doc *xpath.HtmlDocument) (*ParsedProduct, error) {
 
 func (p *Parser) fetchXpathDoc(ctx context.Context, url string) (*xpath.HtmlDocument, error) {
   doc, err := p.deps.Xpath.GetHtmlDoc(ctx, url)
+  if errors.Is(err, xpath.ErrPageNotFound) {
+    return nil, fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", models.ErrProductNotFound)
+  }
   if err != nil {
     return nil, fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", err)
   }
@@ -41,6 +41,24 @@ func NewParser(deps Dependencies) *Parser {
   return &Parser{deps: deps}
 }
 
+func (p *Parser) ProductKey(url string) (models.ProductKey, error) {
+  return ProductKey(url)
+}
+
+// ProductKey возвращает ключ товара по пути ссылки: код товара в ссылке магазина не выделяется.
+func ProductKey(url string) (models.ProductKey, error) {
+  if err := validateURL(url); err != nil {
+    return "", fmt.Errorf("invalid url: %s. error: %w", url, err)
+  }
+
+  key, err := models.NewProductPathKey(url)
+  if err != nil {
+    return "", fmt.Errorf("models.NewProductPathKey: %w", err)
+  }
+
+  return key, nil
+}
+
 func (p *Parser) findProduct(ctx context.Context, url string) (*ParsedProduct, error) {
   doc, err := p.fetchXpathDoc(ctx, url)
   if err != nil {
@@ -179,6 +197,9 @@ func sanitizeNodeContent(content string) string {
 
 func (p *Parser) fetchXpathDoc(ctx context.Context, url string) (*xpath.HtmlDocument, error) {
   doc, err := p.deps.Xpath.GetHtmlDoc(ctx, url)
+  if errors.Is(err, xpath.ErrPageNotFound) {
+    return nil, fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", models.ErrProductNotFound)
+  }
   if err != nil {
     return nil, fmt.Errorf("p.deps.Xpath.GetHtmlDoc: %w", err)
   }
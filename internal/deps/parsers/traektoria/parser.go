@@ -4,6 +4,7 @@ import (
   "context"
   "encoding/json"
   "fmt"
+  "net/http"
   neturl "net/url"
   "regexp"
   "strings"
@@ -42,6 +43,35 @@ func NewParser(deps Dependencies) *Parser {
   return &Parser{deps: deps}
 }
 
+func (p *Parser) ProductKey(url string) (models.ProductKey, error) {
+  return ProductKey(url)
+}
+
+// ProductKey возвращает ключ товара по коду и sku из ссылки. Sku определяет цвет товара.
+func ProductKey(url string) (models.ProductKey, error) {
+  if err := validateURL(url); err != nil {
+    return "", fmt.Errorf("invalid url: %s. error: %w", url, err)
+  }
+
+  parsed, err := neturl.Parse(url)
+  if err != nil {
+    return "", fmt.Errorf("neturl.Parse: %w", err)
+  }
+
+  code, err := findProductCode(parsed.Path)
+  if err != nil {
+    return "", fmt.Errorf("findProductCode: %w", err)
+  }
+  if code == "" {
+    return "", fmt.Errorf("product code not found in url: %s", url)
+  }
+
+  if sku := findProductSkuCode(url); sku != "" {
+    return models.NewProductKey(models.ProductTypeTraektoria, code, sku), nil
+  }
+  return models.NewProductKey(models.ProductTypeTraektoria, code), nil
+}
+
 func findProductCode(url string) (code string, err error) {
   // Пример: https://www.traektoria.ru/product/1639029_bryuki-carhartt-wip-cole-cargo-pant/?SKU=1645314.
   // Код товара: 1639029.
@@ -140,6 +170,10 @@ func (p *Parser) findProductJSON(ctx context.Context, url string) (*ParsedProduc
     return nil, fmt.Errorf("resty.Client.Get: %w", err)
   }
 
+  if resp.StatusCode() == http.StatusNotFound {
+    return nil, fmt.Errorf("resty.Client.Get: %w", models.ErrProductNotFound)
+  }
+
   body := resp.Body()
   parsed := new(ParsedPage)
 
@@ -0,0 +1,255 @@
+package resolver
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net"
+  "net/http"
+  neturl "net/url"
+  "strings"
+  "syscall"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+var (
+  ErrTooManyRedirects = errors.New("too many redirects")
+  ErrForbiddenAddress = errors.New("forbidden address")
+)
+
+// appSchemes хосты сайтов магазинов для ссылок, которыми делятся мобильные приложения.
+// Пример: lamoda://p/rtlacv500501 открывается как https://www.lamoda.ru/p/rtlacv500501.
+var appSchemes = map[string]string{
+  "lamoda":     "www.lamoda.ru",
+  "lime":       "lime-shop.com",
+  "limeshop":   "lime-shop.com",
+  "kixbox":     "kixbox.ru",
+  "ridestep":   "ridestep.ru",
+  "traektoria": "www.traektoria.ru",
+}
+
+// mobileHostPrefixes префиксы мобильных версий сайтов. Парсеры магазинов принимают только полные версии.
+var mobileHostPrefixes = []string{"m.", "mobile."}
+
+// trackingParams параметры запроса рекламных систем, которые не влияют на товар.
+var trackingParams = map[string]struct{}{
+  "gclid":     {},
+  "fbclid":    {},
+  "yclid":     {},
+  "ysclid":    {},
+  "_openstat": {},
+  "erid":      {},
+  "clid":      {},
+}
+
+const trackingParamPrefix = "utm_"
+
+type Config struct {
+  // MaxHops максимальное количество переходов по перенаправлениям короткой ссылки.
+  MaxHops int `validate:"gt=0"`
+  // Timeout таймаут раскрытия ссылки вместе со всеми перенаправлениями.
+  Timeout time.Duration `validate:"gt=0"`
+}
+
+func (c *Config) Validate() error {
+  return validator.New().Struct(c)
+}
+
+// Resolver приводит ссылки из приложений, мобильных версий и сокращателей к ссылкам на сайты магазинов.
+type Resolver struct {
+  config Config
+  client *http.Client
+}
+
+func NewResolver(config Config) (*Resolver, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  // Ссылки присылают пользователи, поэтому запросы во внутреннюю сеть запрещены.
+  dialer := &net.Dialer{
+    Timeout: config.Timeout,
+    Control: denyPrivateAddress,
+  }
+
+  transport := http.DefaultTransport.(*http.Transport).Clone()
+  transport.Proxy = nil
+  transport.DialContext = dialer.DialContext
+
+  return &Resolver{
+    config: config,
+    client: &http.Client{
+      Transport: transport,
+      // Перенаправления проходятся вручную, чтобы остановиться на первой ссылке магазина.
+      CheckRedirect: func(*http.Request, []*http.Request) error {
+        return http.ErrUseLastResponse
+      },
+    },
+  }, nil
+}
+
+// Resolve возвращает ссылку на товар на сайте магазина без рекламных параметров.
+// Перенаправления проходятся только для ссылок, магазин которых не определен по хосту.
+func (r *Resolver) Resolve(ctx context.Context, url string) (string, error) {
+  parsed, err := neturl.Parse(strings.TrimSpace(url))
+  if err != nil {
+    return "", fmt.Errorf("neturl.Parse: %w", err)
+  }
+  parsed = mapAppURL(parsed)
+
+  if parsed.Scheme != "http" && parsed.Scheme != "https" {
+    return url, nil
+  }
+
+  ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+  defer cancel()
+
+  for hop := 0; ; hop++ {
+    parsed = mapMobileURL(parsed)
+
+    if models.FindProductType(parsed.String()) != models.ProductTypeUnknown {
+      break
+    }
+    if hop == r.config.MaxHops {
+      return "", fmt.Errorf("%w: url: %s, max hops: %d", ErrTooManyRedirects, url, r.config.MaxHops)
+    }
+
+    next, err := r.follow(ctx, parsed)
+    if err != nil {
+      return "", fmt.Errorf("r.follow: %w", err)
+    }
+    if next == nil {
+      break
+    }
+
+    log.
+      WithField("url", parsed.String()).
+      WithField("location", next.String()).
+      Debug("url redirect followed")
+
+    parsed = mapAppURL(next)
+  }
+
+  return stripTrackingParams(parsed).String(), nil
+}
+
+// follow возвращает адрес перенаправления или nil, если ссылка никуда не перенаправляет.
+func (r *Resolver) follow(ctx context.Context, url *neturl.URL) (*neturl.URL, error) {
+  req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+  if err != nil {
+    return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+  }
+
+  resp, err := r.client.Do(req)
+  if err != nil {
+    return nil, fmt.Errorf("r.client.Do: %w", err)
+  }
+  defer resp.Body.Close()
+
+  if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+    return nil, nil
+  }
+
+  location, err := resp.Location()
+  if err != nil {
+    if errors.Is(err, http.ErrNoLocation) {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("resp.Location: %w", err)
+  }
+
+  return location, nil
+}
+
+// mapAppURL приводит ссылку приложения к ссылке на сайт.
+// Поддерживаются схемы приложений магазинов и android intent ссылки.
+func mapAppURL(url *neturl.URL) *neturl.URL {
+  if url.Scheme == "intent" {
+    // Пример: intent://www.lamoda.ru/p/rtlacv500501#Intent;scheme=https;package=com.lamoda.lite;end.
+    mapped := *url
+    mapped.Scheme = "https"
+    mapped.Fragment, mapped.RawFragment = "", ""
+
+    for _, part := range strings.Split(url.Fragment, ";") {
+      if scheme, ok := strings.CutPrefix(part, "scheme="); ok && (scheme == "http" || scheme == "https") {
+        mapped.Scheme = scheme
+      }
+    }
+    return &mapped
+  }
+
+  host, ok := appSchemes[url.Scheme]
+  if !ok {
+    return url
+  }
+
+  mapped := *url
+  mapped.Scheme = "https"
+  mapped.Host = host
+  mapped.Opaque = ""
+
+  // В ссылках приложений первая часть пути часто записывается на месте хоста: lamoda://p/<код>.
+  if url.Host != "" && !strings.Contains(url.Host, ".") {
+    mapped.Path = "/" + url.Host + url.Path
+    mapped.RawPath = ""
+  }
+
+  return &mapped
+}
+
+func mapMobileURL(url *neturl.URL) *neturl.URL {
+  for _, prefix := range mobileHostPrefixes {
+    if host, ok := strings.CutPrefix(url.Host, prefix); ok {
+      mapped := *url
+      mapped.Host = host
+
+      return &mapped
+    }
+  }
+  return url
+}
+
+func stripTrackingParams(url *neturl.URL) *neturl.URL {
+  mapped := *url
+  mapped.Fragment, mapped.RawFragment = "", ""
+
+  query := url.Query()
+  stripped := false
+
+  for key := range query {
+    if _, ok := trackingParams[strings.ToLower(key)]; ok || strings.HasPrefix(strings.ToLower(key), trackingParamPrefix) {
+      query.Del(key)
+      stripped = true
+    }
+  }
+
+  // Порядок параметров сохраняется, если удалять нечего.
+  if stripped {
+    mapped.RawQuery = query.Encode()
+  }
+
+  return &mapped
+}
+
+func denyPrivateAddress(_ string, address string, _ syscall.RawConn) error {
+  host, _, err := net.SplitHostPort(address)
+  if err != nil {
+    return fmt.Errorf("net.SplitHostPort: %w", err)
+  }
+
+  ip := net.ParseIP(host)
+  if ip == nil {
+    return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+  }
+
+  if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+    ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+    return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+  }
+
+  return nil
+}
@@ -0,0 +1,53 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sync"
+  "time"
+
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type DeepLinks struct {
+  mu     sync.RWMutex
+  values map[models.DeepLinkId]models.DeepLink
+}
+
+func NewDeepLinks() *DeepLinks {
+  return &DeepLinks{
+    values: make(map[models.DeepLinkId]models.DeepLink),
+  }
+}
+
+func (r *DeepLinks) Upsert(_ context.Context, link models.DeepLink) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  r.values[link.Id] = link
+
+  return nil
+}
+
+func (r *DeepLinks) UpsertMany(_ context.Context, links []models.DeepLink) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  for _, link := range links {
+    r.values[link.Id] = link
+  }
+
+  return nil
+}
+
+func (r *DeepLinks) Get(_ context.Context, id models.DeepLinkId) (*models.DeepLink, error) {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  link, ok := r.values[id]
+  if !ok || !link.ExpiresAt.After(time.Now()) {
+    return nil, fmt.Errorf("deep link with id: %s: %w", id, models.ErrNotFound)
+  }
+
+  return &link, nil
+}
@@ -0,0 +1,34 @@
+package memrepo
+
+import (
+  "context"
+  "sync"
+
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Issues struct {
+  mu     sync.RWMutex
+  values []models.Issue
+}
+
+func NewIssues() *Issues {
+  return &Issues{}
+}
+
+func (r *Issues) Insert(_ context.Context, issue models.Issue) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  r.values = append(r.values, issue)
+
+  return nil
+}
+
+// List возвращает копию всех сохраненных обращений.
+func (r *Issues) List() []models.Issue {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  return append([]models.Issue(nil), r.values...)
+}
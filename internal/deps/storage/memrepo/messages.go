@@ -0,0 +1,83 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sync"
+
+  "github.com/samber/lo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Messages struct {
+  mu     sync.RWMutex
+  values []models.SendableMessage
+}
+
+func NewMessages() *Messages {
+  return &Messages{}
+}
+
+func (r *Messages) InsertIfNotExist(_ context.Context, message models.SendableMessage) (bool, error) {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  for _, stored := range r.values {
+    if stored.ChatId == message.ChatId && stored.Text.SHA256 == message.Text.SHA256 {
+      return false, nil
+    }
+  }
+
+  r.values = append(r.values, message)
+
+  return true, nil
+}
+
+func (r *Messages) ScanUnsent(ctx context.Context, filter models.MessagesFilter, callback func(ctx context.Context, message *models.SendableMessage) error) error {
+  r.mu.RLock()
+
+  list := make([]*models.SendableMessage, 0, len(r.values))
+
+  for _, message := range r.values {
+    if !lo.Contains(models.CronSendableTypes, message.Type) || message.SentId != nil {
+      continue
+    }
+    if filter.ProductType != "" && message.Product.Type != filter.ProductType {
+      continue
+    }
+    message := message
+    list = append(list, &message)
+  }
+
+  r.mu.RUnlock()
+
+  for _, message := range list {
+    if err := callback(ctx, message); err != nil {
+      return fmt.Errorf("callback: %w", err)
+    }
+  }
+
+  return nil
+}
+
+func (r *Messages) Update(_ context.Context, message *models.SendableMessage) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  for index, stored := range r.values {
+    if stored.UUID == message.UUID {
+      r.values[index] = *message
+      return nil
+    }
+  }
+
+  return fmt.Errorf("message with uuid: %s: %w", message.UUID, models.ErrNotFound)
+}
+
+// List возвращает копию всех сохраненных сообщений.
+func (r *Messages) List() []models.SendableMessage {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  return append([]models.SendableMessage(nil), r.values...)
+}
@@ -0,0 +1,37 @@
+package memrepo
+
+import "github.com/ushakovn/outfit/internal/models"
+
+// Repositories потокобезопасные in-memory реализации репозиториев.
+// Используются в unit тестах и при локальной разработке без mongodb.
+type Repositories struct {
+  Trackings *Trackings
+  Sessions  *Sessions
+  Messages  *Messages
+  Issues    *Issues
+  Runs      *Runs
+  Sliders   *Sliders
+  DeepLinks *DeepLinks
+}
+
+func New() *Repositories {
+  return &Repositories{
+    Trackings: NewTrackings(),
+    Sessions:  NewSessions(),
+    Messages:  NewMessages(),
+    Issues:    NewIssues(),
+    Runs:      NewRuns(),
+    Sliders:   NewSliders(),
+    DeepLinks: NewDeepLinks(),
+  }
+}
+
+var (
+  _ models.TrackingsRepository = (*Trackings)(nil)
+  _ models.SessionsRepository  = (*Sessions)(nil)
+  _ models.MessagesRepository  = (*Messages)(nil)
+  _ models.IssuesRepository    = (*Issues)(nil)
+  _ models.RunsRepository      = (*Runs)(nil)
+  _ models.SlidersRepository   = (*Sliders)(nil)
+  _ models.DeepLinksRepository = (*DeepLinks)(nil)
+)
@@ -0,0 +1,64 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sync"
+
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Runs struct {
+  mu     sync.RWMutex
+  values []models.CronRun
+}
+
+func NewRuns() *Runs {
+  return &Runs{}
+}
+
+func (r *Runs) Insert(_ context.Context, run models.CronRun) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  r.values = append(r.values, run)
+
+  return nil
+}
+
+func (r *Runs) Update(_ context.Context, run *models.CronRun) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  for index, stored := range r.values {
+    if stored.UUID == run.UUID {
+      r.values[index] = *run
+      return nil
+    }
+  }
+
+  return fmt.Errorf("run with uuid: %s: %w", run.UUID, models.ErrNotFound)
+}
+
+func (r *Runs) FindLast(_ context.Context, name models.CronName, typ models.ProductType) (*models.CronRun, error) {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  var last *models.CronRun
+
+  for _, run := range r.values {
+    if run.Name != name || run.ProductType != typ {
+      continue
+    }
+    if last == nil || run.Timestamps.StartedAt.After(last.Timestamps.StartedAt) {
+      run := run
+      last = &run
+    }
+  }
+
+  if last == nil {
+    return nil, fmt.Errorf("run with name: %s and product type: %s: %w", name, typ, models.ErrNotFound)
+  }
+
+  return last, nil
+}
@@ -0,0 +1,41 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sync"
+
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Sessions struct {
+  mu     sync.RWMutex
+  values map[models.ChatId]models.Session
+}
+
+func NewSessions() *Sessions {
+  return &Sessions{
+    values: make(map[models.ChatId]models.Session),
+  }
+}
+
+func (r *Sessions) Get(_ context.Context, chatId models.ChatId) (*models.Session, error) {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  session, ok := r.values[chatId]
+  if !ok {
+    return nil, fmt.Errorf("session with chat_id: %d: %w", chatId, models.ErrNotFound)
+  }
+
+  return &session, nil
+}
+
+func (r *Sessions) Upsert(_ context.Context, session models.Session) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  r.values[session.ChatId] = session
+
+  return nil
+}
@@ -0,0 +1,59 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sync"
+  "time"
+
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type sliderKey struct {
+  chatId    models.ChatId
+  messageId int
+}
+
+type Sliders struct {
+  mu     sync.RWMutex
+  values map[sliderKey]models.Slider
+}
+
+func NewSliders() *Sliders {
+  return &Sliders{
+    values: make(map[sliderKey]models.Slider),
+  }
+}
+
+func (r *Sliders) Insert(_ context.Context, slider models.Slider) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := sliderKey{
+    chatId:    slider.ChatId,
+    messageId: slider.MessageId,
+  }
+
+  if _, ok := r.values[key]; ok {
+    return fmt.Errorf("slider with chat_id: %d, message_id: %d: %w", slider.ChatId, slider.MessageId, models.ErrAlreadyExists)
+  }
+
+  r.values[key] = slider
+
+  return nil
+}
+
+func (r *Sliders) Get(_ context.Context, chatId models.ChatId, messageId int) (*models.Slider, error) {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  slider, ok := r.values[sliderKey{
+    chatId:    chatId,
+    messageId: messageId,
+  }]
+  if !ok || !slider.ExpiresAt.After(time.Now()) {
+    return nil, fmt.Errorf("slider with chat_id: %d, message_id: %d: %w", chatId, messageId, models.ErrNotFound)
+  }
+
+  return &slider, nil
+}
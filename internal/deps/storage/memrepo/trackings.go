@@ -0,0 +1,413 @@
+package memrepo
+
+import (
+  "context"
+  "fmt"
+  "sort"
+  "strings"
+  "sync"
+  "time"
+
+  "github.com/samber/lo"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type trackingKey struct {
+  chatId models.ChatId
+  url    models.ProductURL
+}
+
+type Trackings struct {
+  mu     sync.RWMutex
+  values map[trackingKey]models.Tracking
+}
+
+func NewTrackings() *Trackings {
+  return &Trackings{
+    values: make(map[trackingKey]models.Tracking),
+  }
+}
+
+func (r *Trackings) Get(_ context.Context, chatId models.ChatId, url models.ProductURL) (*models.Tracking, error) {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  tracking, ok := r.values[trackingKey{chatId: chatId, url: url}]
+  if !ok || tracking.Timestamps.DeletedAt != nil {
+    return nil, fmt.Errorf("tracking with chat_id: %d and url: %s: %w", chatId, url, models.ErrNotFound)
+  }
+
+  return &tracking, nil
+}
+
+func (r *Trackings) GetByProductKey(_ context.Context, chatId models.ChatId, key models.ProductKey) (*models.Tracking, error) {
+  list := r.filter(1, func(tracking models.Tracking) bool {
+    return tracking.ChatId == chatId && tracking.ProductKey == key && tracking.Timestamps.DeletedAt == nil
+  })
+  if len(list) == 0 {
+    return nil, fmt.Errorf("tracking with chat_id: %d and product_key: %s: %w", chatId, key, models.ErrNotFound)
+  }
+
+  return list[0], nil
+}
+
+func (r *Trackings) GetById(_ context.Context, chatId models.ChatId, id models.TrackingId) (*models.Tracking, error) {
+  list := r.filter(1, func(tracking models.Tracking) bool {
+    return tracking.ChatId == chatId && tracking.Id() == id && tracking.Timestamps.DeletedAt == nil
+  })
+  if len(list) == 0 {
+    return nil, fmt.Errorf("tracking with chat_id: %d and id: %s: %w", chatId, id, models.ErrNotFound)
+  }
+
+  return list[0], nil
+}
+
+func (r *Trackings) List(_ context.Context, chatId models.ChatId, limit int64) ([]*models.Tracking, error) {
+  return r.filter(limit, func(tracking models.Tracking) bool {
+    return tracking.ChatId == chatId && tracking.Timestamps.DeletedAt == nil
+  }), nil
+}
+
+func (r *Trackings) Search(_ context.Context, chatId models.ChatId, query string, limit int64) ([]*models.Tracking, error) {
+  words := strings.Fields(strings.ToLower(query))
+
+  return r.filter(limit, func(tracking models.Tracking) bool {
+    if tracking.ChatId != chatId || tracking.Timestamps.DeletedAt != nil {
+      return false
+    }
+
+    text := strings.ToLower(strings.Join([]string{
+      tracking.ParsedProduct.Brand,
+      tracking.ParsedProduct.Category,
+      tracking.ParsedProduct.Description,
+      tracking.URL,
+      tracking.Comment,
+    }, " "))
+
+    for _, word := range words {
+      if strings.Contains(text, word) {
+        return true
+      }
+    }
+
+    return false
+  }), nil
+}
+
+func (r *Trackings) Claim(_ context.Context, params models.ClaimTrackingsParams) ([]*models.Tracking, error) {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  now := time.Now()
+
+  keys := make([]trackingKey, 0)
+
+  for key, tracking := range r.values {
+    if isClaimable(tracking, params, now) {
+      keys = append(keys, key)
+    }
+  }
+
+  sort.SliceStable(keys, func(i, j int) bool {
+    left, right := r.values[keys[i]].Timestamps.HandledAt, r.values[keys[j]].Timestamps.HandledAt
+
+    if left == nil || right == nil {
+      return left == nil && right != nil
+    }
+    return left.Before(*right)
+  })
+
+  if len(keys) > params.Limit {
+    keys = keys[:params.Limit]
+  }
+
+  list := make([]*models.Tracking, 0, len(keys))
+
+  for _, key := range keys {
+    tracking := r.values[key]
+
+    tracking.Lease = &models.TrackingLease{
+      Owner:     params.Owner,
+      ExpiresAt: now.Add(params.TTL),
+    }
+    r.values[key] = tracking
+
+    list = append(list, &tracking)
+  }
+
+  return list, nil
+}
+
+func isClaimable(tracking models.Tracking, params models.ClaimTrackingsParams, now time.Time) bool {
+  if tracking.Lease != nil && tracking.Lease.ExpiresAt.After(now) {
+    return false
+  }
+  if handledAt := tracking.Timestamps.HandledAt; handledAt != nil && !handledAt.Before(params.HandledBefore) {
+    return false
+  }
+  if tracking.Timestamps.DeletedAt != nil {
+    return false
+  }
+  if params.Filter.ExcludePaused && tracking.Pause.IsPaused(now) {
+    return false
+  }
+  if params.Filter.ExcludeArchived && tracking.Archive != nil {
+    return false
+  }
+  if typ := params.Filter.ProductType; typ != "" && tracking.ParsedProduct.Type != typ {
+    return false
+  }
+  return !lo.Contains(params.Filter.ExcludeProductTypes, tracking.ParsedProduct.Type)
+}
+
+func (r *Trackings) Release(_ context.Context, params models.ReleaseTrackingParams) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: params.ChatId, url: params.URL}
+
+  tracking, ok := r.values[key]
+  if !ok || tracking.Lease == nil || tracking.Lease.Owner != params.Owner {
+    return nil
+  }
+
+  tracking.Lease = nil
+  tracking.Timestamps.HandledAt = lo.ToPtr(params.HandledAt)
+
+  r.values[key] = tracking
+
+  return nil
+}
+
+func (r *Trackings) Insert(_ context.Context, tracking models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  // Отслеживание той же ссылки из корзины заменяется новым.
+  if stored, ok := r.values[key]; ok && stored.Timestamps.DeletedAt == nil {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrAlreadyExists)
+  }
+
+  tracking.TrackingId = tracking.Id()
+  r.values[key] = tracking
+
+  return nil
+}
+
+func (r *Trackings) Update(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  if _, ok := r.active(key); !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  r.values[key] = *tracking
+
+  return nil
+}
+
+// active возвращает отслеживание вне корзины. Отслеживания из корзины не изменяются, пока не будут восстановлены.
+func (r *Trackings) active(key trackingKey) (models.Tracking, bool) {
+  stored, ok := r.values[key]
+  if !ok || stored.Timestamps.DeletedAt != nil {
+    return models.Tracking{}, false
+  }
+  return stored, true
+}
+
+func (r *Trackings) UpdateParsed(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  stored, ok := r.active(key)
+  if !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  stored.ParsedProduct = tracking.ParsedProduct
+  stored.Timestamps.HandledAt = tracking.Timestamps.HandledAt
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) UpdateSettings(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  stored, ok := r.active(key)
+  if !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  stored.Sizes = tracking.Sizes
+  stored.Flags = tracking.Flags
+  stored.Comment = tracking.Comment
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) UpdatePause(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  stored, ok := r.active(key)
+  if !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  stored.Pause = tracking.Pause
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) UpdateArchive(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  stored, ok := r.active(key)
+  if !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  stored.Archive = tracking.Archive
+  stored.Timestamps.FailedSince = tracking.Timestamps.FailedSince
+  stored.Timestamps.ReactivatedAt = tracking.Timestamps.ReactivatedAt
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) UpdateExpiry(_ context.Context, tracking *models.Tracking) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: tracking.ChatId, url: tracking.URL}
+
+  stored, ok := r.active(key)
+  if !ok {
+    return fmt.Errorf("tracking with chat_id: %d and url: %s: %w", tracking.ChatId, tracking.URL, models.ErrNotFound)
+  }
+
+  stored.Timestamps.ExpiresAt = tracking.Timestamps.ExpiresAt
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) Delete(_ context.Context, params models.DeleteTrackingParams) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: params.ChatId, url: params.URL}
+
+  stored, ok := r.values[key]
+  if !ok || stored.Timestamps.DeletedAt != nil {
+    return nil
+  }
+
+  stored.Timestamps.DeletedAt = lo.ToPtr(params.DeletedAt)
+  stored.Timestamps.PurgeAt = lo.ToPtr(params.PurgeAt)
+  stored.Lease = nil
+
+  r.values[key] = stored
+
+  return nil
+}
+
+func (r *Trackings) Restore(_ context.Context, chatId models.ChatId, url models.ProductURL) error {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  key := trackingKey{chatId: chatId, url: url}
+
+  // Отслеживание с истекшим сроком хранения считается удаленным окончательно и не восстанавливается.
+  stored, ok := r.values[key]
+  if !ok || stored.Timestamps.DeletedAt == nil || !stored.Timestamps.PurgeAt.After(time.Now()) {
+    return fmt.Errorf("deleted tracking with chat_id: %d and url: %s: %w", chatId, url, models.ErrNotFound)
+  }
+
+  stored.Timestamps.DeletedAt = nil
+  stored.Timestamps.PurgeAt = nil
+
+  r.values[key] = stored
+
+  return nil
+}
+
+// ListDeleted возвращает отслеживания из корзины. Отслеживания с истекшим сроком хранения считаются удаленными окончательно.
+func (r *Trackings) ListDeleted(_ context.Context, chatId models.ChatId, limit int64) ([]*models.Tracking, error) {
+  now := time.Now()
+
+  list := r.filter(0, func(tracking models.Tracking) bool {
+    return tracking.ChatId == chatId &&
+      tracking.Timestamps.DeletedAt != nil &&
+      tracking.Timestamps.PurgeAt.After(now)
+  })
+
+  sort.SliceStable(list, func(i, j int) bool {
+    return list[i].Timestamps.DeletedAt.After(*list[j].Timestamps.DeletedAt)
+  })
+
+  if limit > 0 && int64(len(list)) > limit {
+    list = list[:limit]
+  }
+
+  return list, nil
+}
+
+// GetDeletedById ищет отслеживание в корзине. Отслеживание с истекшим сроком хранения считается удаленным окончательно.
+func (r *Trackings) GetDeletedById(_ context.Context, chatId models.ChatId, id models.TrackingId) (*models.Tracking, error) {
+  now := time.Now()
+
+  list := r.filter(1, func(tracking models.Tracking) bool {
+    return tracking.ChatId == chatId &&
+      tracking.Id() == id &&
+      tracking.Timestamps.DeletedAt != nil &&
+      tracking.Timestamps.PurgeAt.After(now)
+  })
+  if len(list) == 0 {
+    return nil, fmt.Errorf("deleted tracking with chat_id: %d and id: %s: %w", chatId, id, models.ErrNotFound)
+  }
+
+  return list[0], nil
+}
+
+func (r *Trackings) filter(limit int64, match func(tracking models.Tracking) bool) []*models.Tracking {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  list := make([]*models.Tracking, 0)
+
+  for _, tracking := range r.values {
+    if limit > 0 && int64(len(list)) >= limit {
+      break
+    }
+    if match(tracking) {
+      tracking := tracking
+      list = append(list, &tracking)
+    }
+  }
+
+  return list
+}
@@ -2,10 +2,13 @@ package mongodb
 
 import (
   "context"
+  "crypto/tls"
   "errors"
   "fmt"
   "net/http"
+  "net/url"
   "strings"
+  "time"
 
   "github.com/go-playground/validator/v10"
   log "github.com/sirupsen/logrus"
@@ -13,16 +16,32 @@ import (
   "go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var ErrNotFound = errors.New("document not found")
+const (
+  errCodeNamespaceNotFound = 26
+  errCodeIndexNotFound     = 27
+)
+
+var (
+  ErrNotFound     = errors.New("document not found")
+  ErrDuplicateKey = errors.New("duplicate key")
+)
 
 type Client struct {
   client *mongo.Client
 }
 
 type Config struct {
-  Host           string          `validate:"required"`
-  Port           string          `validate:"required"`
-  Authentication *Authentication `validate:"required"`
+  // URI строка подключения. Если указана, Host, Port и Authentication не используются.
+  URI            string
+  Host           string `validate:"required_without=URI"`
+  Port           string `validate:"required_without=URI"`
+  Authentication *Authentication
+
+  AuthSource             string
+  ReplicaSet             string
+  TLS                    bool
+  ConnectTimeout         time.Duration
+  ServerSelectionTimeout time.Duration
 }
 
 type Authentication struct {
@@ -43,6 +62,10 @@ func (c *Dependencies) Validate() error {
 }
 
 func (c *Config) ConnectionString() string {
+  if c.URI != "" {
+    return c.URI
+  }
+
   sb := strings.Builder{}
 
   write := func(s string) {
@@ -52,9 +75,7 @@ func (c *Config) ConnectionString() string {
   sb.WriteString("mongodb://")
 
   if c.Authentication != nil {
-    write(c.Authentication.User)
-    write(":")
-    write(c.Authentication.Password)
+    write(url.UserPassword(c.Authentication.User, c.Authentication.Password).String())
     write("@")
   }
 
@@ -65,6 +86,30 @@ func (c *Config) ConnectionString() string {
   return sb.String()
 }
 
+func (c *Config) toOptions() *options.ClientOptions {
+  opts := options.
+    Client().
+    ApplyURI(c.ConnectionString())
+
+  if c.AuthSource != "" && opts.Auth != nil {
+    opts.Auth.AuthSource = c.AuthSource
+  }
+  if c.ReplicaSet != "" {
+    opts.SetReplicaSet(c.ReplicaSet)
+  }
+  if c.TLS {
+    opts.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+  }
+  if c.ConnectTimeout != 0 {
+    opts.SetConnectTimeout(c.ConnectTimeout)
+  }
+  if c.ServerSelectionTimeout != 0 {
+    opts.SetServerSelectionTimeout(c.ServerSelectionTimeout)
+  }
+
+  return opts
+}
+
 func NewClient(ctx context.Context, config Config, deps Dependencies) (*Client, error) {
   if err := deps.Validate(); err != nil {
     return nil, fmt.Errorf("invalid dependencies: %w", err)
@@ -73,10 +118,9 @@ func NewClient(ctx context.Context, config Config, deps Dependencies) (*Client,
     return nil, fmt.Errorf("invalid config: %w", err)
   }
 
-  opts := options.
-    Client().
-    SetHTTPClient(deps.Client).
-    ApplyURI(config.ConnectionString())
+  opts := config.
+    toOptions().
+    SetHTTPClient(deps.Client)
 
   client, err := mongo.Connect(ctx, opts)
   if err != nil {
@@ -89,8 +133,10 @@ func NewClient(ctx context.Context, config Config, deps Dependencies) (*Client,
 
   log.
     WithFields(log.Fields{
-      "config.host": config.Host,
-      "config.port": config.Port,
+      "config.host":        config.Host,
+      "config.port":        config.Port,
+      "config.replica_set": config.ReplicaSet,
+      "config.tls":         config.TLS,
     }).
     Info("mongodb connection successfully")
 
@@ -98,3 +144,10 @@ func NewClient(ctx context.Context, config Config, deps Dependencies) (*Client,
     client: client,
   }, nil
 }
+
+func (c *Client) Ping(ctx context.Context) error {
+  if err := c.client.Ping(ctx, nil); err != nil {
+    return fmt.Errorf("c.client.Ping: %w", err)
+  }
+  return nil
+}
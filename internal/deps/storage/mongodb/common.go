@@ -1,10 +1,11 @@
 package mongodb
 
 import (
-  "reflect"
+  "errors"
+  "fmt"
 
-  "github.com/ushakovn/outfit/pkg/reflection"
   "go.mongodb.org/mongo-driver/bson"
+  "go.mongodb.org/mongo-driver/mongo"
 )
 
 func makeBsonBsonDSort(params []SortParams) bson.D {
@@ -20,38 +21,6 @@ func makeBsonBsonDSort(params []SortParams) bson.D {
   return bsonD
 }
 
-func makeBsonDUpdates(document any) bson.D {
-  updates := bson.D{}
-
-  typ := reflect.TypeOf(document)
-  value := reflect.ValueOf(document)
-
-  if typ.Kind() == reflect.Ptr {
-    typ = typ.Elem()
-    value = value.Elem()
-  }
-
-  for i := 1; i < typ.NumField(); i++ {
-    field := typ.Field(i)
-
-    val := value.Field(i)
-    tag := field.Tag.Get("bson")
-
-    if !reflection.IsZeroType(val) {
-      update := bson.E{
-        Key:   tag,
-        Value: val.Interface(),
-      }
-      updates = append(updates, update)
-    }
-  }
-
-  return bson.D{{
-    Key:   "$set",
-    Value: updates,
-  }}
-}
-
 func makeBsonDFilters(kv map[string]any) bson.D {
   out := bson.D{}
 
@@ -65,13 +34,16 @@ func makeBsonDFilters(kv map[string]any) bson.D {
   return out
 }
 
-func isZeroType(value reflect.Value) bool {
-  zero := reflect.Zero(value.Type()).Interface()
+func wrapDuplicateKey(err error) error {
+  if mongo.IsDuplicateKeyError(err) {
+    return fmt.Errorf("%w: %w", ErrDuplicateKey, err)
+  }
+  return err
+}
 
-  switch value.Kind() {
-  case reflect.Slice, reflect.Array, reflect.Chan, reflect.Map:
-    return value.Len() == 0
-  default:
-    return reflect.DeepEqual(zero, value.Interface())
+func isIndexNotFound(err error) bool {
+  if se := mongo.ServerError(nil); errors.As(err, &se) {
+    return se.HasErrorCode(errCodeIndexNotFound) || se.HasErrorCode(errCodeNamespaceNotFound)
   }
+  return false
 }
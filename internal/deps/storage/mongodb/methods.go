@@ -80,7 +80,7 @@ func (c *Client) Scan(ctx context.Context, params ScanParams) error {
 
   defer func() {
     if err = cursor.Close(ctx); err != nil {
-      log.Error("mongodb.Client: cursor.Close: %v", err)
+      log.Errorf("mongodb.Client: cursor.Close: %v", err)
     }
   }()
 
@@ -118,7 +118,14 @@ func (c *Client) Scan(ctx context.Context, params ScanParams) error {
 type UpdateParams struct {
   GetParams
 
-  Document any
+  Updates *Updates
+}
+
+func (p *UpdateParams) Validate() error {
+  if p.Updates.IsEmpty() {
+    return errors.New("updates not specified")
+  }
+  return nil
 }
 
 func (p *UpdateParams) toFilters() bson.D {
@@ -126,33 +133,27 @@ func (p *UpdateParams) toFilters() bson.D {
 }
 
 func (p *UpdateParams) toUpdates() bson.D {
-  return makeBsonDUpdates(p.Document)
+  return p.Updates.toBsonD()
 }
 
+// Upsert обновляет документ или вставляет новый одной атомарной операцией.
 func (c *Client) Upsert(ctx context.Context, params UpdateParams) (id any, err error) {
-  res, err := c.Get(ctx, params.GetParams)
-  if err != nil {
-    if errors.Is(err, ErrNotFound) {
-      log.
-        WithFields(log.Fields{
-          "params.database":   params.Database,
-          "params.collection": params.Collection,
-          "params.filters":    params.Filters,
-        }).
-        Debug("document not found in mongodb collection. new document will be inserted")
-
-      res, err = c.Insert(ctx, InsertParams{
-        CommonParams: params.CommonParams,
-        Document:     params.Document,
-      })
-      if err != nil {
-        return nil, fmt.Errorf("c.Insert: %w", err)
-      }
-
-      return res, nil
-    }
+  if err = params.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid params: %w", err)
+  }
+
+  filters := params.toFilters()
+  updates := params.toUpdates()
 
-    return nil, fmt.Errorf("c.Get: %w", err)
+  opts := options.Update().SetUpsert(true)
+
+  res, err := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    UpdateOne(ctx, filters, updates, opts)
+
+  if err != nil {
+    return nil, fmt.Errorf("c.client.Database.Collection.UpdateOne: %w", wrapDuplicateKey(err))
   }
 
   log.
@@ -160,21 +161,18 @@ func (c *Client) Upsert(ctx context.Context, params UpdateParams) (id any, err e
       "params.database":   params.Database,
       "params.collection": params.Collection,
       "params.filters":    params.Filters,
+      "mongodb.upserted":  res.UpsertedCount != 0,
     }).
-    Debug("document found in mongodb collection. existed document will be updated")
-
-  res, err = c.Update(ctx, UpdateParams{
-    GetParams: params.GetParams,
-    Document:  params.Document,
-  })
-  if err != nil {
-    return nil, fmt.Errorf("c.Insert: %w", err)
-  }
+    Debug("document in mongodb collection upserted successfully")
 
-  return res, nil
+  return res.UpsertedID, nil
 }
 
 func (c *Client) Update(ctx context.Context, params UpdateParams) (id any, err error) {
+  if err = params.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid params: %w", err)
+  }
+
   filters := params.toFilters()
   updates := params.toUpdates()
 
@@ -184,7 +182,7 @@ func (c *Client) Update(ctx context.Context, params UpdateParams) (id any, err e
     UpdateOne(ctx, filters, updates)
 
   if err != nil {
-    return nil, fmt.Errorf("c.client.Database.Collection.UpdateOne: %w", err)
+    return nil, fmt.Errorf("c.client.Database.Collection.UpdateOne: %w", wrapDuplicateKey(err))
   }
 
   log.
@@ -196,7 +194,96 @@ func (c *Client) Update(ctx context.Context, params UpdateParams) (id any, err e
     Debug("document in mongodb collection updated successfully")
 
   return res.UpsertedID, nil
+}
+
+type ReplaceParams struct {
+  GetParams
 
+  Document any
+  // Upsert вставляет документ, если по фильтрам не найдено ни одного документа.
+  Upsert bool
+}
+
+func (p *ReplaceParams) toFilters() bson.D {
+  return makeBsonDFilters(p.GetParams.Filters)
+}
+
+// Replace заменяет документ целиком, сохраняя его _id.
+func (c *Client) Replace(ctx context.Context, params ReplaceParams) (id any, err error) {
+  filters := params.toFilters()
+
+  opts := options.Replace().SetUpsert(params.Upsert)
+
+  res, err := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    ReplaceOne(ctx, filters, params.Document, opts)
+
+  if err != nil {
+    return nil, fmt.Errorf("c.client.Database.Collection.ReplaceOne: %w", wrapDuplicateKey(err))
+  }
+
+  log.
+    WithFields(log.Fields{
+      "params.database":   params.Database,
+      "params.collection": params.Collection,
+      "params.filters":    params.Filters,
+      "mongodb.upserted":  res.UpsertedCount != 0,
+    }).
+    Debug("document in mongodb collection replaced successfully")
+
+  return res.UpsertedID, nil
+}
+
+type ReplaceManyParams struct {
+  CommonParams
+
+  Replaces []ReplaceManyModel
+  // Upsert вставляет документ, если по фильтрам замены не найдено ни одного документа.
+  Upsert bool
+}
+
+type ReplaceManyModel struct {
+  Filters  map[string]any
+  Document any
+}
+
+// ReplaceMany заменяет документы одним запросом. Замены независимы и выполняются без сохранения порядка.
+func (c *Client) ReplaceMany(ctx context.Context, params ReplaceManyParams) (count int64, err error) {
+  if len(params.Replaces) == 0 {
+    return 0, nil
+  }
+
+  writes := make([]mongo.WriteModel, 0, len(params.Replaces))
+
+  for _, replace := range params.Replaces {
+    writes = append(writes, mongo.NewReplaceOneModel().
+      SetFilter(makeBsonDFilters(replace.Filters)).
+      SetReplacement(replace.Document).
+      SetUpsert(params.Upsert))
+  }
+
+  opts := options.BulkWrite().SetOrdered(false)
+
+  res, err := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    BulkWrite(ctx, writes, opts)
+
+  if err != nil {
+    return 0, fmt.Errorf("c.client.Database.Collection.BulkWrite: %w", wrapDuplicateKey(err))
+  }
+
+  log.
+    WithFields(log.Fields{
+      "params.database":   params.Database,
+      "params.collection": params.Collection,
+      "mongodb.matched":   res.MatchedCount,
+      "mongodb.upserted":  res.UpsertedCount,
+    }).
+    Debug("documents in mongodb collection replaced successfully")
+
+  return res.MatchedCount + res.UpsertedCount, nil
 }
 
 type InsertParams struct {
@@ -212,7 +299,7 @@ func (c *Client) Insert(ctx context.Context, params InsertParams) (id any, err e
     InsertOne(ctx, params.Document)
 
   if err != nil {
-    return nil, fmt.Errorf("c.client.Database.Collection.InsertOne: %w", err)
+    return nil, fmt.Errorf("c.client.Database.Collection.InsertOne: %w", wrapDuplicateKey(err))
   }
 
   log.
@@ -225,6 +312,53 @@ func (c *Client) Insert(ctx context.Context, params InsertParams) (id any, err e
   return res.InsertedID, nil
 }
 
+type InsertIfNotExistParams struct {
+  GetParams
+
+  Document any
+}
+
+func (p *InsertIfNotExistParams) toFilters() bson.D {
+  return makeBsonDFilters(p.GetParams.Filters)
+}
+
+// InsertIfNotExist вставляет документ, если по фильтрам не найдено ни одного документа.
+// Проверка и вставка выполняются одной атомарной операцией.
+func (c *Client) InsertIfNotExist(ctx context.Context, params InsertIfNotExistParams) (inserted bool, err error) {
+  filters := params.toFilters()
+
+  updates := bson.D{{
+    Key:   "$setOnInsert",
+    Value: params.Document,
+  }}
+
+  opts := options.Update().SetUpsert(true)
+
+  res, err := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    UpdateOne(ctx, filters, updates, opts)
+
+  if err != nil {
+    // Конкурентная вставка с тем же уникальным ключом.
+    if mongo.IsDuplicateKeyError(err) {
+      return false, nil
+    }
+    return false, fmt.Errorf("c.client.Database.Collection.UpdateOne: %w", err)
+  }
+
+  log.
+    WithFields(log.Fields{
+      "params.database":   params.Database,
+      "params.collection": params.Collection,
+      "params.filters":    params.Filters,
+      "mongodb.upserted":  res.UpsertedCount != 0,
+    }).
+    Debug("document insert if not exist completed")
+
+  return res.UpsertedCount != 0, nil
+}
+
 type GetParams struct {
   CommonParams
 
@@ -309,7 +443,7 @@ func (c *Client) Find(ctx context.Context, params FindParams) ([]any, error) {
 
   defer func() {
     if err = cursor.Close(ctx); err != nil {
-      log.Error("mongodb.Telegram: cursor.Close: %v", err)
+      log.Errorf("mongodb.Client: cursor.Close: %v", err)
     }
   }()
 
@@ -380,6 +514,8 @@ func (c *Client) Delete(ctx context.Context, params DeleteParams) (count int64,
 type TextSearchParams struct {
   CommonParams
 
+  Filters map[string]any
+
   Query string
   Limit int64
 }
@@ -394,6 +530,10 @@ func (c *Client) TextSearch(ctx context.Context, params TextSearchParams) ([]any
     },
   }
 
+  for key, value := range params.Filters {
+    filters[key] = value
+  }
+
   res, err := c.Find(ctx, FindParams{
     CommonParams: params.CommonParams,
     Filters:      filters,
@@ -455,3 +595,199 @@ func (c *Client) CreateIndex(ctx context.Context, params CreateIndexParams) (nam
 
   return name, nil
 }
+
+type DeleteDuplicatesParams struct {
+  CommonParams
+
+  // Keys поля, по которым документы считаются дубликатами.
+  Keys []string
+  // Sorting порядок документов внутри группы дубликатов. Первый документ сохраняется.
+  Sorting []SortParams
+}
+
+func (p *DeleteDuplicatesParams) toPipeline() mongo.Pipeline {
+  group := make(bson.D, 0, len(p.Keys))
+
+  for index, key := range p.Keys {
+    group = append(group, bson.E{
+      Key:   fmt.Sprintf("k%d", index),
+      Value: "$" + key,
+    })
+  }
+
+  pipeline := mongo.Pipeline{}
+
+  if len(p.Sorting) != 0 {
+    pipeline = append(pipeline, bson.D{{
+      Key:   "$sort",
+      Value: makeBsonBsonDSort(p.Sorting),
+    }})
+  }
+
+  return append(pipeline,
+    bson.D{{
+      Key: "$group",
+      Value: bson.D{
+        {Key: "_id", Value: group},
+        {Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+        {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+      },
+    }},
+    bson.D{{
+      Key:   "$match",
+      Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}},
+    }},
+  )
+}
+
+// DeleteDuplicates удаляет документы с совпадающими значениями Keys.
+// В каждой группе дубликатов сохраняется первый документ в порядке Sorting.
+func (c *Client) DeleteDuplicates(ctx context.Context, params DeleteDuplicatesParams) (count int64, err error) {
+  collection := c.client.
+    Database(params.Database).
+    Collection(params.Collection)
+
+  opts := options.Aggregate().SetAllowDiskUse(true)
+
+  cursor, err := collection.Aggregate(ctx, params.toPipeline(), opts)
+  if err != nil {
+    return 0, fmt.Errorf("c.client.Database.Collection.Aggregate: %w", err)
+  }
+
+  defer func() {
+    if err := cursor.Close(ctx); err != nil {
+      log.Errorf("mongodb.Client: cursor.Close: %v", err)
+    }
+  }()
+
+  for cursor.Next(ctx) {
+    var group struct {
+      IDs []any `bson:"ids"`
+    }
+
+    if err = cursor.Decode(&group); err != nil {
+      return count, fmt.Errorf("cursor.Decode: %w", err)
+    }
+
+    res, err := collection.DeleteMany(ctx, bson.D{{
+      Key:   "_id",
+      Value: bson.D{{Key: "$in", Value: group.IDs[1:]}},
+    }})
+    if err != nil {
+      return count, fmt.Errorf("c.client.Database.Collection.DeleteMany: %w", err)
+    }
+
+    count += res.DeletedCount
+  }
+
+  if err = cursor.Err(); err != nil {
+    return count, fmt.Errorf("cursor.Err: %w", err)
+  }
+
+  log.
+    WithFields(log.Fields{
+      "params.database":      params.Database,
+      "params.collection":    params.Collection,
+      "params.keys":          params.Keys,
+      "mongodb.delete.count": count,
+    }).
+    Info("mongodb collection duplicates deleted")
+
+  return count, nil
+}
+
+type DropIndexParams struct {
+  CommonParams
+
+  Name string
+}
+
+// DropIndex удаляет индекс по имени. Возвращает ErrNotFound, если индекс или коллекция не существуют.
+func (c *Client) DropIndex(ctx context.Context, params DropIndexParams) error {
+  _, err := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    Indexes().
+    DropOne(ctx, params.Name)
+
+  if err != nil {
+    if isIndexNotFound(err) {
+      return fmt.Errorf("%w: %w", ErrNotFound, err)
+    }
+    return fmt.Errorf("c.client.Database.Collection.Indexes.DropOne: %w", err)
+  }
+
+  log.
+    WithFields(log.Fields{
+      "params.database":   params.Database,
+      "params.collection": params.Collection,
+      "params.name":       params.Name,
+    }).
+    Info("mongodb collection index dropped")
+
+  return nil
+}
+
+type FindOneAndUpdateParams struct {
+  GetParams
+
+  Sorting []SortParams
+  Updates *Updates
+}
+
+func (p *FindOneAndUpdateParams) Validate() error {
+  if p.Updates.IsEmpty() {
+    return errors.New("updates not specified")
+  }
+  return nil
+}
+
+func (p *FindOneAndUpdateParams) toFilters() bson.D {
+  return makeBsonDFilters(p.GetParams.Filters)
+}
+
+func (p *FindOneAndUpdateParams) toOptions() *options.FindOneAndUpdateOptions {
+  opts := options.
+    FindOneAndUpdate().
+    SetReturnDocument(options.After)
+
+  if len(p.Sorting) != 0 {
+    sort := makeBsonBsonDSort(p.Sorting)
+    opts.SetSort(sort)
+  }
+
+  return opts
+}
+
+// FindOneAndUpdate атомарно обновляет первый найденный документ и возвращает его после обновления.
+// Возвращает ErrNotFound, если по фильтрам не найдено ни одного документа.
+func (c *Client) FindOneAndUpdate(ctx context.Context, params FindOneAndUpdateParams) (any, error) {
+  if err := params.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid params: %w", err)
+  }
+
+  res := c.client.
+    Database(params.Database).
+    Collection(params.Collection).
+    FindOneAndUpdate(ctx, params.toFilters(), params.Updates.toBsonD(), params.toOptions())
+
+  if err := res.Err(); err != nil {
+    if errors.Is(err, mongo.ErrNoDocuments) {
+      return nil, ErrNotFound
+    }
+    return nil, fmt.Errorf("c.client.Database.Collection.FindOneAndUpdate: %w", err)
+  }
+
+  doc := any(make(map[string]any))
+
+  if params.StructType != nil {
+    typ := reflect.TypeOf(params.StructType)
+    doc = reflect.New(typ).Interface()
+  }
+
+  if err := res.Decode(doc); err != nil {
+    return nil, fmt.Errorf("res.Decode: %T: %w", doc, err)
+  }
+
+  return doc, nil
+}
@@ -0,0 +1,174 @@
+package migrations
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "time"
+
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "go.mongodb.org/mongo-driver/bson"
+)
+
+func (m *Migrator) lockCommon() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   m.config.Database,
+    Collection: m.config.LockCollection,
+  }
+}
+
+// acquireLock ожидает блокировку, пока она занята другим экземпляром.
+func (m *Migrator) acquireLock(ctx context.Context) error {
+  ctx, cancel := context.WithTimeout(ctx, m.config.LockWait)
+  defer cancel()
+
+  ticker := time.NewTicker(defaultLockRetryInterval)
+  defer ticker.Stop()
+
+  for {
+    err := m.tryLock(ctx)
+    if err == nil {
+      return nil
+    }
+    if !errors.Is(err, ErrLocked) {
+      return fmt.Errorf("m.tryLock: %w", err)
+    }
+
+    log.
+      WithField("migrations.owner", m.config.Owner).
+      Info("mongodb migrations locked by another owner. waiting")
+
+    select {
+    case <-ctx.Done():
+      return fmt.Errorf("%w: %w", ErrLocked, ctx.Err())
+    case <-ticker.C:
+    }
+  }
+}
+
+// tryLock захватывает блокировку одной атомарной операцией.
+// Если блокировка занята и не истекла, фильтр не находит документ,
+// и вставка нового документа с тем же _id завершается ошибкой дубликата ключа.
+func (m *Migrator) tryLock(ctx context.Context) error {
+  now := time.Now()
+
+  _, err := m.deps.Mongodb.Upsert(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: m.lockCommon(),
+      Filters: map[string]any{
+        "_id": lockDocumentId,
+        "$or": bson.A{
+          bson.D{{Key: "owner", Value: m.config.Owner}},
+          bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: now}}}},
+        },
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Set("owner", m.config.Owner).
+      Set("locked_at", now).
+      Set("expires_at", now.Add(m.config.LockTTL)),
+  })
+  if err != nil {
+    if errors.Is(err, mongodb.ErrDuplicateKey) {
+      return ErrLocked
+    }
+    return fmt.Errorf("m.deps.Mongodb.Upsert: %w", err)
+  }
+
+  return nil
+}
+
+// holdLock продлевает блокировку, пока выполняются миграции.
+// Возвращенный контекст отменяется с причиной ErrLockLost, если блокировка перешла другому экземпляру.
+// stop останавливает продление и возвращает ErrLockLost, если блокировка была потеряна.
+func (m *Migrator) holdLock(ctx context.Context) (lockCtx context.Context, stop func() error) {
+  lockCtx, cancel := context.WithCancelCause(ctx)
+
+  stopped := make(chan struct{})
+  done := make(chan struct{})
+
+  go func() {
+    defer close(done)
+
+    // Продление несколько раз за время жизни блокировки переживает единичные ошибки.
+    ticker := time.NewTicker(m.config.LockTTL / 3)
+    defer ticker.Stop()
+
+    for {
+      select {
+      case <-stopped:
+        return
+      case <-lockCtx.Done():
+        return
+      case <-ticker.C:
+      }
+
+      if err := m.renewLock(lockCtx); err != nil {
+        if errors.Is(err, ErrLockLost) {
+          cancel(err)
+          return
+        }
+
+        log.
+          WithField("migrations.owner", m.config.Owner).
+          Errorf("m.renewLock: %v", err)
+      }
+    }
+  }()
+
+  return lockCtx, func() error {
+    close(stopped)
+    <-done
+
+    err := context.Cause(lockCtx)
+    cancel(nil)
+
+    if errors.Is(err, ErrLockLost) {
+      return err
+    }
+    return nil
+  }
+}
+
+// renewLock продлевает блокировку, если она принадлежит этому экземпляру.
+func (m *Migrator) renewLock(ctx context.Context) error {
+  _, err := m.deps.Mongodb.FindOneAndUpdate(ctx, mongodb.FindOneAndUpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: m.lockCommon(),
+      Filters: map[string]any{
+        "_id":   lockDocumentId,
+        "owner": m.config.Owner,
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Set("expires_at", time.Now().Add(m.config.LockTTL)),
+  })
+  if err != nil {
+    if errors.Is(err, mongodb.ErrNotFound) {
+      return ErrLockLost
+    }
+    return fmt.Errorf("m.deps.Mongodb.FindOneAndUpdate: %w", err)
+  }
+
+  return nil
+}
+
+func (m *Migrator) releaseLock() {
+  // Блокировка снимается и после отмены контекста запуска.
+  ctx, cancel := context.WithTimeout(context.Background(), defaultLockRetryInterval)
+  defer cancel()
+
+  _, err := m.deps.Mongodb.Delete(ctx, mongodb.DeleteParams{
+    CommonParams: m.lockCommon(),
+    Filters: map[string]any{
+      "_id":   lockDocumentId,
+      "owner": m.config.Owner,
+    },
+  })
+  if err != nil {
+    log.
+      WithField("migrations.owner", m.config.Owner).
+      Errorf("m.deps.Mongodb.Delete: %v", err)
+  }
+}
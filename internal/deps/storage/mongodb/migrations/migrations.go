@@ -0,0 +1,270 @@
+package migrations
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "os"
+  "sort"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+  defaultLockTTL           = 10 * time.Minute
+  defaultLockWait          = 15 * time.Minute
+  defaultLockRetryInterval = 2 * time.Second
+
+  lockDocumentId = "lock"
+)
+
+var (
+  ErrLocked   = errors.New("migrations locked by another owner")
+  ErrLockLost = errors.New("migrations lock lost")
+)
+
+// Migration версионированная миграция схемы или данных.
+// Миграции применяются в порядке возрастания версий, каждая не более одного раза.
+type Migration struct {
+  Version     int
+  Description string
+  Up          func(ctx context.Context) error
+}
+
+type Migrator struct {
+  config Config
+  deps   Dependencies
+}
+
+type Config struct {
+  Database string `validate:"required"`
+  // Collection коллекция примененных версий.
+  Collection string `validate:"required"`
+  // LockCollection коллекция блокировки.
+  LockCollection string `validate:"required"`
+  // Owner идентификатор экземпляра, выполняющего миграции. По умолчанию hostname/pid.
+  Owner string
+  // LockTTL время, после которого блокировка упавшего экземпляра может быть перехвачена.
+  LockTTL time.Duration
+  // LockWait время ожидания блокировки, занятой другим экземпляром.
+  LockWait time.Duration
+  // DryRun выводит список непримененных миграций без их выполнения.
+  DryRun bool
+}
+
+type Dependencies struct {
+  Mongodb *mongodb.Client `validate:"required"`
+}
+
+func (c *Config) Validate() error {
+  return validator.New().Struct(c)
+}
+
+func (c *Dependencies) Validate() error {
+  return validator.New().Struct(c)
+}
+
+type appliedMigration struct {
+  Version     int       `bson:"version"`
+  Description string    `bson:"description"`
+  Owner       string    `bson:"owner"`
+  AppliedAt   time.Time `bson:"applied_at"`
+  Duration    string    `bson:"duration"`
+}
+
+func NewMigrator(config Config, deps Dependencies) (*Migrator, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+  if err := deps.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid dependencies: %w", err)
+  }
+
+  if config.Owner == "" {
+    config.Owner = defaultOwner()
+  }
+  if config.LockTTL == 0 {
+    config.LockTTL = defaultLockTTL
+  }
+  if config.LockWait == 0 {
+    config.LockWait = defaultLockWait
+  }
+
+  return &Migrator{
+    config: config,
+    deps:   deps,
+  }, nil
+}
+
+func (m *Migrator) Run(ctx context.Context, list []Migration) (err error) {
+  list, err = sortMigrations(list)
+  if err != nil {
+    return fmt.Errorf("sortMigrations: %w", err)
+  }
+
+  if !m.config.DryRun {
+    if err = m.acquireLock(ctx); err != nil {
+      return fmt.Errorf("m.acquireLock: %w", err)
+    }
+    defer m.releaseLock()
+
+    // Миграции выполняются с контекстом, который отменяется при потере блокировки.
+    var stopHold func() error
+
+    ctx, stopHold = m.holdLock(ctx)
+
+    defer func() {
+      if holdErr := stopHold(); holdErr != nil {
+        err = fmt.Errorf("m.holdLock: %w", holdErr)
+      }
+    }()
+
+    if err = m.ensureIndexes(ctx); err != nil {
+      return fmt.Errorf("m.ensureIndexes: %w", err)
+    }
+  }
+
+  // Примененные версии читаются после захвата блокировки,
+  // так как другой экземпляр мог применить миграции, пока мы ее ожидали.
+  applied, err := m.findApplied(ctx)
+  if err != nil {
+    return fmt.Errorf("m.findApplied: %w", err)
+  }
+
+  for _, migration := range list {
+    if _, ok := applied[migration.Version]; ok {
+      continue
+    }
+
+    fields := log.Fields{
+      "migration.version":     migration.Version,
+      "migration.description": migration.Description,
+      "migrations.dry_run":    m.config.DryRun,
+    }
+
+    if m.config.DryRun {
+      log.WithFields(fields).Warn("mongodb migration pending")
+      continue
+    }
+
+    // Блокировка могла перейти другому экземпляру во время предыдущей миграции.
+    if cause := context.Cause(ctx); cause != nil {
+      return fmt.Errorf("migration %d: %s: %w", migration.Version, migration.Description, cause)
+    }
+
+    log.WithFields(fields).Info("mongodb migration applying")
+
+    startedAt := time.Now()
+
+    if err = migration.Up(ctx); err != nil {
+      return fmt.Errorf("migration %d: %s: %w", migration.Version, migration.Description, err)
+    }
+
+    if err = m.insertApplied(ctx, migration, time.Since(startedAt)); err != nil {
+      return fmt.Errorf("m.insertApplied: %w", err)
+    }
+
+    log.WithFields(fields).Info("mongodb migration applied")
+  }
+
+  return nil
+}
+
+func sortMigrations(list []Migration) ([]Migration, error) {
+  sorted := append([]Migration(nil), list...)
+
+  sort.Slice(sorted, func(i, j int) bool {
+    return sorted[i].Version < sorted[j].Version
+  })
+
+  for index, migration := range sorted {
+    if migration.Version <= 0 {
+      return nil, fmt.Errorf("migration %q: version must be positive", migration.Description)
+    }
+    if migration.Up == nil {
+      return nil, fmt.Errorf("migration %d: up function not specified", migration.Version)
+    }
+    if index > 0 && sorted[index-1].Version == migration.Version {
+      return nil, fmt.Errorf("migration %d: duplicated version", migration.Version)
+    }
+  }
+
+  return sorted, nil
+}
+
+func (m *Migrator) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   m.config.Database,
+    Collection: m.config.Collection,
+    StructType: appliedMigration{},
+  }
+}
+
+func (m *Migrator) ensureIndexes(ctx context.Context) error {
+  _, err := m.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: m.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "version",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("migrations_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("m.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+func (m *Migrator) findApplied(ctx context.Context) (map[int]*appliedMigration, error) {
+  res, err := m.deps.Mongodb.Find(ctx, mongodb.FindParams{
+    CommonParams: m.common(),
+  })
+  if err != nil {
+    return nil, fmt.Errorf("m.deps.Mongodb.Find: %w", err)
+  }
+
+  applied := make(map[int]*appliedMigration, len(res))
+
+  for _, value := range res {
+    migration, ok := value.(*appliedMigration)
+    if !ok {
+      return nil, fmt.Errorf("cast %v with type: %[1]T to: %T failed", value, migration)
+    }
+    applied[migration.Version] = migration
+  }
+
+  return applied, nil
+}
+
+func (m *Migrator) insertApplied(ctx context.Context, migration Migration, duration time.Duration) error {
+  _, err := m.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
+    CommonParams: m.common(),
+    Document: appliedMigration{
+      Version:     migration.Version,
+      Description: migration.Description,
+      Owner:       m.config.Owner,
+      AppliedAt:   time.Now(),
+      Duration:    duration.String(),
+    },
+  })
+  if err != nil {
+    return fmt.Errorf("m.deps.Mongodb.Insert: %w", err)
+  }
+
+  return nil
+}
+
+func defaultOwner() string {
+  hostname, err := os.Hostname()
+  if err != nil {
+    hostname = "unknown"
+  }
+  return fmt.Sprintf("%s/%d", hostname, os.Getpid())
+}
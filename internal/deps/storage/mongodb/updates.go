@@ -0,0 +1,53 @@
+package mongodb
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Updates набор изменений документа по путям полей.
+// Значения устанавливаются как есть, включая нулевые.
+type Updates struct {
+  set         bson.D
+  unset       bson.D
+  setOnInsert bson.D
+}
+
+func NewUpdates() *Updates {
+  return &Updates{}
+}
+
+// Set устанавливает значение поля по пути, например: timestamps.handled_at.
+func (u *Updates) Set(path string, value any) *Updates {
+  u.set = append(u.set, bson.E{Key: path, Value: value})
+  return u
+}
+
+// Unset удаляет поле по пути.
+func (u *Updates) Unset(path string) *Updates {
+  u.unset = append(u.unset, bson.E{Key: path, Value: ""})
+  return u
+}
+
+// SetOnInsert устанавливает значение поля только при вставке документа через Upsert.
+func (u *Updates) SetOnInsert(path string, value any) *Updates {
+  u.setOnInsert = append(u.setOnInsert, bson.E{Key: path, Value: value})
+  return u
+}
+
+func (u *Updates) IsEmpty() bool {
+  return u == nil || len(u.set) == 0 && len(u.unset) == 0 && len(u.setOnInsert) == 0
+}
+
+func (u *Updates) toBsonD() bson.D {
+  out := bson.D{}
+
+  if len(u.set) != 0 {
+    out = append(out, bson.E{Key: "$set", Value: u.set})
+  }
+  if len(u.unset) != 0 {
+    out = append(out, bson.E{Key: "$unset", Value: u.unset})
+  }
+  if len(u.setOnInsert) != 0 {
+    out = append(out, bson.E{Key: "$setOnInsert", Value: u.setOnInsert})
+  }
+
+  return out
+}
@@ -0,0 +1,45 @@
+package mongorepo
+
+import (
+  "errors"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+func castDocument[T any](value any) (*T, error) {
+  doc, ok := value.(*T)
+  if !ok {
+    return nil, fmt.Errorf("cast %v with type: %[1]T to: %T failed", value, new(T))
+  }
+  return doc, nil
+}
+
+func castDocuments[T any](values []any) ([]*T, error) {
+  docs := make([]*T, 0, len(values))
+
+  for _, value := range values {
+    doc, err := castDocument[T](value)
+    if err != nil {
+      return nil, err
+    }
+    docs = append(docs, doc)
+  }
+
+  return docs, nil
+}
+
+func wrapNotFound(err error) error {
+  if errors.Is(err, mongodb.ErrNotFound) {
+    return fmt.Errorf("%w: %w", models.ErrNotFound, err)
+  }
+  return err
+}
+
+func wrapAlreadyExists(err error) error {
+  if errors.Is(err, mongodb.ErrDuplicateKey) {
+    return fmt.Errorf("%w: %w", models.ErrAlreadyExists, err)
+  }
+  return err
+}
@@ -0,0 +1,117 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+  "go.mongodb.org/mongo-driver/bson"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DeepLinks struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *DeepLinks) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.DeepLinks,
+    StructType: models.DeepLink{},
+  }
+}
+
+// Upsert сохраняет ссылку. Повторное сохранение ссылки с тем же идентификатором продлевает ее.
+func (r *DeepLinks) Upsert(ctx context.Context, link models.DeepLink) error {
+  _, err := r.deps.Mongodb.Replace(ctx, mongodb.ReplaceParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "id": link.Id,
+      },
+    },
+    Document: link,
+    Upsert:   true,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Replace: %w", err)
+  }
+
+  return nil
+}
+
+// UpsertMany сохраняет ссылки одним запросом.
+func (r *DeepLinks) UpsertMany(ctx context.Context, links []models.DeepLink) error {
+  replaces := make([]mongodb.ReplaceManyModel, 0, len(links))
+
+  for _, link := range links {
+    replaces = append(replaces, mongodb.ReplaceManyModel{
+      Filters: map[string]any{
+        "id": link.Id,
+      },
+      Document: link,
+    })
+  }
+
+  _, err := r.deps.Mongodb.ReplaceMany(ctx, mongodb.ReplaceManyParams{
+    CommonParams: r.common(),
+    Replaces:     replaces,
+    Upsert:       true,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.ReplaceMany: %w", err)
+  }
+
+  return nil
+}
+
+func (r *DeepLinks) Get(ctx context.Context, id models.DeepLinkId) (*models.DeepLink, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "id": id,
+      // Истекшие документы удаляются ttl индексом с задержкой.
+      "expires_at": bson.D{{Key: "$gt", Value: time.Now()}},
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.DeepLink](res)
+}
+
+func (r *DeepLinks) ensureIndexes(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("deep_links_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  _, err = r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "expires_at",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("deep_links_ttl_index").SetExpireAfterSeconds(0),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
@@ -0,0 +1,29 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Issues struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Issues) Insert(ctx context.Context, issue models.Issue) error {
+  _, err := r.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
+    CommonParams: mongodb.CommonParams{
+      Database:   r.config.Database,
+      Collection: r.config.Collections.Issues,
+    },
+    Document: issue,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Insert: %w", err)
+  }
+
+  return nil
+}
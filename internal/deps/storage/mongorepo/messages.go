@@ -0,0 +1,129 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+  "go.mongodb.org/mongo-driver/bson"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Messages struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Messages) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.Messages,
+    StructType: models.SendableMessage{},
+  }
+}
+
+func (r *Messages) InsertIfNotExist(ctx context.Context, message models.SendableMessage) (bool, error) {
+  inserted, err := r.deps.Mongodb.InsertIfNotExist(ctx, mongodb.InsertIfNotExistParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "chat_id":     message.ChatId,
+        "text.sha256": message.Text.SHA256,
+      },
+    },
+    Document: message,
+  })
+  if err != nil {
+    return false, fmt.Errorf("r.deps.Mongodb.InsertIfNotExist: %w", err)
+  }
+
+  return inserted, nil
+}
+
+func (r *Messages) ScanUnsent(ctx context.Context, filter models.MessagesFilter, callback func(ctx context.Context, message *models.SendableMessage) error) error {
+  filters := map[string]any{
+    "type":    bson.D{{Key: "$in", Value: models.CronSendableTypes}},
+    "sent_id": nil,
+  }
+
+  if filter.ProductType != "" {
+    filters["product.type"] = filter.ProductType
+  }
+
+  err := r.deps.Mongodb.Scan(ctx, mongodb.ScanParams{
+    CommonParams: r.common(),
+    Filters:      filters,
+
+    Callback: func(ctx context.Context, value any) error {
+      message, err := castDocument[models.SendableMessage](value)
+      if err != nil {
+        return err
+      }
+      return callback(ctx, message)
+    },
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Scan: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Messages) Update(ctx context.Context, message *models.SendableMessage) error {
+  _, err := r.deps.Mongodb.Replace(ctx, mongodb.ReplaceParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "uuid": message.UUID,
+      },
+    },
+    Document: message,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Replace: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Messages) ensureUniqueIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "text.sha256",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("messages_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// dedupe удаляет дубликаты сообщений, оставляя отправленное.
+func (r *Messages) dedupe(ctx context.Context) (int64, error) {
+  count, err := r.deps.Mongodb.DeleteDuplicates(ctx, mongodb.DeleteDuplicatesParams{
+    CommonParams: r.common(),
+    Keys:         []string{"chat_id", "text.sha256"},
+    Sorting: []mongodb.SortParams{
+      {
+        Field: "timestamps.sent_at",
+        Order: mongodb.SortOrderDesc,
+      },
+    },
+  })
+  if err != nil {
+    return 0, fmt.Errorf("r.deps.Mongodb.DeleteDuplicates: %w", err)
+  }
+
+  return count, nil
+}
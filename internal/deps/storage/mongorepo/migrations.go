@@ -0,0 +1,179 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/go-playground/validator/v10"
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb/migrations"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type MigrateParams struct {
+  // Owner идентификатор экземпляра, выполняющего миграции. По умолчанию hostname/pid.
+  Owner  string
+  DryRun bool
+  // ProductKey определяет ключ товара по ссылке для заполнения ключей существующих отслеживаний.
+  ProductKey ProductKeyFunc `validate:"required"`
+}
+
+type ProductKeyFunc func(url models.ProductURL) (models.ProductKey, error)
+
+func (p *MigrateParams) Validate() error {
+  return validator.New().Struct(p)
+}
+
+// Migrate применяет миграции базы данных outfit.
+// Конкурентные запуски из нескольких приложений ожидают друг друга.
+func (r *Repositories) Migrate(ctx context.Context, params MigrateParams) error {
+  if err := params.Validate(); err != nil {
+    return fmt.Errorf("invalid params: %w", err)
+  }
+
+  migrator, err := migrations.NewMigrator(
+    migrations.Config{
+      Database:       r.config.Database,
+      Collection:     r.config.Collections.Migrations,
+      LockCollection: r.config.Collections.MigrationsLock,
+      Owner:          params.Owner,
+      DryRun:         params.DryRun,
+    },
+    migrations.Dependencies{
+      Mongodb: r.deps.Mongodb,
+    })
+  if err != nil {
+    return fmt.Errorf("migrations.NewMigrator: %w", err)
+  }
+
+  if err = migrator.Run(ctx, r.migrations(params)); err != nil {
+    return fmt.Errorf("migrator.Run: %w", err)
+  }
+
+  return nil
+}
+
+// migrations список миграций. Примененные миграции не изменяются, новые добавляются в конец.
+func (r *Repositories) migrations(params MigrateParams) []migrations.Migration {
+  return []migrations.Migration{
+    {
+      Version:     1,
+      Description: "delete duplicated trackings, sessions and messages",
+      Up:          r.dedupe,
+    },
+    {
+      Version:     2,
+      Description: "create unique indexes for trackings, sessions and messages",
+      Up: func(ctx context.Context) error {
+        if err := r.Trackings.ensureUniqueIndex(ctx); err != nil {
+          return fmt.Errorf("r.Trackings.ensureUniqueIndex: %w", err)
+        }
+        if err := r.Sessions.ensureUniqueIndex(ctx); err != nil {
+          return fmt.Errorf("r.Sessions.ensureUniqueIndex: %w", err)
+        }
+        if err := r.Messages.ensureUniqueIndex(ctx); err != nil {
+          return fmt.Errorf("r.Messages.ensureUniqueIndex: %w", err)
+        }
+        return nil
+      },
+    },
+    {
+      Version:     3,
+      Description: "rebuild trackings text index with url and comment fields",
+      Up: func(ctx context.Context) error {
+        if err := r.Trackings.dropTextIndex(ctx); err != nil {
+          return fmt.Errorf("r.Trackings.dropTextIndex: %w", err)
+        }
+        if err := r.Trackings.ensureTextIndex(ctx); err != nil {
+          return fmt.Errorf("r.Trackings.ensureTextIndex: %w", err)
+        }
+        return nil
+      },
+    },
+    {
+      Version:     4,
+      Description: "create trackings index for scan by timestamps.handled_at",
+      Up:          r.Trackings.ensureHandledAtIndex,
+    },
+    {
+      Version:     5,
+      Description: "create sliders unique and ttl indexes",
+      Up:          r.Sliders.ensureIndexes,
+    },
+    {
+      Version:     6,
+      Description: "create deep links unique and ttl indexes",
+      Up:          r.DeepLinks.ensureIndexes,
+    },
+    {
+      Version:     7,
+      Description: "backfill trackings product key and create product key index",
+      Up: func(ctx context.Context) error {
+        count, err := r.Trackings.backfillProductKeys(ctx, params.ProductKey)
+        if err != nil {
+          return fmt.Errorf("r.Trackings.backfillProductKeys: %w", err)
+        }
+
+        log.
+          WithField("trackings.updated", count).
+          Info("trackings product keys backfilled")
+
+        if err = r.Trackings.ensureProductKeyIndex(ctx); err != nil {
+          return fmt.Errorf("r.Trackings.ensureProductKeyIndex: %w", err)
+        }
+        return nil
+      },
+    },
+    {
+      Version:     8,
+      Description: "create trackings ttl index for purging the trash",
+      Up:          r.Trackings.ensurePurgeIndex,
+    },
+    {
+      Version:     9,
+      Description: "backfill trackings id and create tracking id index",
+      Up: func(ctx context.Context) error {
+        count, err := r.Trackings.backfillTrackingIds(ctx)
+        if err != nil {
+          return fmt.Errorf("r.Trackings.backfillTrackingIds: %w", err)
+        }
+
+        log.
+          WithField("trackings.updated", count).
+          Info("trackings ids backfilled")
+
+        if err = r.Trackings.ensureTrackingIdIndex(ctx); err != nil {
+          return fmt.Errorf("r.Trackings.ensureTrackingIdIndex: %w", err)
+        }
+        return nil
+      },
+    },
+  }
+}
+
+func (r *Repositories) dedupe(ctx context.Context) error {
+  trackings, err := r.Trackings.dedupe(ctx)
+  if err != nil {
+    return fmt.Errorf("r.Trackings.dedupe: %w", err)
+  }
+
+  sessions, err := r.Sessions.dedupe(ctx)
+  if err != nil {
+    return fmt.Errorf("r.Sessions.dedupe: %w", err)
+  }
+
+  messages, err := r.Messages.dedupe(ctx)
+  if err != nil {
+    return fmt.Errorf("r.Messages.dedupe: %w", err)
+  }
+
+  log.
+    WithFields(log.Fields{
+      "trackings.deleted": trackings,
+      "sessions.deleted":  sessions,
+      "messages.deleted":  messages,
+    }).
+    Info("mongodb collections deduplicated")
+
+  return nil
+}
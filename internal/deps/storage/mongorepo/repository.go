@@ -0,0 +1,82 @@
+package mongorepo
+
+import (
+  "fmt"
+
+  "github.com/go-playground/validator/v10"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Repositories struct {
+  Trackings *Trackings
+  Sessions  *Sessions
+  Messages  *Messages
+  Issues    *Issues
+  Runs      *Runs
+  Sliders   *Sliders
+  DeepLinks *DeepLinks
+
+  config Config
+  deps   Dependencies
+}
+
+type Config struct {
+  Database    string `validate:"required"`
+  Collections Collections
+}
+
+type Collections struct {
+  Trackings      string `validate:"required"`
+  Sessions       string `validate:"required"`
+  Messages       string `validate:"required"`
+  Issues         string `validate:"required"`
+  Runs           string `validate:"required"`
+  Migrations     string `validate:"required"`
+  MigrationsLock string `validate:"required"`
+  Sliders        string `validate:"required"`
+  DeepLinks      string `validate:"required"`
+}
+
+func (c *Config) Validate() error {
+  return validator.New().Struct(c)
+}
+
+type Dependencies struct {
+  Mongodb *mongodb.Client `validate:"required"`
+}
+
+func (c *Dependencies) Validate() error {
+  return validator.New().Struct(c)
+}
+
+func New(config Config, deps Dependencies) (*Repositories, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+  if err := deps.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid dependencies: %w", err)
+  }
+
+  return &Repositories{
+    Trackings: &Trackings{config: config, deps: deps},
+    Sessions:  &Sessions{config: config, deps: deps},
+    Messages:  &Messages{config: config, deps: deps},
+    Issues:    &Issues{config: config, deps: deps},
+    Runs:      &Runs{config: config, deps: deps},
+    Sliders:   &Sliders{config: config, deps: deps},
+    DeepLinks: &DeepLinks{config: config, deps: deps},
+    config:    config,
+    deps:      deps,
+  }, nil
+}
+
+var (
+  _ models.TrackingsRepository = (*Trackings)(nil)
+  _ models.SessionsRepository  = (*Sessions)(nil)
+  _ models.MessagesRepository  = (*Messages)(nil)
+  _ models.IssuesRepository    = (*Issues)(nil)
+  _ models.RunsRepository      = (*Runs)(nil)
+  _ models.SlidersRepository   = (*Sliders)(nil)
+  _ models.DeepLinksRepository = (*DeepLinks)(nil)
+)
@@ -0,0 +1,80 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+)
+
+type Runs struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Runs) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.Runs,
+    StructType: models.CronRun{},
+  }
+}
+
+func (r *Runs) Insert(ctx context.Context, run models.CronRun) error {
+  _, err := r.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
+    CommonParams: r.common(),
+    Document:     run,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Insert: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Runs) Update(ctx context.Context, run *models.CronRun) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "uuid": run.UUID,
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Set("status", run.Status).
+      Set("error", run.Error).
+      Set("timestamps.finished_at", run.Timestamps.FinishedAt),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Runs) FindLast(ctx context.Context, name models.CronName, typ models.ProductType) (*models.CronRun, error) {
+  res, err := r.deps.Mongodb.Find(ctx, mongodb.FindParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "name":         name,
+      "product_type": typ,
+    },
+    Sorting: []mongodb.SortParams{
+      {
+        Field: "timestamps.started_at",
+        Order: mongodb.SortOrderDesc,
+      },
+    },
+    Limit: 1,
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Find: %w", err)
+  }
+
+  if len(res) == 0 {
+    return nil, models.ErrNotFound
+  }
+
+  return castDocument[models.CronRun](res[0])
+}
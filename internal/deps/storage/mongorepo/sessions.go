@@ -0,0 +1,92 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Sessions struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Sessions) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.Sessions,
+    StructType: models.Session{},
+  }
+}
+
+func (r *Sessions) Get(ctx context.Context, chatId models.ChatId) (*models.Session, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id": chatId,
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Session](res)
+}
+
+func (r *Sessions) Upsert(ctx context.Context, session models.Session) error {
+  _, err := r.deps.Mongodb.Replace(ctx, mongodb.ReplaceParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "chat_id": session.ChatId,
+      },
+    },
+    Document: session,
+    Upsert:   true,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Replace: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Sessions) ensureUniqueIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("sessions_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// dedupe удаляет дубликаты сессий, оставляя последнюю обновленную.
+func (r *Sessions) dedupe(ctx context.Context) (int64, error) {
+  count, err := r.deps.Mongodb.DeleteDuplicates(ctx, mongodb.DeleteDuplicatesParams{
+    CommonParams: r.common(),
+    Keys:         []string{"chat_id"},
+    Sorting: []mongodb.SortParams{
+      {
+        Field: "updated_at",
+        Order: mongodb.SortOrderDesc,
+      },
+    },
+  })
+  if err != nil {
+    return 0, fmt.Errorf("r.deps.Mongodb.DeleteDuplicates: %w", err)
+  }
+
+  return count, nil
+}
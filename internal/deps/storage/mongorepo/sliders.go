@@ -0,0 +1,90 @@
+package mongorepo
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+  "go.mongodb.org/mongo-driver/bson"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Sliders struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Sliders) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.Sliders,
+    StructType: models.Slider{},
+  }
+}
+
+func (r *Sliders) Insert(ctx context.Context, slider models.Slider) error {
+  _, err := r.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
+    CommonParams: r.common(),
+    Document:     slider,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Insert: %w", wrapAlreadyExists(err))
+  }
+
+  return nil
+}
+
+func (r *Sliders) Get(ctx context.Context, chatId models.ChatId, messageId int) (*models.Slider, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id":    chatId,
+      "message_id": messageId,
+      // Истекшие документы удаляются ttl индексом с задержкой.
+      "expires_at": bson.D{{Key: "$gt", Value: time.Now()}},
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Slider](res)
+}
+
+func (r *Sliders) ensureIndexes(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "message_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("sliders_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  _, err = r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "expires_at",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("sliders_ttl_index").SetExpireAfterSeconds(0),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
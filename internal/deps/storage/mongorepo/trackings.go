@@ -0,0 +1,718 @@
+package mongorepo
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "time"
+
+  log "github.com/sirupsen/logrus"
+  "github.com/ushakovn/outfit/internal/deps/storage/mongodb"
+  "github.com/ushakovn/outfit/internal/models"
+  "go.mongodb.org/mongo-driver/bson"
+  mongodbopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Trackings struct {
+  config Config
+  deps   Dependencies
+}
+
+func (r *Trackings) common() mongodb.CommonParams {
+  return mongodb.CommonParams{
+    Database:   r.config.Database,
+    Collection: r.config.Collections.Trackings,
+    StructType: models.Tracking{},
+  }
+}
+
+func (r *Trackings) Get(ctx context.Context, chatId models.ChatId, url models.ProductURL) (*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "url":                   url,
+      "chat_id":               chatId,
+      "timestamps.deleted_at": nil,
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Tracking](res)
+}
+
+func (r *Trackings) GetByProductKey(ctx context.Context, chatId models.ChatId, key models.ProductKey) (*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "product_key":           key,
+      "chat_id":               chatId,
+      "timestamps.deleted_at": nil,
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Tracking](res)
+}
+
+func (r *Trackings) GetById(ctx context.Context, chatId models.ChatId, id models.TrackingId) (*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "tracking_id":           id,
+      "chat_id":               chatId,
+      "timestamps.deleted_at": nil,
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Tracking](res)
+}
+
+func (r *Trackings) List(ctx context.Context, chatId models.ChatId, limit int64) ([]*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Find(ctx, mongodb.FindParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id":               chatId,
+      "timestamps.deleted_at": nil,
+    },
+    Limit: limit,
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Find: %w", err)
+  }
+
+  return castDocuments[models.Tracking](res)
+}
+
+func (r *Trackings) Search(ctx context.Context, chatId models.ChatId, query string, limit int64) ([]*models.Tracking, error) {
+  res, err := r.deps.Mongodb.TextSearch(ctx, mongodb.TextSearchParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id":               chatId,
+      "timestamps.deleted_at": nil,
+    },
+    Query: query,
+    Limit: limit,
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.TextSearch: %w", err)
+  }
+
+  return castDocuments[models.Tracking](res)
+}
+
+func (r *Trackings) Claim(ctx context.Context, params models.ClaimTrackingsParams) ([]*models.Tracking, error) {
+  list := make([]*models.Tracking, 0, params.Limit)
+
+  for len(list) < params.Limit {
+    now := time.Now()
+
+    filters := makeClaimFilters(params, now)
+
+    res, err := r.deps.Mongodb.FindOneAndUpdate(ctx, mongodb.FindOneAndUpdateParams{
+      GetParams: mongodb.GetParams{
+        CommonParams: r.common(),
+        Filters:      filters,
+      },
+      Sorting: []mongodb.SortParams{
+        {
+          Field: "timestamps.handled_at",
+          Order: mongodb.SortOrderAsc,
+        },
+      },
+      Updates: mongodb.NewUpdates().
+        Set("lease", models.TrackingLease{
+          Owner:     params.Owner,
+          ExpiresAt: now.Add(params.TTL),
+        }),
+    })
+    if err != nil {
+      if errors.Is(err, mongodb.ErrNotFound) {
+        break
+      }
+      return nil, fmt.Errorf("r.deps.Mongodb.FindOneAndUpdate: %w", err)
+    }
+
+    tracking, err := castDocument[models.Tracking](res)
+    if err != nil {
+      return nil, err
+    }
+
+    list = append(list, tracking)
+  }
+
+  return list, nil
+}
+
+func makeClaimFilters(params models.ClaimTrackingsParams, now time.Time) map[string]any {
+  conditions := bson.A{
+    bson.D{{Key: "$or", Value: bson.A{
+      bson.D{{Key: "lease", Value: nil}},
+      bson.D{{Key: "lease.expires_at", Value: bson.D{{Key: "$lt", Value: now}}}},
+    }}},
+    bson.D{{Key: "$or", Value: bson.A{
+      bson.D{{Key: "timestamps.handled_at", Value: nil}},
+      bson.D{{Key: "timestamps.handled_at", Value: bson.D{{Key: "$lt", Value: params.HandledBefore}}}},
+    }}},
+    bson.D{{Key: "timestamps.deleted_at", Value: nil}},
+  }
+
+  // Отслеживания без поля pause созданы до появления паузы и считаются активными.
+  if params.Filter.ExcludePaused {
+    conditions = append(conditions,
+      bson.D{{Key: "pause.paused", Value: bson.D{{Key: "$ne", Value: true}}}},
+      bson.D{{Key: "$or", Value: bson.A{
+        bson.D{{Key: "pause.snoozed_until", Value: nil}},
+        bson.D{{Key: "pause.snoozed_until", Value: bson.D{{Key: "$lte", Value: now}}}},
+      }}},
+    )
+  }
+
+  if params.Filter.ExcludeArchived {
+    conditions = append(conditions, bson.D{{Key: "archive", Value: nil}})
+  }
+
+  filters := map[string]any{
+    "$and": conditions,
+  }
+
+  typeFilters := bson.D{}
+
+  if params.Filter.ProductType != "" {
+    typeFilters = append(typeFilters, bson.E{Key: "$eq", Value: params.Filter.ProductType})
+  }
+  if len(params.Filter.ExcludeProductTypes) != 0 {
+    typeFilters = append(typeFilters, bson.E{Key: "$nin", Value: params.Filter.ExcludeProductTypes})
+  }
+  if len(typeFilters) != 0 {
+    filters["parsed_product.type"] = typeFilters
+  }
+
+  return filters
+}
+
+func (r *Trackings) Release(ctx context.Context, params models.ReleaseTrackingParams) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "chat_id":     params.ChatId,
+        "url":         params.URL,
+        "lease.owner": params.Owner,
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Set("timestamps.handled_at", params.HandledAt).
+      Unset("lease"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) Insert(ctx context.Context, tracking models.Tracking) error {
+  // Отслеживание той же ссылки из корзины заменяется новым, иначе вставка нарушит уникальный индекс.
+  _, err := r.deps.Mongodb.Delete(ctx, mongodb.DeleteParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id":               tracking.ChatId,
+      "url":                   tracking.URL,
+      "timestamps.deleted_at": bson.D{{Key: "$ne", Value: nil}},
+    },
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Delete: %w", err)
+  }
+
+  tracking.TrackingId = tracking.Id()
+
+  _, err = r.deps.Mongodb.Insert(ctx, mongodb.InsertParams{
+    CommonParams: r.common(),
+    Document:     tracking,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Insert: %w", wrapAlreadyExists(err))
+  }
+
+  return nil
+}
+
+// activeTrackingFilters фильтры отслеживания вне корзины. Отслеживания из корзины не изменяются, пока не будут восстановлены.
+func activeTrackingFilters(chatId models.ChatId, url models.ProductURL) map[string]any {
+  return map[string]any{
+    "chat_id":               chatId,
+    "url":                   url,
+    "timestamps.deleted_at": nil,
+  }
+}
+
+func (r *Trackings) Update(ctx context.Context, tracking *models.Tracking) error {
+  tracking.TrackingId = tracking.Id()
+
+  _, err := r.deps.Mongodb.Replace(ctx, mongodb.ReplaceParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Document: tracking,
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Replace: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) UpdateSettings(ctx context.Context, tracking *models.Tracking) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Updates: mongodb.NewUpdates().
+      Set("sizes", tracking.Sizes).
+      Set("flags", tracking.Flags).
+      Set("comment", tracking.Comment),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) UpdatePause(ctx context.Context, tracking *models.Tracking) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Updates: mongodb.NewUpdates().
+      Set("pause", tracking.Pause),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) UpdateArchive(ctx context.Context, tracking *models.Tracking) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Updates: mongodb.NewUpdates().
+      Set("archive", tracking.Archive).
+      Set("timestamps.failed_since", tracking.Timestamps.FailedSince).
+      Set("timestamps.reactivated_at", tracking.Timestamps.ReactivatedAt),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) UpdateExpiry(ctx context.Context, tracking *models.Tracking) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Updates: mongodb.NewUpdates().
+      Set("timestamps.expires_at", tracking.Timestamps.ExpiresAt),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) UpdateParsed(ctx context.Context, tracking *models.Tracking) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters:      activeTrackingFilters(tracking.ChatId, tracking.URL),
+    },
+    Updates: mongodb.NewUpdates().
+      Set("parsed_product", tracking.ParsedProduct).
+      Set("timestamps.handled_at", tracking.Timestamps.HandledAt),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) Delete(ctx context.Context, params models.DeleteTrackingParams) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "chat_id":               params.ChatId,
+        "url":                   params.URL,
+        "timestamps.deleted_at": nil,
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Set("timestamps.deleted_at", params.DeletedAt).
+      Set("timestamps.purge_at", params.PurgeAt).
+      Unset("lease"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) Restore(ctx context.Context, chatId models.ChatId, url models.ProductURL) error {
+  _, err := r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+    GetParams: mongodb.GetParams{
+      CommonParams: r.common(),
+      Filters: map[string]any{
+        "chat_id":               chatId,
+        "url":                   url,
+        "timestamps.deleted_at": bson.D{{Key: "$ne", Value: nil}},
+        // Ttl индекс удаляет документы с задержкой, поэтому истекшие отслеживания не восстанавливаются.
+        "timestamps.purge_at": bson.D{{Key: "$gt", Value: time.Now()}},
+      },
+    },
+    Updates: mongodb.NewUpdates().
+      Unset("timestamps.deleted_at").
+      Unset("timestamps.purge_at"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+  }
+
+  return nil
+}
+
+// ListDeleted возвращает отслеживания из корзины. Ttl индекс удаляет документы с задержкой,
+// поэтому отслеживания с истекшим сроком хранения отфильтровываются явно.
+func (r *Trackings) ListDeleted(ctx context.Context, chatId models.ChatId, limit int64) ([]*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Find(ctx, mongodb.FindParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "chat_id":               chatId,
+      "timestamps.deleted_at": bson.D{{Key: "$ne", Value: nil}},
+      "timestamps.purge_at":   bson.D{{Key: "$gt", Value: time.Now()}},
+    },
+    Sorting: []mongodb.SortParams{
+      {
+        Field: "timestamps.deleted_at",
+        Order: mongodb.SortOrderDesc,
+      },
+    },
+    Limit: limit,
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Find: %w", err)
+  }
+
+  return castDocuments[models.Tracking](res)
+}
+
+func (r *Trackings) GetDeletedById(ctx context.Context, chatId models.ChatId, id models.TrackingId) (*models.Tracking, error) {
+  res, err := r.deps.Mongodb.Get(ctx, mongodb.GetParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "tracking_id":           id,
+      "chat_id":               chatId,
+      "timestamps.deleted_at": bson.D{{Key: "$ne", Value: nil}},
+      "timestamps.purge_at":   bson.D{{Key: "$gt", Value: time.Now()}},
+    },
+  })
+  if err != nil {
+    return nil, fmt.Errorf("r.deps.Mongodb.Get: %w", wrapNotFound(err))
+  }
+
+  return castDocument[models.Tracking](res)
+}
+
+func (r *Trackings) ensureUniqueIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "url",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_unique_index").SetUnique(true),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) ensureTextIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "parsed_product.brand",
+        Type:  mongodb.IndexTypeText,
+      },
+      {
+        Field: "parsed_product.category",
+        Type:  mongodb.IndexTypeText,
+      },
+      {
+        Field: "parsed_product.description",
+        Type:  mongodb.IndexTypeText,
+      },
+      {
+        Field: "url",
+        Type:  mongodb.IndexTypeText,
+      },
+      {
+        Field: "comment",
+        Type:  mongodb.IndexTypeText,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_text_index"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) dropTextIndex(ctx context.Context) error {
+  err := r.deps.Mongodb.DropIndex(ctx, mongodb.DropIndexParams{
+    CommonParams: r.common(),
+    Name:         "trackings_text_index",
+  })
+  if err != nil && !errors.Is(err, mongodb.ErrNotFound) {
+    return fmt.Errorf("r.deps.Mongodb.DropIndex: %w", err)
+  }
+
+  return nil
+}
+
+func (r *Trackings) ensureHandledAtIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "parsed_product.type",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "timestamps.handled_at",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_handled_at_index"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// ensurePurgeIndex создает ttl индекс, удаляющий отслеживания из корзины после истечения срока хранения.
+// Документы без времени удаления индексом не затрагиваются.
+func (r *Trackings) ensurePurgeIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "timestamps.purge_at",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_purge_ttl_index").SetExpireAfterSeconds(0),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// ensureTrackingIdIndex создает индекс поиска отслеживания по идентификатору из кнопок.
+func (r *Trackings) ensureTrackingIdIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "tracking_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_tracking_id_index"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// backfillTrackingIds заполняет идентификатор у отслеживаний, созданных до его сохранения.
+func (r *Trackings) backfillTrackingIds(ctx context.Context) (int64, error) {
+  var count int64
+
+  err := r.deps.Mongodb.Scan(ctx, mongodb.ScanParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "tracking_id": bson.D{{Key: "$in", Value: bson.A{nil, ""}}},
+    },
+    Callback: func(ctx context.Context, value any) error {
+      tracking, err := castDocument[models.Tracking](value)
+      if err != nil {
+        return err
+      }
+
+      _, err = r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+        GetParams: mongodb.GetParams{
+          CommonParams: r.common(),
+          Filters: map[string]any{
+            "chat_id": tracking.ChatId,
+            "url":     tracking.URL,
+          },
+        },
+        Updates: mongodb.NewUpdates().
+          Set("tracking_id", tracking.Id()),
+      })
+      if err != nil {
+        return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+      }
+      count++
+
+      return nil
+    },
+  })
+  if err != nil {
+    return 0, fmt.Errorf("r.deps.Mongodb.Scan: %w", err)
+  }
+
+  return count, nil
+}
+
+// ensureProductKeyIndex создает индекс поиска дубликатов по ключу товара.
+// Индекс не уникальный: дубликаты, созданные до появления ключа, не удаляются без ведома пользователя.
+func (r *Trackings) ensureProductKeyIndex(ctx context.Context) error {
+  _, err := r.deps.Mongodb.CreateIndex(ctx, mongodb.CreateIndexParams{
+    CommonParams: r.common(),
+    Parts: []mongodb.IndexPart{
+      {
+        Field: "chat_id",
+        Type:  mongodb.IndexTypeAsc,
+      },
+      {
+        Field: "product_key",
+        Type:  mongodb.IndexTypeAsc,
+      },
+    },
+    Options: mongodbopts.Index().SetName("trackings_product_key_index"),
+  })
+  if err != nil {
+    return fmt.Errorf("r.deps.Mongodb.CreateIndex: %w", err)
+  }
+
+  return nil
+}
+
+// backfillProductKeys заполняет ключ товара у отслеживаний, созданных до его появления.
+// Отслеживания, для ссылок которых ключ не определяется, остаются без ключа.
+func (r *Trackings) backfillProductKeys(ctx context.Context, productKey ProductKeyFunc) (int64, error) {
+  var count int64
+
+  err := r.deps.Mongodb.Scan(ctx, mongodb.ScanParams{
+    CommonParams: r.common(),
+    Filters: map[string]any{
+      "product_key": bson.D{{Key: "$in", Value: bson.A{nil, ""}}},
+    },
+    Callback: func(ctx context.Context, value any) error {
+      tracking, err := castDocument[models.Tracking](value)
+      if err != nil {
+        return err
+      }
+
+      key, err := productKey(tracking.URL)
+      if err != nil {
+        log.
+          WithField("tracking.chat_id", tracking.ChatId).
+          WithField("tracking.url", tracking.URL).
+          Warnf("product key not found: %v", err)
+
+        return nil
+      }
+
+      _, err = r.deps.Mongodb.Update(ctx, mongodb.UpdateParams{
+        GetParams: mongodb.GetParams{
+          CommonParams: r.common(),
+          Filters: map[string]any{
+            "chat_id": tracking.ChatId,
+            "url":     tracking.URL,
+          },
+        },
+        Updates: mongodb.NewUpdates().
+          Set("product_key", key).
+          Set("parsed_product.key", key),
+      })
+      if err != nil {
+        return fmt.Errorf("r.deps.Mongodb.Update: %w", err)
+      }
+      count++
+
+      return nil
+    },
+  })
+  if err != nil {
+    return 0, fmt.Errorf("r.deps.Mongodb.Scan: %w", err)
+  }
+
+  return count, nil
+}
+
+// dedupe удаляет дубликаты отслеживаний, оставляя последнее обработанное.
+func (r *Trackings) dedupe(ctx context.Context) (int64, error) {
+  count, err := r.deps.Mongodb.DeleteDuplicates(ctx, mongodb.DeleteDuplicatesParams{
+    CommonParams: r.common(),
+    Keys:         []string{"chat_id", "url"},
+    Sorting: []mongodb.SortParams{
+      {
+        Field: "timestamps.handled_at",
+        Order: mongodb.SortOrderDesc,
+      },
+      {
+        Field: "timestamps.created_at",
+        Order: mongodb.SortOrderDesc,
+      },
+    },
+  })
+  if err != nil {
+    return 0, fmt.Errorf("r.deps.Mongodb.DeleteDuplicates: %w", err)
+  }
+
+  return count, nil
+}
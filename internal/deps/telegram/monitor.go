@@ -0,0 +1,62 @@
+package telegram
+
+import (
+  "fmt"
+  "net/http"
+  "strings"
+  "time"
+
+  tgbot "github.com/go-telegram/bot"
+  "go.uber.org/atomic"
+)
+
+// Monitor фиксирует время последнего успешного запроса getUpdates.
+// Long polling отвечает не реже раза в defaultPollTimeout, поэтому
+// отсутствие ответов дольше нескольких таймаутов означает зависание бота.
+type Monitor struct {
+  lastPollAt atomic.Int64
+}
+
+func NewMonitor() *Monitor {
+  m := new(Monitor)
+  m.lastPollAt.Store(time.Now().UnixNano())
+
+  return m
+}
+
+func (m *Monitor) LastPollAt() time.Time {
+  return time.Unix(0, m.lastPollAt.Load())
+}
+
+func (m *Monitor) Check(maxDelay time.Duration) error {
+  if delay := time.Since(m.LastPollAt()); delay > maxDelay {
+    return fmt.Errorf("last successful updates poll was %s ago", delay.Truncate(time.Second))
+  }
+  return nil
+}
+
+func (m *Monitor) DefaultMaxDelay() time.Duration {
+  return 3 * defaultPollTimeout
+}
+
+func (m *Monitor) wrap(client tgbot.HttpClient) tgbot.HttpClient {
+  return &monitoredClient{
+    client:  client,
+    monitor: m,
+  }
+}
+
+type monitoredClient struct {
+  client  tgbot.HttpClient
+  monitor *Monitor
+}
+
+func (c *monitoredClient) Do(req *http.Request) (*http.Response, error) {
+  resp, err := c.client.Do(req)
+
+  if err == nil && resp.StatusCode == http.StatusOK && strings.HasSuffix(req.URL.Path, "/getUpdates") {
+    c.monitor.lastPollAt.Store(time.Now().UnixNano())
+  }
+
+  return resp, err
+}
@@ -2,17 +2,40 @@ package telegram
 
 import (
   "fmt"
+  "net/http"
+  "time"
 
   tgbot "github.com/go-telegram/bot"
   log "github.com/sirupsen/logrus"
 )
 
+const defaultPollTimeout = time.Minute
+
 type Config struct {
-  Token string
+  Token       string
+  Monitor     *Monitor
+  Middlewares []tgbot.Middleware
+  // Webhook обновления принимаются через Webhook, который сам управляет выполнением обработчиков.
+  Webhook bool
 }
 
 func NewBotClient(config Config) (*tgbot.Bot, error) {
-  bot, err := tgbot.New(config.Token)
+  var opts []tgbot.Option
+
+  if config.Monitor != nil {
+    client := &http.Client{Timeout: defaultPollTimeout}
+    opts = append(opts, tgbot.WithHTTPClient(defaultPollTimeout, config.Monitor.wrap(client)))
+  }
+
+  if config.Webhook {
+    opts = append(opts, tgbot.WithNotAsyncHandlers())
+  }
+
+  if len(config.Middlewares) != 0 {
+    opts = append(opts, tgbot.WithMiddlewares(config.Middlewares...))
+  }
+
+  bot, err := tgbot.New(config.Token, opts...)
   if err != nil {
     return nil, fmt.Errorf("tgbot.New: %w", err)
   }
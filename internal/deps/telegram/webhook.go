@@ -0,0 +1,211 @@
+package telegram
+
+import (
+  "context"
+  "crypto/subtle"
+  "encoding/json"
+  "fmt"
+  "net"
+  "net/http"
+  "net/url"
+  "strconv"
+  "sync"
+  "time"
+
+  "github.com/go-playground/validator/v10"
+  tgbot "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+  log "github.com/sirupsen/logrus"
+)
+
+const (
+  secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+  defaultWebhookReadTimeout = 10 * time.Second
+  defaultWebhookStopTimeout = 5 * time.Second
+)
+
+// Webhook http сервер, принимающий обновления telegram.
+// Бот должен быть создан с Config.Webhook, чтобы обработчики выполнялись под контролем сервера.
+type Webhook struct {
+  config WebhookConfig
+  bot    *tgbot.Bot
+  server *http.Server
+
+  // handlersCtx не отменяется вместе с контекстом приложения, чтобы обработчики завершились при остановке.
+  handlersCtx    context.Context
+  cancelHandlers context.CancelFunc
+  inflight       sync.WaitGroup
+}
+
+type WebhookConfig struct {
+  // URL публичный адрес, который регистрируется в telegram. Путь адреса обслуживается сервером.
+  URL         string `validate:"required,url"`
+  Port        int    `validate:"required"`
+  SecretToken string `validate:"required,max=256"`
+  // MaxConnections максимальное количество одновременных запросов от telegram.
+  MaxConnections int
+  // DrainTimeout время ожидания выполняющихся обработчиков при остановке.
+  DrainTimeout time.Duration `validate:"gt=0"`
+  // DeleteOnStop удалять webhook при остановке.
+  // Не следует включать для нескольких реплик: остановка одной отключит остальные.
+  DeleteOnStop bool
+}
+
+func (c *WebhookConfig) Validate() error {
+  return validator.New().Struct(c)
+}
+
+func NewWebhook(config WebhookConfig, bot *tgbot.Bot) (*Webhook, error) {
+  if err := config.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid config: %w", err)
+  }
+
+  parsed, err := url.Parse(config.URL)
+  if err != nil {
+    return nil, fmt.Errorf("url.Parse: %w", err)
+  }
+
+  path := parsed.Path
+  if path == "" {
+    path = "/"
+  }
+
+  w := &Webhook{
+    config: config,
+    bot:    bot,
+  }
+
+  mux := http.NewServeMux()
+  mux.HandleFunc(path, w.handleUpdate)
+
+  w.server = &http.Server{
+    Addr:              net.JoinHostPort("", strconv.Itoa(config.Port)),
+    Handler:           mux,
+    ReadHeaderTimeout: defaultWebhookReadTimeout,
+  }
+
+  return w, nil
+}
+
+// Start запускает http сервер и регистрирует webhook в telegram.
+func (w *Webhook) Start(ctx context.Context) error {
+  w.handlersCtx, w.cancelHandlers = context.WithCancel(context.WithoutCancel(ctx))
+
+  listener, err := net.Listen("tcp", w.server.Addr)
+  if err != nil {
+    return fmt.Errorf("net.Listen: %w", err)
+  }
+
+  go func() {
+    if err := w.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+      log.Errorf("telegram.Webhook: w.server.Serve: %v", err)
+    }
+  }()
+
+  _, err = w.bot.SetWebhook(ctx, &tgbot.SetWebhookParams{
+    URL:            w.config.URL,
+    MaxConnections: w.config.MaxConnections,
+    SecretToken:    w.config.SecretToken,
+  })
+  if err != nil {
+    return fmt.Errorf("w.bot.SetWebhook: %w", err)
+  }
+
+  log.
+    WithField("webhook.addr", w.server.Addr).
+    Info("telegram webhook started")
+
+  return nil
+}
+
+// Stop прекращает прием обновлений и ожидает выполняющиеся обработчики не дольше DrainTimeout.
+func (w *Webhook) Stop() error {
+  ctx, cancel := context.WithTimeout(context.Background(), defaultWebhookStopTimeout)
+  defer cancel()
+
+  if err := w.server.Shutdown(ctx); err != nil {
+    log.Errorf("telegram.Webhook: w.server.Shutdown: %v", err)
+  }
+
+  drained := make(chan struct{})
+
+  go func() {
+    w.inflight.Wait()
+    close(drained)
+  }()
+
+  select {
+  case <-drained:
+    log.Info("telegram webhook handlers drained")
+
+  case <-time.After(w.config.DrainTimeout):
+    log.Warn("telegram webhook drain timeout exceeded. handlers cancelled")
+  }
+
+  w.cancelHandlers()
+
+  if !w.config.DeleteOnStop {
+    return nil
+  }
+
+  ctx, cancel = context.WithTimeout(context.Background(), defaultWebhookStopTimeout)
+  defer cancel()
+
+  if _, err := w.bot.DeleteWebhook(ctx, &tgbot.DeleteWebhookParams{}); err != nil {
+    return fmt.Errorf("w.bot.DeleteWebhook: %w", err)
+  }
+
+  return nil
+}
+
+// Check проверяет, что в telegram зарегистрирован webhook этого приложения.
+func (w *Webhook) Check(ctx context.Context) error {
+  info, err := w.bot.GetWebhookInfo(ctx)
+  if err != nil {
+    return fmt.Errorf("w.bot.GetWebhookInfo: %w", err)
+  }
+
+  if info.URL != w.config.URL {
+    return fmt.Errorf("registered webhook url mismatch: %q", info.URL)
+  }
+
+  return nil
+}
+
+func (w *Webhook) handleUpdate(rw http.ResponseWriter, req *http.Request) {
+  if req.Method != http.MethodPost {
+    rw.WriteHeader(http.StatusMethodNotAllowed)
+    return
+  }
+
+  token := req.Header.Get(secretTokenHeader)
+
+  if subtle.ConstantTimeCompare([]byte(token), []byte(w.config.SecretToken)) != 1 {
+    log.
+      WithField("remote_addr", req.RemoteAddr).
+      Warn("telegram webhook request with invalid secret token")
+
+    rw.WriteHeader(http.StatusUnauthorized)
+    return
+  }
+
+  update := new(tgmodels.Update)
+
+  if err := json.NewDecoder(req.Body).Decode(update); err != nil {
+    log.Errorf("telegram.Webhook: json.Decode: %v", err)
+
+    rw.WriteHeader(http.StatusBadRequest)
+    return
+  }
+
+  w.inflight.Add(1)
+
+  go func() {
+    defer w.inflight.Done()
+
+    w.bot.ProcessUpdate(w.handlersCtx, update)
+  }()
+
+  rw.WriteHeader(http.StatusOK)
+}
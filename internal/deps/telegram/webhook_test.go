@@ -0,0 +1,301 @@
+package telegram
+
+import (
+  "bytes"
+  "context"
+  "net"
+  "net/http"
+  "net/http/httptest"
+  "strconv"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+
+  tgbot "github.com/go-telegram/bot"
+  tgmodels "github.com/go-telegram/bot/models"
+)
+
+const (
+  testBotToken    = "123:token"
+  testSecretToken = "secret"
+)
+
+// fakeBotAPI имитирует Bot API и запоминает вызванные методы с параметрами.
+type fakeBotAPI struct {
+  server *httptest.Server
+
+  mu    sync.Mutex
+  calls []fakeBotAPICall
+}
+
+type fakeBotAPICall struct {
+  Method string
+  Params map[string]string
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+  api := &fakeBotAPI{}
+
+  api.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+    method := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+
+    params := map[string]string{}
+    if err := req.ParseMultipartForm(1 << 20); err == nil {
+      for key, values := range req.MultipartForm.Value {
+        params[key] = values[0]
+      }
+    }
+
+    api.mu.Lock()
+    api.calls = append(api.calls, fakeBotAPICall{Method: method, Params: params})
+    api.mu.Unlock()
+
+    rw.Header().Set("Content-Type", "application/json")
+    _, _ = rw.Write([]byte(`{"ok":true,"result":true}`))
+  }))
+  t.Cleanup(api.server.Close)
+
+  return api
+}
+
+func (a *fakeBotAPI) findCall(method string) (fakeBotAPICall, bool) {
+  a.mu.Lock()
+  defer a.mu.Unlock()
+
+  for _, call := range a.calls {
+    if call.Method == method {
+      return call, true
+    }
+  }
+  return fakeBotAPICall{}, false
+}
+
+type startWebhookParams struct {
+  Handler      tgbot.HandlerFunc
+  DrainTimeout time.Duration
+  DeleteOnStop bool
+}
+
+// startWebhook запускает webhook на свободном порту. Возвращает адрес, на который telegram отправляет обновления.
+func startWebhook(t *testing.T, api *fakeBotAPI, params startWebhookParams) (*Webhook, string) {
+  t.Helper()
+
+  bot, err := tgbot.New(testBotToken,
+    tgbot.WithServerURL(api.server.URL),
+    tgbot.WithSkipGetMe(),
+    tgbot.WithNotAsyncHandlers(),
+    tgbot.WithDefaultHandler(params.Handler),
+  )
+  if err != nil {
+    t.Fatalf("tgbot.New: %v", err)
+  }
+
+  port := findFreePort(t)
+  url := "http://127.0.0.1:" + strconv.Itoa(port) + "/telegram/webhook"
+
+  webhook, err := NewWebhook(WebhookConfig{
+    URL:          url,
+    Port:         port,
+    SecretToken:  testSecretToken,
+    DrainTimeout: params.DrainTimeout,
+    DeleteOnStop: params.DeleteOnStop,
+  }, bot)
+  if err != nil {
+    t.Fatalf("NewWebhook: %v", err)
+  }
+
+  if err = webhook.Start(context.Background()); err != nil {
+    t.Fatalf("webhook.Start: %v", err)
+  }
+
+  return webhook, url
+}
+
+func findFreePort(t *testing.T) int {
+  t.Helper()
+
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatalf("net.Listen: %v", err)
+  }
+  defer listener.Close()
+
+  return listener.Addr().(*net.TCPAddr).Port
+}
+
+func postUpdate(t *testing.T, url, token string) int {
+  t.Helper()
+
+  req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"update_id":1,"message":{"text":"hi","chat":{"id":1}}}`))
+  if err != nil {
+    t.Fatalf("http.NewRequest: %v", err)
+  }
+  if token != "" {
+    req.Header.Set(secretTokenHeader, token)
+  }
+
+  resp, err := http.DefaultClient.Do(req)
+  if err != nil {
+    t.Fatalf("http.DefaultClient.Do: %v", err)
+  }
+  defer resp.Body.Close()
+
+  return resp.StatusCode
+}
+
+func TestWebhookRegistersAndDeletes(t *testing.T) {
+  api := newFakeBotAPI(t)
+
+  webhook, url := startWebhook(t, api, startWebhookParams{
+    Handler:      func(ctx context.Context, bot *tgbot.Bot, update *tgmodels.Update) {},
+    DrainTimeout: time.Second,
+    DeleteOnStop: true,
+  })
+
+  call, ok := api.findCall("setWebhook")
+  if !ok {
+    t.Fatal("setWebhook was not called on Start")
+  }
+  if call.Params["url"] != url || call.Params["secret_token"] != testSecretToken {
+    t.Fatalf("setWebhook params = %v, want url %q and secret token", call.Params, url)
+  }
+
+  if _, ok = api.findCall("deleteWebhook"); ok {
+    t.Fatal("deleteWebhook called before Stop")
+  }
+
+  if err := webhook.Stop(); err != nil {
+    t.Fatalf("webhook.Stop: %v", err)
+  }
+
+  if _, ok = api.findCall("deleteWebhook"); !ok {
+    t.Fatal("deleteWebhook was not called on Stop")
+  }
+}
+
+func TestWebhookKeepsRegistrationWithoutDeleteOnStop(t *testing.T) {
+  api := newFakeBotAPI(t)
+
+  webhook, _ := startWebhook(t, api, startWebhookParams{
+    Handler:      func(ctx context.Context, bot *tgbot.Bot, update *tgmodels.Update) {},
+    DrainTimeout: time.Second,
+  })
+
+  if err := webhook.Stop(); err != nil {
+    t.Fatalf("webhook.Stop: %v", err)
+  }
+
+  if _, ok := api.findCall("deleteWebhook"); ok {
+    t.Fatal("deleteWebhook called without DeleteOnStop")
+  }
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+  api := newFakeBotAPI(t)
+
+  handled := make(chan *tgmodels.Update, 1)
+
+  webhook, url := startWebhook(t, api, startWebhookParams{
+    Handler: func(ctx context.Context, bot *tgbot.Bot, update *tgmodels.Update) {
+      handled <- update
+    },
+    DrainTimeout: time.Second,
+  })
+  defer webhook.Stop()
+
+  for _, token := range []string{"", "wrong"} {
+    if code := postUpdate(t, url, token); code != http.StatusUnauthorized {
+      t.Fatalf("status with token %q = %d, want %d", token, code, http.StatusUnauthorized)
+    }
+  }
+
+  select {
+  case <-handled:
+    t.Fatal("update with invalid secret token reached the handler")
+  case <-time.After(50 * time.Millisecond):
+  }
+
+  if code := postUpdate(t, url, testSecretToken); code != http.StatusOK {
+    t.Fatalf("status with valid token = %d, want %d", code, http.StatusOK)
+  }
+
+  select {
+  case update := <-handled:
+    if update.Message == nil || update.Message.Text != "hi" {
+      t.Fatalf("handled update = %+v, want message hi", update)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("update with valid secret token did not reach the handler")
+  }
+}
+
+func TestWebhookStopWaitsForHandlers(t *testing.T) {
+  api := newFakeBotAPI(t)
+
+  started := make(chan struct{})
+  finished := make(chan struct{})
+
+  webhook, url := startWebhook(t, api, startWebhookParams{
+    Handler: func(ctx context.Context, bot *tgbot.Bot, update *tgmodels.Update) {
+      close(started)
+      time.Sleep(100 * time.Millisecond)
+      close(finished)
+    },
+    DrainTimeout: 5 * time.Second,
+  })
+
+  if code := postUpdate(t, url, testSecretToken); code != http.StatusOK {
+    t.Fatalf("status = %d, want %d", code, http.StatusOK)
+  }
+  <-started
+
+  if err := webhook.Stop(); err != nil {
+    t.Fatalf("webhook.Stop: %v", err)
+  }
+
+  select {
+  case <-finished:
+  default:
+    t.Fatal("Stop returned before the in-flight handler finished")
+  }
+}
+
+func TestWebhookStopDrainTimeout(t *testing.T) {
+  const drainTimeout = 100 * time.Millisecond
+
+  api := newFakeBotAPI(t)
+
+  started := make(chan struct{})
+  cancelled := make(chan struct{})
+
+  webhook, url := startWebhook(t, api, startWebhookParams{
+    Handler: func(ctx context.Context, bot *tgbot.Bot, update *tgmodels.Update) {
+      close(started)
+      <-ctx.Done()
+      close(cancelled)
+    },
+    DrainTimeout: drainTimeout,
+  })
+
+  if code := postUpdate(t, url, testSecretToken); code != http.StatusOK {
+    t.Fatalf("status = %d, want %d", code, http.StatusOK)
+  }
+  <-started
+
+  stoppedAt := time.Now()
+
+  if err := webhook.Stop(); err != nil {
+    t.Fatalf("webhook.Stop: %v", err)
+  }
+
+  if elapsed := time.Since(stoppedAt); elapsed < drainTimeout || elapsed > drainTimeout+time.Second {
+    t.Fatalf("Stop took %v, want about %v", elapsed, drainTimeout)
+  }
+
+  select {
+  case <-cancelled:
+  case <-time.After(time.Second):
+    t.Fatal("handler context was not cancelled after drain timeout")
+  }
+}
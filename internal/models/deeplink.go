@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DeepLinkId идентификатор ссылки, передаваемый в /start вместе с подписью.
+type DeepLinkId = string
+
+// DeepLink ссылка на бота, открывающая добавление отслеживания с заполненным товаром.
+// Хранит снимок ссылки на товар и размеров, поэтому переход по ссылке не читает данные чата, который ею поделился.
+type DeepLink struct {
+  Id    DeepLinkId `bson:"id" json:"id"`
+  URL   ProductURL `bson:"url" json:"url"`
+  Sizes []string   `bson:"sizes" json:"sizes"`
+  // SourceChatId чат, поделившийся отслеживанием. Не задан для ссылок на товар.
+  SourceChatId ChatId `bson:"source_chat_id,omitempty" json:"source_chat_id,omitempty"`
+  // TrackingId отслеживание, которым поделились. Не задан для ссылок на товар.
+  TrackingId TrackingId `bson:"tracking_id,omitempty" json:"tracking_id,omitempty"`
+  CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+  ExpiresAt  time.Time  `bson:"expires_at" json:"expires_at"`
+}
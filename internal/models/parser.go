@@ -2,10 +2,14 @@ package models
 
 import (
   "context"
+  "errors"
 
   "github.com/go-playground/validator/v10"
 )
 
+// ErrProductNotFound возвращается парсером, если магазин сообщил, что товара больше нет.
+var ErrProductNotFound = errors.New("product not found")
+
 type ParseParams struct {
   URL      string               `bson:"url" json:"url" validate:"required"`
   Sizes    ParseSizesParams     `bson:"sizes" json:"sizes"`
@@ -30,4 +34,6 @@ func (p *ParseParams) HasDiscount() bool {
 
 type Parser interface {
   Parse(ctx context.Context, params ParseParams) (*Product, error)
+  // ProductKey возвращает канонический ключ товара по ссылке без запроса к магазину.
+  ProductKey(url string) (ProductKey, error)
 }
@@ -2,6 +2,7 @@ package models
 
 import (
   "encoding/json"
+  "fmt"
   "math"
   neturl "net/url"
   "strings"
@@ -21,12 +22,30 @@ const (
   ProductTypeTraektoria ProductType = "traektoria"
 )
 
+// ProductTypes поддерживаемые типы товаров.
+var ProductTypes = []ProductType{
+  ProductTypeLamoda,
+  ProductTypeKixbox,
+  ProductTypeOktyabr,
+  ProductTypeLime,
+  ProductTypeRidestep,
+  ProductTypeTraektoria,
+}
+
+// DefaultSellUpThreshold остаток товара по умолчанию, при снижении до которого товар считается распродаваемым.
+const DefaultSellUpThreshold int64 = 5
+
 type ProductType = string
 
 type ProductURL = string
 
+// ProductKey канонический ключ товара, не зависящий от параметров и оформления ссылки.
+// Пример: lamoda:rtlacv500501.
+type ProductKey = string
+
 type Product struct {
   URL         ProductURL      `bson:"url" json:"url"`
+  Key         ProductKey      `bson:"key" json:"key"`
   Type        ProductType     `bson:"type" json:"type"`
   ImageURL    string          `bson:"image_url" json:"image_url"`
   Brand       string          `bson:"brand" json:"brand"`
@@ -102,7 +121,7 @@ type ProductPriceDiff struct {
   Diff     string `bson:"diff" json:"diff"`
 }
 
-func NewProductDiff(stored, parsed Product) *ProductDiff {
+func NewProductDiff(stored, parsed Product, sellUpThreshold int64) *ProductDiff {
   storedOptionsBySize := lo.SliceToMap(stored.Options, func(opt ProductOption) (ProductSizeOptions, ProductOption) {
     return opt.Size, opt
   })
@@ -116,7 +135,7 @@ func NewProductDiff(stored, parsed Product) *ProductDiff {
       OldQuantity: storedOption.Stock.Quantity,
       Quantity:    parsedOption.Stock.Quantity,
 
-      IsSellUp:        parsedOption.Stock.Quantity <= 5 && parsedOption.Stock.Quantity < storedOption.Stock.Quantity,
+      IsSellUp:        parsedOption.Stock.Quantity <= sellUpThreshold && parsedOption.Stock.Quantity < storedOption.Stock.Quantity,
       IsAvailable:     parsedOption.Stock.Quantity > 0,
       IsComeToInStock: parsedOption.Stock.Quantity > 0 && storedOption.Stock.Quantity <= 0,
     }
@@ -150,6 +169,26 @@ func (p *Product) SetParsedAt() {
   p.ParsedAt = time.Now()
 }
 
+// NewProductKey создает ключ товара из кодов, определяющих товар в магазине.
+func NewProductKey(productType ProductType, codes ...string) ProductKey {
+  return productType + ":" + strings.ToLower(strings.Join(codes, ":"))
+}
+
+// NewProductPathKey создает ключ товара из пути ссылки для магазинов, в ссылках которых нет отдельного кода товара.
+func NewProductPathKey(url string) (ProductKey, error) {
+  parsed, err := neturl.Parse(url)
+  if err != nil {
+    return "", fmt.Errorf("neturl.Parse: %w", err)
+  }
+
+  path := strings.Trim(parsed.Path, "/")
+  if path == "" {
+    return "", fmt.Errorf("product path not found in url: %s", url)
+  }
+
+  return NewProductKey(FindProductType(url), path), nil
+}
+
 func FindProductType(url string) ProductType {
   parsed, _ := neturl.Parse(url)
 
@@ -0,0 +1,121 @@
+package models
+
+import (
+  "context"
+  "errors"
+  "time"
+)
+
+var (
+  ErrNotFound      = errors.New("not found")
+  ErrAlreadyExists = errors.New("already exists")
+)
+
+type TrackingsFilter struct {
+  ProductType         ProductType
+  ExcludeProductTypes []ProductType
+  // ExcludePaused пропускает приостановленные и отложенные отслеживания.
+  ExcludePaused bool
+  // ExcludeArchived пропускает архивные отслеживания.
+  ExcludeArchived bool
+}
+
+type ClaimTrackingsParams struct {
+  Filter TrackingsFilter
+  // Owner идентификатор экземпляра трекера.
+  Owner string
+  // TTL время аренды, после которого отслеживание может быть перехвачено.
+  TTL time.Duration
+  // Limit максимальный размер пачки.
+  Limit int
+  // HandledBefore захватываются только отслеживания, обработанные раньше указанного времени.
+  HandledBefore time.Time
+}
+
+type ReleaseTrackingParams struct {
+  ChatId    ChatId
+  URL       ProductURL
+  Owner     string
+  HandledAt time.Time
+}
+
+type DeleteTrackingParams struct {
+  ChatId    ChatId
+  URL       ProductURL
+  DeletedAt time.Time
+  // PurgeAt время, после которого отслеживание удаляется из корзины окончательно.
+  PurgeAt time.Time
+}
+
+type TrackingsRepository interface {
+  Get(ctx context.Context, chatId ChatId, url ProductURL) (*Tracking, error)
+  // GetByProductKey ищет отслеживание того же товара, добавленное по любой ссылке.
+  GetByProductKey(ctx context.Context, chatId ChatId, key ProductKey) (*Tracking, error)
+  // GetById ищет отслеживание по идентификатору из кнопок.
+  GetById(ctx context.Context, chatId ChatId, id TrackingId) (*Tracking, error)
+  List(ctx context.Context, chatId ChatId, limit int64) ([]*Tracking, error)
+  Search(ctx context.Context, chatId ChatId, query string, limit int64) ([]*Tracking, error)
+  // Claim захватывает пачку свободных отслеживаний в аренду в порядке давности обработки.
+  Claim(ctx context.Context, params ClaimTrackingsParams) ([]*Tracking, error)
+  // Release освобождает аренду и сохраняет время обработки.
+  Release(ctx context.Context, params ReleaseTrackingParams) error
+  Insert(ctx context.Context, tracking Tracking) error
+  // Update заменяет отслеживание целиком.
+  Update(ctx context.Context, tracking *Tracking) error
+  // UpdateParsed обновляет только распарсенный товар и время обработки.
+  UpdateParsed(ctx context.Context, tracking *Tracking) error
+  // UpdateSettings обновляет только размеры, флаги и комментарий, сохраняя историю товара.
+  UpdateSettings(ctx context.Context, tracking *Tracking) error
+  // UpdatePause обновляет только приостановку отслеживания.
+  UpdatePause(ctx context.Context, tracking *Tracking) error
+  // UpdateArchive обновляет только архивирование, время первой ошибки парсинга и время возобновления.
+  UpdateArchive(ctx context.Context, tracking *Tracking) error
+  // UpdateExpiry обновляет только срок отслеживания.
+  UpdateExpiry(ctx context.Context, tracking *Tracking) error
+  // Delete перемещает отслеживание в корзину.
+  Delete(ctx context.Context, params DeleteTrackingParams) error
+  // Restore возвращает отслеживание из корзины.
+  Restore(ctx context.Context, chatId ChatId, url ProductURL) error
+  // ListDeleted возвращает отслеживания из корзины, начиная с удаленных последними.
+  ListDeleted(ctx context.Context, chatId ChatId, limit int64) ([]*Tracking, error)
+  // GetDeletedById ищет отслеживание в корзине по идентификатору из кнопок.
+  GetDeletedById(ctx context.Context, chatId ChatId, id TrackingId) (*Tracking, error)
+}
+
+type SessionsRepository interface {
+  Get(ctx context.Context, chatId ChatId) (*Session, error)
+  Upsert(ctx context.Context, session Session) error
+}
+
+type SlidersRepository interface {
+  Insert(ctx context.Context, slider Slider) error
+  Get(ctx context.Context, chatId ChatId, messageId int) (*Slider, error)
+}
+
+type DeepLinksRepository interface {
+  Upsert(ctx context.Context, link DeepLink) error
+  // UpsertMany сохраняет ссылки одним запросом.
+  UpsertMany(ctx context.Context, links []DeepLink) error
+  Get(ctx context.Context, id DeepLinkId) (*DeepLink, error)
+}
+
+type MessagesFilter struct {
+  ProductType ProductType
+}
+
+type MessagesRepository interface {
+  InsertIfNotExist(ctx context.Context, message SendableMessage) (inserted bool, err error)
+  ScanUnsent(ctx context.Context, filter MessagesFilter, callback func(ctx context.Context, message *SendableMessage) error) error
+  Update(ctx context.Context, message *SendableMessage) error
+}
+
+type IssuesRepository interface {
+  Insert(ctx context.Context, issue Issue) error
+}
+
+type RunsRepository interface {
+  Insert(ctx context.Context, run CronRun) error
+  Update(ctx context.Context, run *CronRun) error
+  // FindLast возвращает последний запуск крона с указанным типом товаров.
+  FindLast(ctx context.Context, name CronName, typ ProductType) (*CronRun, error)
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type CronRunStatus string
+
+const (
+  CronRunStatusRunning   CronRunStatus = "running"
+  CronRunStatusSucceeded CronRunStatus = "succeeded"
+  CronRunStatusFailed    CronRunStatus = "failed"
+)
+
+type CronName = string
+
+const (
+  TrackerCronName CronName = "tracker"
+  SenderCronName  CronName = "sender"
+)
+
+type CronRun struct {
+  UUID        string            `bson:"uuid" json:"uuid"`
+  Name        CronName          `bson:"name" json:"name"`
+  ProductType ProductType       `bson:"product_type" json:"product_type"`
+  Status      CronRunStatus     `bson:"status" json:"status"`
+  Error       string            `bson:"error" json:"error"`
+  Timestamps  CronRunTimestamps `bson:"timestamps" json:"timestamps"`
+}
+
+type CronRunTimestamps struct {
+  StartedAt  time.Time  `bson:"started_at" json:"started_at"`
+  FinishedAt *time.Time `bson:"finished_at" json:"finished_at"`
+}
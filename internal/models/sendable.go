@@ -14,11 +14,22 @@ import (
 type SendableType string
 
 const (
-  TrackingSendableType    SendableType = "tracking"
-  ProductSendableType     SendableType = "product"
-  ProductDiffSendableType SendableType = "product_diff"
+  TrackingSendableType         SendableType = "tracking"
+  TrackingArchivedSendableType SendableType = "tracking_archived"
+  ProductSendableType          SendableType = "product"
+  ProductDiffSendableType      SendableType = "product_diff"
 )
 
+// CronSendableTypes типы сообщений, которые отправляются пользователям кроном.
+var CronSendableTypes = []SendableType{
+  ProductDiffSendableType,
+  TrackingArchivedSendableType,
+}
+
+// ArchiveCallbackPrefix префикс данных кнопки возобновления архивного отслеживания.
+// Данные кнопки имеют вид archive:<идентификатор отслеживания>.
+const ArchiveCallbackPrefix = "archive:"
+
 type SendableMessage struct {
   UUID        string             `bson:"uuid" json:"uuid"`
   ChatId      int64              `bson:"chat_id" json:"chat_id"`
@@ -28,6 +39,13 @@ type SendableMessage struct {
   ProductDiff *ProductDiff       `bson:"product_diff" json:"product_diff"`
   SentId      *int               `bson:"sent_id" json:"sent_id"`
   Timestamps  SendableTimestamps `bson:"timestamps" json:"timestamps"`
+  // Buttons кнопки под сообщением, по одной в строке.
+  Buttons []SendableButton `bson:"buttons,omitempty" json:"buttons,omitempty"`
+}
+
+type SendableButton struct {
+  Text         string `bson:"text" json:"text"`
+  CallbackData string `bson:"callback_data" json:"callback_data"`
 }
 
 type SendableText struct {
@@ -109,6 +127,25 @@ func (b Builder) BuildTrackingMessage() BuildResult {
 `, sizesString)
   }
 
+  if b.tracking.Archive != nil {
+    text += fmt.Sprintf(`
+Отслеживание в архиве 🗄
+%s
+`, b.tracking.Archive.String())
+  }
+
+  if b.tracking.Pause.IsPaused(time.Now()) {
+    text += fmt.Sprintf(`
+%s
+`, b.tracking.Pause.String())
+  }
+
+  if expiresAt := b.tracking.Timestamps.ExpiresAt; expiresAt != nil && b.tracking.Archive == nil {
+    text += fmt.Sprintf(`
+Отслеживается до %s ⏳
+`, expiresAt.Local().Format(MessageTimeLayout))
+  }
+
   if utf8.RuneCountInString(b.tracking.Comment) != 0 {
     text += fmt.Sprintf(`
 Комментарий к отслеживанию 💬
@@ -316,3 +353,125 @@ func (b Builder) BuildProductDiffMessage() BuildResult {
 
   return res
 }
+
+// BuildProductSummaryMessage создает сводку изменений товара за время паузы отслеживания.
+// Сводка отправляется всегда, даже если товар не изменился.
+func (b Builder) BuildProductSummaryMessage() BuildResult {
+  text := fmt.Sprintf(`<b>Отслеживание возобновлено ▶️</b>
+
+%s %s
+%s
+`, b.product.Brand, b.product.Category,
+    b.product.URL)
+
+  // Время окончания паузы отличает сводки разных пауз при проверке на повтор.
+  if snoozedUntil := b.tracking.Pause.SnoozedUntil; snoozedUntil != nil {
+    text += fmt.Sprintf(`
+Пауза закончилась %s
+`, snoozedUntil.Local().Format(MessageTimeLayout))
+  }
+
+  changes := ""
+
+  for _, option := range b.diff.Options {
+    size := option.Size.Base.Value
+
+    switch {
+    case option.Stock.IsComeToInStock:
+      changes += fmt.Sprintf(`
+Размер %s появился в наличии 📦
+Доступен в количестве: %d шт
+`, size, option.Stock.Quantity)
+
+    case option.Stock.OldQuantity > 0 && option.Stock.Quantity <= 0:
+      changes += fmt.Sprintf(`
+Размер %s закончился 😟
+`, size)
+
+    case option.Stock.OldQuantity != option.Stock.Quantity:
+      changes += fmt.Sprintf(`
+Количество товара в размере %s изменилось c %d до %d шт
+`, size, option.Stock.OldQuantity, option.Stock.Quantity)
+    }
+
+    switch {
+    case option.Price.IsLower:
+      changes += fmt.Sprintf(`Цена на размер %s снижена 📉
+Текущая цена: %s
+Старая цена: %s
+`, size, option.Price.New, option.Price.Old)
+
+    case option.Price.IsHigher:
+      changes += fmt.Sprintf(`Цена на размер %s возросла 📈
+Текущая цена: %s
+Старая цена: %s
+`, size, option.Price.New, option.Price.Old)
+    }
+  }
+
+  if changes == "" {
+    changes = `
+За время паузы товар не изменился 😉`
+  } else {
+    changes = `
+<b>Изменения за время паузы 📋</b>
+` + changes
+  }
+
+  text = strings.TrimSpace(text + changes)
+
+  return BuildResult{
+    Message: SendableMessage{
+      UUID:        uuid.NewString(),
+      ChatId:      b.chatId,
+      Type:        ProductDiffSendableType,
+      Product:     b.product,
+      ProductDiff: &b.diff,
+      Text: SendableText{
+        Value:  text,
+        SHA256: hasher.SHA256(text),
+      },
+      Timestamps: SendableTimestamps{
+        CreatedAt: time.Now(),
+      },
+    },
+    IsValid: true,
+  }
+}
+
+// BuildTrackingArchivedMessage создает оповещение об архивировании отслеживания с кнопкой возобновления.
+func (b Builder) BuildTrackingArchivedMessage() BuildResult {
+  text := fmt.Sprintf(`<b>Отслеживание перенесено в архив 🗄</b>
+
+%s %s
+%s
+
+%s
+Бот больше не проверяет товар и не присылает уведомления
+`, b.tracking.ParsedProduct.Brand, b.tracking.ParsedProduct.Category,
+    b.tracking.URL, b.tracking.Archive.String())
+
+  // Время архивирования отличает оповещения о повторных архивированиях при проверке на повтор.
+  text += fmt.Sprintf(`
+Перенесено в архив %s`, b.tracking.Archive.ArchivedAt.Local().Format(MessageTimeLayout))
+
+  return BuildResult{
+    Message: SendableMessage{
+      UUID:    uuid.NewString(),
+      ChatId:  b.chatId,
+      Type:    TrackingArchivedSendableType,
+      Product: b.tracking.ParsedProduct,
+      Text: SendableText{
+        Value:  text,
+        SHA256: hasher.SHA256(text),
+      },
+      Buttons: []SendableButton{
+        {Text: "Возобновить", CallbackData: ArchiveCallbackPrefix + b.tracking.Id()},
+      },
+      Timestamps: SendableTimestamps{
+        CreatedAt: time.Now(),
+      },
+    },
+    IsValid: true,
+  }
+}
@@ -19,8 +19,19 @@ const (
   TrackingCommentMenu           SessionMenu = "tracking_comment_menu"
   TrackingInputCommentMenu      SessionMenu = "tracking_input_comment_menu"
   TrackingFlagConfirmMenu       SessionMenu = "tracking_flag_confirm_menu"
+  TrackingEditMenu              SessionMenu = "tracking_edit_menu"
+  TrackingPauseMenu             SessionMenu = "tracking_pause_menu"
+  TrackingPauseDateMenu         SessionMenu = "tracking_pause_date_menu"
+  TrackingPauseConfirmMenu      SessionMenu = "tracking_pause_confirm_menu"
+  TrackingExpiryMenu            SessionMenu = "tracking_expiry_menu"
+  TrackingExpiryConfirmMenu     SessionMenu = "tracking_expiry_confirm_menu"
   TrackingDeleteMenu            SessionMenu = "tracking_delete_menu"
   TrackingDeleteConfirmMenu     SessionMenu = "tracking_delete_confirm_menu"
+  TrackingTrashMenu             SessionMenu = "tracking_trash_menu"
+  TrackingBulkMenu              SessionMenu = "tracking_bulk_menu"
+  TrackingBulkLoadingMenu       SessionMenu = "tracking_bulk_loading_menu"
+  TrackingBulkFlagMenu          SessionMenu = "tracking_bulk_flag_menu"
+  TrackingBulkConfirmMenu       SessionMenu = "tracking_bulk_confirm_menu"
 
   IssueInsertMenu        SessionMenu = "issue_insert_menu"
   IssueInputTypeMenu     SessionMenu = "issue_input_type_menu"
@@ -40,10 +51,23 @@ type Session struct {
   Tracking  *Tracking        `bson:"tracking" json:"tracking"`
   Entities  *SessionEntities `bson:"entities" json:"entities"`
   UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+  // Editing признак изменения существующего отслеживания вместо создания нового.
+  Editing bool `bson:"editing" json:"editing"`
 }
 
 type SessionEntities struct {
-  Issue *Issue `bson:"issue" json:"issue"`
+  Issue *Issue       `bson:"issue" json:"issue"`
+  Bulk  *SessionBulk `bson:"bulk" json:"bulk"`
+}
+
+// SessionBulk состояние добавления нескольких отслеживаний одним сообщением.
+type SessionBulk struct {
+  // URLs ссылки, найденные в сообщении или файле.
+  URLs []string `bson:"urls" json:"urls"`
+  // Trackings отслеживания для загруженных товаров, ожидающие выбора размеров и уведомлений.
+  Trackings []Tracking `bson:"trackings" json:"trackings"`
+  // Sizes общий список размеров. Если не задан, отслеживаются все размеры каждого товара.
+  Sizes []string `bson:"sizes" json:"sizes"`
 }
 
 type SessionMessage struct {
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Slider состояние слайдера отслеживаний, отправленного в сообщении чата.
+// Хранится в базе, чтобы кнопки слайдера работали после перезапуска и на любом экземпляре бота.
+type Slider struct {
+  ChatId    ChatId       `bson:"chat_id" json:"chat_id"`
+  MessageId int          `bson:"message_id" json:"message_id"`
+  Items     []SliderItem `bson:"items" json:"items"`
+  CreatedAt time.Time    `bson:"created_at" json:"created_at"`
+  ExpiresAt time.Time    `bson:"expires_at" json:"expires_at"`
+}
+
+type SliderItem struct {
+  TrackingId TrackingId `bson:"tracking_id" json:"tracking_id"`
+  URL        ProductURL `bson:"url" json:"url"`
+}
+
+func NewSliderItems(list []*Tracking) []SliderItem {
+  items := make([]SliderItem, 0, len(list))
+
+  for _, tracking := range list {
+    items = append(items, SliderItem{
+      TrackingId: tracking.Id(),
+      URL:        tracking.URL,
+    })
+  }
+
+  return items
+}
+
+// Find возвращает позицию отслеживания в слайдере.
+func (s *Slider) Find(id TrackingId) (int, bool) {
+  for index, item := range s.Items {
+    if item.TrackingId == id {
+      return index, true
+    }
+  }
+  return 0, false
+}
@@ -1,22 +1,133 @@
 package models
 
-import "time"
+import (
+  "time"
+
+  "github.com/ushakovn/outfit/pkg/hasher"
+)
+
+// trackingIdLength длина идентификатора отслеживания. Ограничена размером callback data в telegram.
+const trackingIdLength = 16
+
+type TrackingId = string
 
 type Tracking struct {
   ChatId        int64              `bson:"chat_id" json:"chat_id"`
   URL           string             `bson:"url" json:"url"`
+  ProductKey    ProductKey         `bson:"product_key" json:"product_key"`
   Sizes         ParseSizesParams   `bson:"sizes" json:"sizes"`
   ParsedProduct Product            `bson:"parsed_product" json:"parsed_product"`
   Flags         TrackingFlags      `bson:"flags" json:"flags"`
   Comment       string             `bson:"comment" json:"comment"`
   Timestamps    TrackingTimestamps `bson:"timestamps" json:"timestamps"`
+  Pause         TrackingPause      `bson:"pause" json:"pause"`
+  Archive       *TrackingArchive   `bson:"archive,omitempty" json:"archive,omitempty"`
+  Lease         *TrackingLease     `bson:"lease,omitempty" json:"-"`
+  // TrackingId сохраненный Id для поиска по идентификатору. Заполняется репозиторием при записи.
+  TrackingId TrackingId `bson:"tracking_id" json:"tracking_id"`
+}
+
+// TrackingLease аренда отслеживания экземпляром трекера.
+// Истекшая аренда может быть перехвачена другим экземпляром.
+type TrackingLease struct {
+  Owner     string    `bson:"owner" json:"owner"`
+  ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// Id стабильный идентификатор отслеживания, уникальный в пределах чата.
+func (t *Tracking) Id() TrackingId {
+  return NewTrackingId(t.URL)
+}
+
+func NewTrackingId(url ProductURL) TrackingId {
+  return hasher.SHA256(url)[:trackingIdLength]
 }
 
 type TrackingFlags struct {
   WithOptional bool `bson:"with_optional" json:"with_optional"`
 }
 
+// MessageTimeLayout формат времени в сообщениях.
+const MessageTimeLayout = "02.01.2006 15:04"
+
+// TrackingPause приостановка отслеживания пользователем.
+type TrackingPause struct {
+  // Paused отслеживание приостановлено до возобновления пользователем.
+  Paused bool `bson:"paused" json:"paused"`
+  // SnoozedUntil время, до которого отслеживание отложено.
+  // Прошедшее время означает, что пауза закончилась, но сводка изменений за нее еще не отправлена.
+  SnoozedUntil *time.Time `bson:"snoozed_until" json:"snoozed_until"`
+}
+
+// String описывает паузу для карточки отслеживания.
+func (p TrackingPause) String() string {
+  if p.Paused {
+    return "Отслеживание приостановлено ⏸"
+  }
+  if p.SnoozedUntil != nil {
+    return "Отслеживание приостановлено до " + p.SnoozedUntil.Local().Format(MessageTimeLayout) + " ⏸"
+  }
+  return ""
+}
+
+// IsPaused проверяет, что отслеживание не обрабатывается трекером в момент now.
+func (p TrackingPause) IsPaused(now time.Time) bool {
+  return p.Paused || (p.SnoozedUntil != nil && p.SnoozedUntil.After(now))
+}
+
+// IsEnded проверяет, что пауза закончилась и товар нужно сравнить с состоянием до нее.
+func (p TrackingPause) IsEnded(now time.Time) bool {
+  return p.SnoozedUntil != nil && !p.IsPaused(now)
+}
+
+// ArchiveReason причина архивирования отслеживания.
+type ArchiveReason string
+
+const (
+  ArchiveReasonDelisted ArchiveReason = "delisted"
+  ArchiveReasonFailing  ArchiveReason = "failing"
+  ArchiveReasonStale    ArchiveReason = "stale"
+  ArchiveReasonExpired  ArchiveReason = "expired"
+)
+
+// TrackingArchive архивирование отслеживания трекером.
+// Архивное отслеживание видно пользователю, но не обрабатывается трекером до возобновления.
+type TrackingArchive struct {
+  Reason     ArchiveReason `bson:"reason" json:"reason"`
+  ArchivedAt time.Time     `bson:"archived_at" json:"archived_at"`
+}
+
+// String описывает причину архивирования для сообщений.
+func (a *TrackingArchive) String() string {
+  switch a.Reason {
+  case ArchiveReasonDelisted:
+    return "Товар снят с продажи"
+  case ArchiveReasonFailing:
+    return "Магазин долго не отвечает, проверить товар не удается"
+  case ArchiveReasonStale:
+    return "Товар не менялся полгода"
+  case ArchiveReasonExpired:
+    return "Истек срок отслеживания"
+  }
+  return "Отслеживание больше не обрабатывается"
+}
+
 type TrackingTimestamps struct {
   CreatedAt time.Time  `bson:"created_at" json:"created_at"`
   HandledAt *time.Time `bson:"handled_at" json:"handled_at"`
+  // DeletedAt время удаления в корзину. Удаленное отслеживание не показывается пользователю и не обрабатывается трекером.
+  DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+  // PurgeAt время окончательного удаления из корзины.
+  PurgeAt *time.Time `bson:"purge_at,omitempty" json:"purge_at,omitempty"`
+  // FailedSince время первой из подряд идущих ошибок парсинга товара.
+  FailedSince *time.Time `bson:"failed_since,omitempty" json:"failed_since,omitempty"`
+  // ExpiresAt время, после которого отслеживание архивируется. Задается пользователем.
+  ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+  // ReactivatedAt время возобновления из архива. Отсчет времени без изменений товара начинается заново.
+  ReactivatedAt *time.Time `bson:"reactivated_at,omitempty" json:"reactivated_at,omitempty"`
+}
+
+// IsExpired проверяет, что срок отслеживания, заданный пользователем, истек.
+func (t TrackingTimestamps) IsExpired(now time.Time) bool {
+  return t.ExpiresAt != nil && !t.ExpiresAt.After(now)
 }
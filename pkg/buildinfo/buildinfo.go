@@ -0,0 +1,58 @@
+package buildinfo
+
+import (
+  "runtime"
+  "runtime/debug"
+)
+
+// Значения переопределяются при сборке через -ldflags "-X".
+var (
+  Version   = ""
+  Commit    = ""
+  BuildTime = ""
+)
+
+type Info struct {
+  App       string `json:"app"`
+  Version   string `json:"version"`
+  Commit    string `json:"commit"`
+  BuildTime string `json:"build_time"`
+  GoVersion string `json:"go_version"`
+  Modified  bool   `json:"modified"`
+}
+
+func Get(app string) Info {
+  info := Info{
+    App:       app,
+    Version:   Version,
+    Commit:    Commit,
+    BuildTime: BuildTime,
+    GoVersion: runtime.Version(),
+  }
+
+  build, ok := debug.ReadBuildInfo()
+  if !ok {
+    return info
+  }
+
+  for _, setting := range build.Settings {
+    switch setting.Key {
+    case "vcs.revision":
+      if info.Commit == "" {
+        info.Commit = setting.Value
+      }
+    case "vcs.time":
+      if info.BuildTime == "" {
+        info.BuildTime = setting.Value
+      }
+    case "vcs.modified":
+      info.Modified = setting.Value == "true"
+    }
+  }
+
+  if info.Version == "" && build.Main.Version != "(devel)" {
+    info.Version = build.Main.Version
+  }
+
+  return info
+}
@@ -1,70 +1,302 @@
 package cache
 
 import (
+  "container/list"
+  "context"
+  "errors"
+  "fmt"
   "sync"
+  "time"
+
+  "go.uber.org/atomic"
 )
 
-type Key[Primary, Secondary comparable] struct {
-  P Primary
-  S Secondary
+const DefaultLoadTimeout = time.Minute
+
+var ErrLoadPanicked = errors.New("cache load panicked")
+
+type Config struct {
+  // TTL время жизни записи по умолчанию. Ноль без ограничения.
+  TTL time.Duration
+  // Capacity максимальное количество записей. При превышении вытесняется давно не используемая запись.
+  // Ноль без ограничения.
+  Capacity int
+  // JanitorInterval период фонового удаления истекших записей. Ноль без фоновой очистки:
+  // истекшие записи удаляются при чтении и вытеснении.
+  JanitorInterval time.Duration
+  // LoadTimeout ограничение времени загрузки в GetOrLoad. По умолчанию DefaultLoadTimeout.
+  LoadTimeout time.Duration
+}
+
+type Stats struct {
+  Len         int
+  Hits        int64
+  Misses      int64
+  Loads       int64
+  Evictions   int64
+  Expirations int64
 }
 
-type Cache[Primary, Secondary comparable, Value any] struct {
-  mu     sync.Mutex
-  values map[Primary]map[Secondary]Value
+type entry[K comparable, V any] struct {
+  key       K
+  value     V
+  expiresAt time.Time
 }
 
-func NewCache[P, S comparable, V any]() *Cache[P, S, V] {
-  return &Cache[P, S, V]{
-    values: make(map[P]map[S]V),
+func (e *entry[K, V]) isExpired(now time.Time) bool {
+  return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// loadCall загрузка значения, ожидаемая всеми одновременными вызовами GetOrLoad с одним ключом.
+type loadCall[V any] struct {
+  done  chan struct{}
+  value V
+  err   error
+}
+
+// Cache потокобезопасный кэш с временем жизни записей и вытеснением по LRU.
+type Cache[K comparable, V any] struct {
+  config Config
+
+  mu      sync.Mutex
+  order   *list.List
+  entries map[K]*list.Element
+  loads   map[K]*loadCall[V]
+
+  hits        atomic.Int64
+  misses      atomic.Int64
+  loadsCount  atomic.Int64
+  evictions   atomic.Int64
+  expirations atomic.Int64
+
+  // janitorDone закрывается при остановке фоновой очистки.
+  janitorDone chan struct{}
+}
+
+// NewCache создает кэш. Фоновая очистка останавливается при отмене ctx.
+func NewCache[K comparable, V any](ctx context.Context, config Config) *Cache[K, V] {
+  if config.LoadTimeout <= 0 {
+    config.LoadTimeout = DefaultLoadTimeout
   }
+
+  c := &Cache[K, V]{
+    config:      config,
+    order:       list.New(),
+    entries:     make(map[K]*list.Element),
+    loads:       make(map[K]*loadCall[V]),
+    janitorDone: make(chan struct{}),
+  }
+
+  if config.JanitorInterval > 0 {
+    go c.janitor(ctx)
+  }
+
+  return c
 }
 
-func (c *Cache[P, S, V]) Set(key Key[P, S], value V) {
+func (c *Cache[K, V]) Set(key K, value V) {
+  c.SetWithTTL(key, value, c.config.TTL)
+}
+
+// SetWithTTL сохраняет значение с указанным временем жизни. Ноль без ограничения.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  if c.values[key.P] == nil {
-    c.values[key.P] = make(map[S]V)
+  c.set(key, value, ttl)
+}
+
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) {
+  var expiresAt time.Time
+  if ttl > 0 {
+    expiresAt = time.Now().Add(ttl)
+  }
+
+  if elem, ok := c.entries[key]; ok {
+    e := elem.Value.(*entry[K, V])
+    e.value = value
+    e.expiresAt = expiresAt
+
+    c.order.MoveToFront(elem)
+    return
   }
 
-  c.values[key.P][key.S] = value
+  c.entries[key] = c.order.PushFront(&entry[K, V]{
+    key:       key,
+    value:     value,
+    expiresAt: expiresAt,
+  })
+
+  if c.config.Capacity > 0 && c.order.Len() > c.config.Capacity {
+    c.evict()
+  }
 }
 
-func (c *Cache[P, S, V]) Get(key Key[P, S]) (value V, ok bool) {
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  if _, ok = c.values[key.P]; !ok {
+  return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+  elem, ok := c.entries[key]
+  if !ok {
+    c.misses.Inc()
     return value, false
   }
 
-  value, ok = c.values[key.P][key.S]
+  e := elem.Value.(*entry[K, V])
+
+  if e.isExpired(time.Now()) {
+    c.remove(elem)
+    c.expirations.Inc()
+    c.misses.Inc()
 
-  return value, ok
+    return value, false
+  }
+
+  c.order.MoveToFront(elem)
+  c.hits.Inc()
+
+  return e.value, true
 }
 
-func (c *Cache[P, S, V]) DeleteP(key P) {
+// GetOrLoad возвращает значение из кэша или загружает его.
+// Одновременные вызовы с одним ключом ожидают единственную загрузку. Загрузка не прерывается отменой
+// контекста вызова, ее время ограничено Config.LoadTimeout. Ошибки загрузки не кэшируются.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context) (V, error)) (V, error) {
+  c.mu.Lock()
+
+  if value, ok := c.get(key); ok {
+    c.mu.Unlock()
+    return value, nil
+  }
+
+  call, ok := c.loads[key]
+  if !ok {
+    call = &loadCall[V]{done: make(chan struct{})}
+    c.loads[key] = call
+
+    c.loadsCount.Inc()
+
+    go c.load(ctx, key, call, load)
+  }
+
+  c.mu.Unlock()
+
+  select {
+  case <-call.done:
+    return call.value, call.err
+  case <-ctx.Done():
+    var value V
+    return value, ctx.Err()
+  }
+}
+
+// load выполняет загрузку и передает результат ожидающим вызовам.
+// Отмена вызова, начавшего загрузку, не должна приводить к ошибке у остальных ожидающих.
+func (c *Cache[K, V]) load(ctx context.Context, key K, call *loadCall[V], load func(ctx context.Context) (V, error)) {
+  ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.config.LoadTimeout)
+  defer cancel()
+
+  defer func() {
+    // Паника загрузки передается ожидающим как ошибка.
+    if r := recover(); r != nil {
+      call.err = fmt.Errorf("%w: %v", ErrLoadPanicked, r)
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if call.err == nil {
+      c.set(key, call.value, c.config.TTL)
+    }
+    delete(c.loads, key)
+
+    close(call.done)
+  }()
+
+  call.value, call.err = load(ctx)
+}
+
+func (c *Cache[K, V]) Delete(key K) {
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  delete(c.values, key)
+  if elem, ok := c.entries[key]; ok {
+    c.remove(elem)
+  }
 }
 
-func (c *Cache[P, S, V]) DeleteS(key Key[P, S]) {
+func (c *Cache[K, V]) Len() int {
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  if _, ok := c.values[key.P]; !ok {
+  return c.order.Len()
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+  return Stats{
+    Len:         c.Len(),
+    Hits:        c.hits.Load(),
+    Misses:      c.misses.Load(),
+    Loads:       c.loadsCount.Load(),
+    Evictions:   c.evictions.Load(),
+    Expirations: c.expirations.Load(),
+  }
+}
+
+// evict удаляет давно не используемую запись.
+func (c *Cache[K, V]) evict() {
+  elem := c.order.Back()
+  if elem == nil {
     return
   }
 
-  delete(c.values[key.P], key.S)
+  if elem.Value.(*entry[K, V]).isExpired(time.Now()) {
+    c.expirations.Inc()
+  } else {
+    c.evictions.Inc()
+  }
+
+  c.remove(elem)
 }
 
-func (c *Cache[P, S, V]) Clear() {
-  c.mu.Lock()
-  defer c.mu.Unlock()
+func (c *Cache[K, V]) deleteExpired() {
+  now := time.Now()
+
+  for elem := c.order.Back(); elem != nil; {
+    prev := elem.Prev()
 
-  clear(c.values)
+    if elem.Value.(*entry[K, V]).isExpired(now) {
+      c.remove(elem)
+      c.expirations.Inc()
+    }
+    elem = prev
+  }
+}
+
+func (c *Cache[K, V]) remove(elem *list.Element) {
+  c.order.Remove(elem)
+  delete(c.entries, elem.Value.(*entry[K, V]).key)
+}
+
+func (c *Cache[K, V]) janitor(ctx context.Context) {
+  defer close(c.janitorDone)
+
+  ticker := time.NewTicker(c.config.JanitorInterval)
+  defer ticker.Stop()
+
+  for {
+    select {
+    case <-ctx.Done():
+      return
+
+    case <-ticker.C:
+      c.mu.Lock()
+      c.deleteExpired()
+      c.mu.Unlock()
+    }
+  }
 }
@@ -0,0 +1,316 @@
+package cache
+
+import (
+  "context"
+  "errors"
+  "sync"
+  "testing"
+  "time"
+
+  "go.uber.org/atomic"
+)
+
+var errLoad = errors.New("load failed")
+
+func TestCacheTTLExpiry(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{TTL: 50 * time.Millisecond})
+
+  c.Set("key", 1)
+  c.SetWithTTL("forever", 2, 0)
+
+  if value, ok := c.Get("key"); !ok || value != 1 {
+    t.Fatalf("Get() = %d, %v, want 1, true", value, ok)
+  }
+
+  time.Sleep(60 * time.Millisecond)
+
+  if _, ok := c.Get("key"); ok {
+    t.Fatal("Get() returned expired entry")
+  }
+  if value, ok := c.Get("forever"); !ok || value != 2 {
+    t.Fatalf("Get() = %d, %v for entry without ttl, want 2, true", value, ok)
+  }
+
+  if stats := c.Stats(); stats.Expirations != 1 || stats.Len != 1 {
+    t.Fatalf("stats = %+v, want 1 expiration and 1 entry", stats)
+  }
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{Capacity: 2})
+
+  c.Set("a", 1)
+  c.Set("b", 2)
+
+  // Чтение делает запись недавно использованной, вытесняется b.
+  c.Get("a")
+  c.Set("c", 3)
+
+  if _, ok := c.Get("b"); ok {
+    t.Fatal("least recently used entry was not evicted")
+  }
+  for _, key := range []string{"a", "c"} {
+    if _, ok := c.Get(key); !ok {
+      t.Fatalf("entry %q evicted, want kept", key)
+    }
+  }
+
+  if stats := c.Stats(); stats.Evictions != 1 || stats.Len != 2 {
+    t.Fatalf("stats = %+v, want 1 eviction and 2 entries", stats)
+  }
+}
+
+func TestCacheJanitorStopsOnCancel(t *testing.T) {
+  ctx, cancel := context.WithCancel(context.Background())
+
+  c := NewCache[string, int](ctx, Config{
+    TTL:             10 * time.Millisecond,
+    JanitorInterval: 10 * time.Millisecond,
+  })
+
+  c.Set("key", 1)
+
+  deadline := time.Now().Add(time.Second)
+
+  // Истекшая запись удаляется без чтения.
+  for c.Len() != 0 {
+    if time.Now().After(deadline) {
+      t.Fatal("janitor did not delete expired entry")
+    }
+    time.Sleep(5 * time.Millisecond)
+  }
+
+  cancel()
+
+  select {
+  case <-c.janitorDone:
+  case <-time.After(time.Second):
+    t.Fatal("janitor did not stop after ctx cancel")
+  }
+}
+
+func TestCacheStats(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{})
+
+  c.Get("key")
+  c.Set("key", 1)
+  c.Get("key")
+  c.Get("key")
+
+  if _, err := c.GetOrLoad(context.Background(), "loaded", func(ctx context.Context) (int, error) {
+    return 2, nil
+  }); err != nil {
+    t.Fatalf("GetOrLoad() error = %v", err)
+  }
+
+  stats := c.Stats()
+
+  if stats.Hits != 2 || stats.Misses != 2 || stats.Loads != 1 || stats.Len != 2 {
+    t.Fatalf("stats = %+v, want 2 hits, 2 misses, 1 load and 2 entries", stats)
+  }
+}
+
+func TestCacheGetOrLoadCoalesces(t *testing.T) {
+  const callers = 20
+
+  c := NewCache[string, int](context.Background(), Config{})
+
+  var loads atomic.Int32
+
+  started := make(chan struct{})
+  release := make(chan struct{})
+
+  load := func(ctx context.Context) (int, error) {
+    if loads.Inc() == 1 {
+      close(started)
+    }
+    <-release
+    return 42, nil
+  }
+
+  var wg sync.WaitGroup
+
+  results := make(chan int, callers)
+
+  get := func() {
+    defer wg.Done()
+
+    value, err := c.GetOrLoad(context.Background(), "key", load)
+    if err != nil {
+      t.Errorf("GetOrLoad() error = %v", err)
+    }
+    results <- value
+  }
+
+  wg.Add(callers)
+
+  go get()
+  <-started
+
+  for index := 1; index < callers; index++ {
+    go get()
+  }
+
+  time.Sleep(20 * time.Millisecond)
+  close(release)
+
+  wg.Wait()
+  close(results)
+
+  for value := range results {
+    if value != 42 {
+      t.Fatalf("GetOrLoad() = %d, want 42", value)
+    }
+  }
+  if loads.Load() != 1 {
+    t.Fatalf("load called %d times, want 1", loads.Load())
+  }
+
+  // Загруженное значение читается из кэша.
+  if _, err := c.GetOrLoad(context.Background(), "key", load); err != nil {
+    t.Fatalf("GetOrLoad() error = %v", err)
+  }
+  if stats := c.Stats(); loads.Load() != 1 || stats.Loads != 1 {
+    t.Fatalf("stats = %+v, load called %d times, want 1 load", stats, loads.Load())
+  }
+}
+
+func TestCacheGetOrLoadDetachedFromCaller(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{})
+
+  started := make(chan struct{})
+  release := make(chan struct{})
+  loadErr := make(chan error, 1)
+
+  load := func(ctx context.Context) (int, error) {
+    close(started)
+    <-release
+
+    loadErr <- ctx.Err()
+    return 42, nil
+  }
+
+  ctx, cancel := context.WithCancel(context.Background())
+
+  first := make(chan error, 1)
+
+  go func() {
+    _, err := c.GetOrLoad(ctx, "key", load)
+    first <- err
+  }()
+  <-started
+
+  second := make(chan int, 1)
+
+  go func() {
+    value, err := c.GetOrLoad(context.Background(), "key", load)
+    if err != nil {
+      t.Errorf("GetOrLoad() error = %v", err)
+    }
+    second <- value
+  }()
+
+  // Вызов, начавший загрузку, отменяется и не дожидается ее.
+  cancel()
+
+  if err := <-first; !errors.Is(err, context.Canceled) {
+    t.Fatalf("GetOrLoad() error = %v, want context.Canceled", err)
+  }
+
+  close(release)
+
+  if value := <-second; value != 42 {
+    t.Fatalf("GetOrLoad() = %d for waiter, want 42", value)
+  }
+  if err := <-loadErr; err != nil {
+    t.Fatalf("load ctx error = %v, want nil after caller cancel", err)
+  }
+  if value, ok := c.Get("key"); !ok || value != 42 {
+    t.Fatalf("Get() = %d, %v, want loaded value cached", value, ok)
+  }
+}
+
+func TestCacheGetOrLoadTimeout(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{LoadTimeout: 20 * time.Millisecond})
+
+  _, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+    <-ctx.Done()
+    return 0, ctx.Err()
+  })
+  if !errors.Is(err, context.DeadlineExceeded) {
+    t.Fatalf("GetOrLoad() error = %v, want context.DeadlineExceeded", err)
+  }
+}
+
+func TestCacheGetOrLoadErrorNotCached(t *testing.T) {
+  c := NewCache[string, int](context.Background(), Config{})
+
+  _, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+    return 0, errLoad
+  })
+  if !errors.Is(err, errLoad) {
+    t.Fatalf("GetOrLoad() error = %v, want errLoad", err)
+  }
+
+  value, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+    return 1, nil
+  })
+  if err != nil || value != 1 {
+    t.Fatalf("GetOrLoad() = %d, %v after failed load, want 1, nil", value, err)
+  }
+}
+
+func TestCacheGetOrLoadPanic(t *testing.T) {
+  const callers = 5
+
+  c := NewCache[string, int](context.Background(), Config{})
+
+  started := make(chan struct{})
+  release := make(chan struct{})
+
+  load := func(ctx context.Context) (int, error) {
+    close(started)
+    <-release
+    panic("unexpected")
+  }
+
+  var wg sync.WaitGroup
+
+  errs := make(chan error, callers)
+
+  get := func() {
+    defer wg.Done()
+
+    _, err := c.GetOrLoad(context.Background(), "key", load)
+    errs <- err
+  }
+
+  wg.Add(callers)
+
+  go get()
+  <-started
+
+  for index := 1; index < callers; index++ {
+    go get()
+  }
+
+  time.Sleep(20 * time.Millisecond)
+  close(release)
+
+  wg.Wait()
+  close(errs)
+
+  for err := range errs {
+    if !errors.Is(err, ErrLoadPanicked) {
+      t.Fatalf("GetOrLoad() error = %v, want ErrLoadPanicked", err)
+    }
+  }
+
+  // После паники ключ загружается заново.
+  value, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+    return 1, nil
+  })
+  if err != nil || value != 1 {
+    t.Fatalf("GetOrLoad() = %d, %v after panic, want 1, nil", value, err)
+  }
+}
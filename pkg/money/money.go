@@ -2,11 +2,15 @@ package money
 
 import "github.com/leekchan/accounting"
 
+// acc используется конкурентно. Все форматы заданы явно, иначе FormatMoney заполняет их при каждом вызове.
 var acc = accounting.Accounting{
-  Symbol:    "₽ ",
-  Precision: 2,
-  Thousand:  " ",
-  Decimal:   ".",
+  Symbol:         "₽ ",
+  Precision:      2,
+  Thousand:       " ",
+  Decimal:        ".",
+  Format:         "%s%v",
+  FormatNegative: "-%s%v",
+  FormatZero:     "%s%v",
 }
 
 func String(value int64) string {
@@ -3,7 +3,9 @@ package xpath
 import (
   "bytes"
   "context"
+  "errors"
   "fmt"
+  "net/http"
   "strings"
 
   "github.com/antchfx/htmlquery"
@@ -12,6 +14,9 @@ import (
   "golang.org/x/net/html"
 )
 
+// ErrPageNotFound возвращается, если сайт ответил, что страницы не существует.
+var ErrPageNotFound = errors.New("page not found")
+
 type ShiftNodePos int
 
 const (
@@ -47,6 +52,10 @@ func (p *Parser) GetHtmlNode(ctx context.Context, url string) (*html.Node, error
     return nil, fmt.Errorf("p.deps.Telegram.R().Get: %w", err)
   }
 
+  if resp.StatusCode() == http.StatusNotFound {
+    return nil, fmt.Errorf("%w: url: %s", ErrPageNotFound, url)
+  }
+
   node, err := html.Parse(bytes.NewReader(resp.Body()))
   if err != nil {
     return nil, fmt.Errorf("html.Parse(resp.RawBody()): %w", err)
@@ -24,7 +24,7 @@ var (
   RegexNonDigit  = regexp.MustCompile(`[^0-9]`)
   RegexNonFloat  = regexp.MustCompile(`[^0-9.,]`)
   RegexRepeatSep = regexp.MustCompile(`\s{2,}`)
-  RegexURL       = regexp.MustCompile(`https?://.+`)
+  RegexURL       = regexp.MustCompile(`https?://\S+`)
 )
 
 func StripTags(s string) string {
@@ -43,12 +43,27 @@ func ReplaceRepeatSeparators(s string, repl string) string {
   return RegexRepeatSep.ReplaceAllString(Strip(s), repl)
 }
 
+// urlTrailingPunct знаки препинания, которыми обычно заканчивается предложение со ссылкой.
+const urlTrailingPunct = `.,;:!?)]}»"'`
+
 func ExtractURL(value string) string {
   value = RegexURL.FindString(value)
-  value = strings.TrimSpace(value)
+  value = strings.TrimRight(value, urlTrailingPunct)
   return value
 }
 
+// ExtractURLs возвращает все ссылки из текста в порядке их следования.
+func ExtractURLs(value string) []string {
+  found := RegexURL.FindAllString(value, -1)
+
+  values := make([]string, 0, len(found))
+  for _, url := range found {
+    values = append(values, strings.TrimRight(url, urlTrailingPunct))
+  }
+
+  return values
+}
+
 func ExtractDigit(s string) string {
   s = RegexNonFloat.ReplaceAllString(s, "")
   return strings.ReplaceAll(s, ",", ".")
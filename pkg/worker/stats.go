@@ -0,0 +1,81 @@
+package worker
+
+import (
+  "errors"
+  "time"
+
+  "go.uber.org/atomic"
+)
+
+// Stats статистика пула.
+type Stats struct {
+  // Queued задачи, ожидающие воркера, включая отложенные по ключу.
+  Queued  int
+  Running int64
+  Pushed  int64
+  // Completed задачи, выполненные без ошибки.
+  Completed int64
+  Failed    int64
+  Panicked  int64
+  // Dropped задачи, отброшенные при отмене пула.
+  Dropped int64
+  // AvgWait среднее время ожидания задачи в очереди.
+  AvgWait time.Duration
+  // AvgLatency среднее время выполнения задачи.
+  AvgLatency time.Duration
+  MaxLatency time.Duration
+}
+
+type stats struct {
+  pushed     atomic.Int64
+  running    atomic.Int64
+  completed  atomic.Int64
+  failed     atomic.Int64
+  panicked   atomic.Int64
+  dropped    atomic.Int64
+  totalWait  atomic.Duration
+  totalTime  atomic.Duration
+  maxLatency atomic.Duration
+}
+
+func (s *stats) observe(wait, duration time.Duration, err error) {
+  s.totalWait.Add(wait)
+  s.totalTime.Add(duration)
+
+  for {
+    current := s.maxLatency.Load()
+    if duration <= current || s.maxLatency.CAS(current, duration) {
+      break
+    }
+  }
+
+  switch {
+  case err == nil:
+    s.completed.Inc()
+  case errors.Is(err, ErrPanic):
+    s.panicked.Inc()
+    s.failed.Inc()
+  default:
+    s.failed.Inc()
+  }
+}
+
+func (p *Pool) Stats() Stats {
+  s := Stats{
+    Queued:     len(p.slots),
+    Running:    p.stats.running.Load(),
+    Pushed:     p.stats.pushed.Load(),
+    Completed:  p.stats.completed.Load(),
+    Failed:     p.stats.failed.Load(),
+    Panicked:   p.stats.panicked.Load(),
+    Dropped:    p.stats.dropped.Load(),
+    MaxLatency: p.stats.maxLatency.Load(),
+  }
+
+  if handled := s.Completed + s.Failed; handled != 0 {
+    s.AvgWait = p.stats.totalWait.Load() / time.Duration(handled)
+    s.AvgLatency = p.stats.totalTime.Load() / time.Duration(handled)
+  }
+
+  return s
+}
@@ -2,79 +2,360 @@ package worker
 
 import (
   "context"
+  "errors"
+  "fmt"
+  "runtime/debug"
+  "slices"
+  "sort"
   "sync"
-
-  log "github.com/sirupsen/logrus"
+  "time"
 )
 
 const DefaultCount = 5
 
+var (
+  ErrPoolClosed = errors.New("worker pool closed")
+  ErrPanic      = errors.New("worker call panicked")
+)
+
 type Call func(ctx context.Context) error
 
+type Config struct {
+  // Count количество воркеров. По умолчанию DefaultCount.
+  Count int
+  // QueueSize размер очереди задач, ожидающих воркера. По умолчанию равен Count.
+  // При заполненной очереди Push блокируется.
+  QueueSize int
+  // CollectResults сохранять результаты всех задач, а не только ошибки.
+  CollectResults bool
+}
+
+// Result результат выполнения задачи.
+type Result struct {
+  Key      string
+  Err      error
+  Wait     time.Duration
+  Duration time.Duration
+}
+
+type task struct {
+  key      string
+  seq      int
+  call     Call
+  pushedAt time.Time
+}
+
+// keyState очередь задач с одинаковым ключом. Одновременно выполняется не более одной задачи на ключ.
+// Воркеры могут получить задачи ключа из очереди не в порядке добавления,
+// поэтому задачи выполняются по номерам, выданным в PushKey.
+type keyState struct {
+  // pushed номер следующей добавленной задачи.
+  pushed int
+  // next номер задачи, которая должна выполниться следующей.
+  next    int
+  running bool
+  // pending отложенные задачи, отсортированные по номеру.
+  pending []task
+}
+
 type Pool struct {
-  count   uint8
-  ch      chan Call
-  done    chan struct{}
-  stopped bool
+  config Config
+
+  ctx    context.Context
+  cancel context.CancelFunc
+
+  queue chan task
+  slots chan struct{}
+  wg    sync.WaitGroup
+
+  closeMu sync.RWMutex
+  closed  bool
+
+  keysMu sync.Mutex
+  keys   map[string]*keyState
+
+  resultsMu sync.Mutex
+  results   []Result
+  errs      []error
+
+  stats stats
+}
+
+func NewPool(ctx context.Context, config Config) *Pool {
+  if config.Count <= 0 {
+    config.Count = DefaultCount
+  }
+  if config.QueueSize <= 0 {
+    config.QueueSize = config.Count
+  }
+
+  ctx, cancel := context.WithCancel(ctx)
+
+  p := &Pool{
+    config: config,
+    ctx:    ctx,
+    cancel: cancel,
+    queue:  make(chan task, config.QueueSize),
+    slots:  make(chan struct{}, config.QueueSize),
+    keys:   make(map[string]*keyState),
+  }
+
+  p.wg.Add(config.Count)
+
+  for index := 0; index < config.Count; index++ {
+    go p.work()
+  }
+
+  return p
+}
+
+// Push ставит задачу в очередь. Блокируется, пока очередь заполнена.
+func (p *Pool) Push(ctx context.Context, call Call) error {
+  return p.PushKey(ctx, "", call)
+}
+
+// PushKey ставит задачу в очередь. Задачи с одинаковым непустым ключом выполняются последовательно в порядке добавления.
+func (p *Pool) PushKey(ctx context.Context, key string, call Call) error {
+  select {
+  case p.slots <- struct{}{}:
+  case <-ctx.Done():
+    return ctx.Err()
+  case <-p.ctx.Done():
+    return ErrPoolClosed
+  }
+
+  p.closeMu.RLock()
+  defer p.closeMu.RUnlock()
+
+  if p.closed {
+    <-p.slots
+    return ErrPoolClosed
+  }
+
+  p.stats.pushed.Inc()
+
+  // Отправка не блокируется: место в очереди уже занято.
+  p.queue <- task{
+    key:      key,
+    seq:      p.nextKeySeq(key),
+    call:     call,
+    pushedAt: time.Now(),
+  }
+
+  return nil
+}
+
+// nextKeySeq выдает номер очередной задачи ключа.
+func (p *Pool) nextKeySeq(key string) int {
+  if key == "" {
+    return 0
+  }
+
+  p.keysMu.Lock()
+  defer p.keysMu.Unlock()
+
+  state, ok := p.keys[key]
+  if !ok {
+    state = &keyState{}
+    p.keys[key] = state
+  }
+  seq := state.pushed
+  state.pushed++
+
+  return seq
 }
 
-func NewPool(ctx context.Context, count uint8) Pool {
-  pool := Pool{
-    count: count,
-    ch:    make(chan Call),
-    done:  make(chan struct{}),
+// Drain прекращает прием задач и дожидается выполнения всех поставленных.
+// Возвращает объединенные ошибки задач.
+func (p *Pool) Drain() error {
+  p.close()
+  p.wg.Wait()
+  p.cancel()
+
+  return p.Err()
+}
+
+// Cancel прекращает прием задач, отменяет контекст выполняемых и отбрасывает ожидающие.
+// Возвращает объединенные ошибки задач.
+func (p *Pool) Cancel() error {
+  p.cancel()
+  p.close()
+  p.wg.Wait()
+
+  // Очередь закрыта, поэтому чтение завершится на последней невыполненной задаче.
+  for range p.queue {
+    p.drop()
+  }
+
+  // Отложенные задачи могли остаться без воркера, если предшествующая задача ключа была отброшена.
+  p.keysMu.Lock()
+  for _, state := range p.keys {
+    for range state.pending {
+      p.drop()
+    }
   }
-  pool.start(ctx)
+  p.keys = make(map[string]*keyState)
+  p.keysMu.Unlock()
+
+  return p.Err()
+}
+
+// Err возвращает объединенные ошибки выполненных задач.
+func (p *Pool) Err() error {
+  p.resultsMu.Lock()
+  defer p.resultsMu.Unlock()
+
+  return errors.Join(p.errs...)
+}
+
+// Results возвращает результаты выполненных задач, если включен Config.CollectResults.
+func (p *Pool) Results() []Result {
+  p.resultsMu.Lock()
+  defer p.resultsMu.Unlock()
 
-  return pool
+  return append([]Result(nil), p.results...)
 }
 
-func (p *Pool) start(ctx context.Context) {
-  var wg sync.WaitGroup
+func (p *Pool) close() {
+  p.closeMu.Lock()
+  defer p.closeMu.Unlock()
 
-  wg.Add(int(p.count))
+  if p.closed {
+    return
+  }
+  p.closed = true
+
+  close(p.queue)
+}
 
-  for index := 0; index < int(p.count); index++ {
-    go func() {
-      defer wg.Done()
+func (p *Pool) work() {
+  defer p.wg.Done()
 
-      for {
-        select {
-        case <-ctx.Done():
-          log.Warn("worker.pool: context cancelled: worker stopped")
-          return
+  for {
+    select {
+    case <-p.ctx.Done():
+      return
 
-        case call, ok := <-p.ch:
-          if !ok {
-            return
-          }
-          if err := call(ctx); err != nil {
-            log.Errorf("worker.pool: worker call failed: %v", err)
-          }
+    case t, ok := <-p.queue:
+      if !ok {
+        return
+      }
+      if !p.acquireKey(t) {
+        continue
+      }
+      // Воркер выполняет все накопившиеся задачи ключа, чтобы сохранить их порядок.
+      // После отмены пула оставшиеся задачи ключа отбрасываются.
+      for next := &t; next != nil; next = p.releaseKey(next.key) {
+        if p.ctx.Err() != nil {
+          p.drop()
+          continue
         }
+        p.run(*next)
       }
-    }()
+    }
   }
+}
 
-  go func() {
-    wg.Wait()
+// acquireKey захватывает ключ задачи. Если ключ занят или задача пришла раньше предшествующей,
+// задача откладывается до ее очереди.
+func (p *Pool) acquireKey(t task) bool {
+  if t.key == "" {
+    return true
+  }
 
-    p.done <- struct{}{}
-  }()
+  p.keysMu.Lock()
+  defer p.keysMu.Unlock()
+
+  state := p.keys[t.key]
+
+  if state.running || state.next != t.seq {
+    index := sort.Search(len(state.pending), func(index int) bool {
+      return state.pending[index].seq > t.seq
+    })
+    state.pending = slices.Insert(state.pending, index, t)
+    return false
+  }
+  state.running = true
 
+  return true
 }
 
-func (p *Pool) Push(call Call) {
-  p.ch <- call
+// releaseKey освобождает ключ или возвращает следующую по порядку отложенную задачу с этим ключом.
+func (p *Pool) releaseKey(key string) *task {
+  if key == "" {
+    return nil
+  }
+
+  p.keysMu.Lock()
+  defer p.keysMu.Unlock()
+
+  state, ok := p.keys[key]
+  if !ok {
+    return nil
+  }
+  state.next++
+
+  if len(state.pending) == 0 || state.pending[0].seq != state.next {
+    state.running = false
+
+    if state.next == state.pushed {
+      delete(p.keys, key)
+    }
+    return nil
+  }
+
+  next := state.pending[0]
+  state.pending = state.pending[1:]
+
+  return &next
 }
 
-func (p *Pool) StopWait() {
-  if p.stopped {
-    return
+// drop отбрасывает задачу и освобождает ее место в очереди.
+func (p *Pool) drop() {
+  <-p.slots
+  p.stats.dropped.Inc()
+}
+
+func (p *Pool) run(t task) {
+  <-p.slots
+
+  startedAt := time.Now()
+  wait := startedAt.Sub(t.pushedAt)
+
+  p.stats.running.Inc()
+  err := safeCall(p.ctx, t.call)
+  p.stats.running.Dec()
+
+  duration := time.Since(startedAt)
+
+  p.stats.observe(wait, duration, err)
+
+  if err != nil && t.key != "" {
+    err = fmt.Errorf("%s: %w", t.key, err)
   }
-  close(p.ch)
 
-  <-p.done
+  p.resultsMu.Lock()
+  defer p.resultsMu.Unlock()
+
+  if err != nil {
+    p.errs = append(p.errs, err)
+  }
+  if p.config.CollectResults {
+    p.results = append(p.results, Result{
+      Key:      t.key,
+      Err:      err,
+      Wait:     wait,
+      Duration: duration,
+    })
+  }
+}
+
+func safeCall(ctx context.Context, call Call) (err error) {
+  defer func() {
+    if r := recover(); r != nil {
+      err = fmt.Errorf("%w: %v\n%s", ErrPanic, r, debug.Stack())
+    }
+  }()
 
-  p.stopped = true
+  return call(ctx)
 }
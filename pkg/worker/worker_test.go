@@ -0,0 +1,380 @@
+package worker
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "runtime"
+  "sync"
+  "testing"
+  "time"
+
+  "go.uber.org/atomic"
+)
+
+var errBoom = errors.New("boom")
+
+func TestPoolPanicRecovered(t *testing.T) {
+  pool := NewPool(context.Background(), Config{Count: 1})
+
+  var called atomic.Bool
+
+  mustPush(t, pool, func(ctx context.Context) error {
+    panic("unexpected")
+  })
+  mustPush(t, pool, func(ctx context.Context) error {
+    called.Store(true)
+    return nil
+  })
+
+  err := pool.Drain()
+  if !errors.Is(err, ErrPanic) {
+    t.Fatalf("Drain() error = %v, want ErrPanic", err)
+  }
+  if !called.Load() {
+    t.Fatal("call after panic was not executed")
+  }
+
+  stats := pool.Stats()
+  if stats.Panicked != 1 || stats.Failed != 1 || stats.Completed != 1 {
+    t.Fatalf("stats = %+v, want 1 panicked, 1 failed, 1 completed", stats)
+  }
+}
+
+func TestPoolPushBlocksWhenQueueFull(t *testing.T) {
+  pool := NewPool(context.Background(), Config{Count: 1, QueueSize: 1})
+
+  started := make(chan struct{})
+  release := make(chan struct{})
+
+  mustPush(t, pool, func(ctx context.Context) error {
+    close(started)
+    <-release
+    return nil
+  })
+  <-started
+
+  // Воркер занят, вторая задача занимает единственное место в очереди.
+  mustPush(t, pool, func(ctx context.Context) error { return nil })
+
+  ctx, cancel := context.WithCancel(context.Background())
+  pushed := make(chan error, 1)
+
+  go func() {
+    pushed <- pool.Push(ctx, func(ctx context.Context) error { return nil })
+  }()
+
+  select {
+  case err := <-pushed:
+    t.Fatalf("Push() returned %v with full queue, want blocking", err)
+  case <-time.After(50 * time.Millisecond):
+  }
+
+  cancel()
+
+  select {
+  case err := <-pushed:
+    if !errors.Is(err, context.Canceled) {
+      t.Fatalf("Push() error = %v, want context.Canceled", err)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("Push() did not return after ctx cancel")
+  }
+
+  close(release)
+
+  if err := pool.Drain(); err != nil {
+    t.Fatalf("Drain() error = %v", err)
+  }
+  if stats := pool.Stats(); stats.Pushed != 2 || stats.Completed != 2 {
+    t.Fatalf("stats = %+v, want 2 pushed and completed", stats)
+  }
+}
+
+func TestPoolPushAfterDrain(t *testing.T) {
+  pool := NewPool(context.Background(), Config{Count: 1})
+
+  if err := pool.Drain(); err != nil {
+    t.Fatalf("Drain() error = %v", err)
+  }
+
+  err := pool.Push(context.Background(), func(ctx context.Context) error { return nil })
+  if !errors.Is(err, ErrPoolClosed) {
+    t.Fatalf("Push() error = %v, want ErrPoolClosed", err)
+  }
+}
+
+func TestPoolPushKeySerializes(t *testing.T) {
+  const count = 50
+
+  pool := NewPool(context.Background(), Config{Count: 4})
+
+  var (
+    mu     sync.Mutex
+    order  = map[string][]int{}
+    active = map[string]*atomic.Int32{"a": atomic.NewInt32(0), "b": atomic.NewInt32(0)}
+  )
+
+  for index := 0; index < count; index++ {
+    for _, key := range []string{"a", "b"} {
+      index, key := index, key
+
+      err := pool.PushKey(context.Background(), key, func(ctx context.Context) error {
+        if active[key].Inc() != 1 {
+          return fmt.Errorf("concurrent call for key %s", key)
+        }
+        defer active[key].Dec()
+
+        time.Sleep(100 * time.Microsecond)
+
+        mu.Lock()
+        order[key] = append(order[key], index)
+        mu.Unlock()
+
+        return nil
+      })
+      if err != nil {
+        t.Fatalf("PushKey() error = %v", err)
+      }
+    }
+  }
+
+  if err := pool.Drain(); err != nil {
+    t.Fatalf("Drain() error = %v", err)
+  }
+
+  for key, values := range order {
+    if len(values) != count {
+      t.Fatalf("key %s executed %d calls, want %d", key, len(values), count)
+    }
+    for index, value := range values {
+      if value != index {
+        t.Fatalf("key %s order = %v, want ascending", key, values)
+      }
+    }
+  }
+}
+
+func TestPoolPushKeyKeepsOrder(t *testing.T) {
+  const (
+    count = 500
+    keys  = 3
+  )
+
+  // Быстрые задачи и много воркеров: задачи одного ключа попадают к разным воркерам почти одновременно.
+  pool := NewPool(context.Background(), Config{Count: 8})
+
+  var (
+    mu    sync.Mutex
+    order = map[string][]int{}
+  )
+
+  for index := 0; index < count; index++ {
+    index, key := index, fmt.Sprintf("key-%d", index%keys)
+
+    mustPushKey(t, pool, key, func(ctx context.Context) error {
+      runtime.Gosched()
+
+      mu.Lock()
+      order[key] = append(order[key], index)
+      mu.Unlock()
+
+      return nil
+    })
+  }
+
+  if err := pool.Drain(); err != nil {
+    t.Fatalf("Drain() error = %v", err)
+  }
+
+  for key, values := range order {
+    for index := 1; index < len(values); index++ {
+      if values[index] < values[index-1] {
+        t.Fatalf("key %s order = %v, want ascending", key, values)
+      }
+    }
+  }
+  if len(pool.keys) != 0 {
+    t.Fatalf("keys = %d after drain, want none", len(pool.keys))
+  }
+}
+
+func TestPoolDrainCompletesQueued(t *testing.T) {
+  const count = 10
+
+  pool := NewPool(context.Background(), Config{Count: 1, QueueSize: count})
+
+  var executed atomic.Int32
+
+  for index := 0; index < count; index++ {
+    mustPush(t, pool, func(ctx context.Context) error {
+      time.Sleep(time.Millisecond)
+      executed.Inc()
+      return nil
+    })
+  }
+
+  if err := pool.Drain(); err != nil {
+    t.Fatalf("Drain() error = %v", err)
+  }
+  if executed.Load() != count {
+    t.Fatalf("executed = %d, want %d", executed.Load(), count)
+  }
+  if stats := pool.Stats(); stats.Completed != count || stats.Dropped != 0 || stats.Queued != 0 {
+    t.Fatalf("stats = %+v, want %d completed and none dropped", stats, count)
+  }
+}
+
+func TestPoolCancelDropsQueued(t *testing.T) {
+  const queued = 5
+
+  pool := NewPool(context.Background(), Config{Count: 1, QueueSize: queued})
+
+  started := make(chan struct{})
+
+  mustPush(t, pool, func(ctx context.Context) error {
+    close(started)
+    <-ctx.Done()
+    return ctx.Err()
+  })
+  <-started
+
+  var executed atomic.Int32
+
+  for index := 0; index < queued; index++ {
+    mustPush(t, pool, func(ctx context.Context) error {
+      executed.Inc()
+      return nil
+    })
+  }
+
+  err := pool.Cancel()
+  if !errors.Is(err, context.Canceled) {
+    t.Fatalf("Cancel() error = %v, want context.Canceled", err)
+  }
+  if executed.Load() != 0 {
+    t.Fatalf("executed = %d after cancel, want 0", executed.Load())
+  }
+
+  stats := pool.Stats()
+  if stats.Dropped != queued || stats.Queued != 0 || stats.Failed != 1 {
+    t.Fatalf("stats = %+v, want %d dropped, 0 queued and 1 failed", stats, queued)
+  }
+}
+
+func TestPoolCancelDropsPendingKeys(t *testing.T) {
+  const pending = 3
+
+  pool := NewPool(context.Background(), Config{Count: 2, QueueSize: pending})
+
+  started := make(chan struct{})
+
+  mustPushKey(t, pool, "key", func(ctx context.Context) error {
+    close(started)
+    <-ctx.Done()
+    return nil
+  })
+  <-started
+
+  for index := 0; index < pending; index++ {
+    mustPushKey(t, pool, "key", func(ctx context.Context) error {
+      t.Error("pending call executed after cancel")
+      return nil
+    })
+  }
+
+  if err := pool.Cancel(); err != nil {
+    t.Fatalf("Cancel() error = %v", err)
+  }
+  if stats := pool.Stats(); stats.Dropped != pending || stats.Completed != 1 {
+    t.Fatalf("stats = %+v, want %d dropped and 1 completed", stats, pending)
+  }
+}
+
+func TestPoolResults(t *testing.T) {
+  pool := NewPool(context.Background(), Config{Count: 2, CollectResults: true})
+
+  mustPushKey(t, pool, "ok", func(ctx context.Context) error { return nil })
+  mustPushKey(t, pool, "failed", func(ctx context.Context) error { return errBoom })
+
+  err := pool.Drain()
+  if !errors.Is(err, errBoom) {
+    t.Fatalf("Drain() error = %v, want errBoom", err)
+  }
+  if !errors.Is(pool.Err(), errBoom) {
+    t.Fatalf("Err() = %v, want errBoom", pool.Err())
+  }
+
+  results := pool.Results()
+  if len(results) != 2 {
+    t.Fatalf("len(Results()) = %d, want 2", len(results))
+  }
+
+  for _, result := range results {
+    switch result.Key {
+    case "ok":
+      if result.Err != nil {
+        t.Fatalf("result %q error = %v, want nil", result.Key, result.Err)
+      }
+    case "failed":
+      if !errors.Is(result.Err, errBoom) || result.Err.Error() != "failed: boom" {
+        t.Fatalf("result %q error = %v, want failed: boom", result.Key, result.Err)
+      }
+    default:
+      t.Fatalf("unexpected result key %q", result.Key)
+    }
+  }
+}
+
+func TestPoolStats(t *testing.T) {
+  const (
+    completed = 7
+    failed    = 3
+  )
+
+  pool := NewPool(context.Background(), Config{Count: 3})
+
+  for index := 0; index < completed+failed; index++ {
+    index := index
+
+    mustPush(t, pool, func(ctx context.Context) error {
+      time.Sleep(time.Millisecond)
+      if index < failed {
+        return errBoom
+      }
+      return nil
+    })
+  }
+
+  if err := pool.Drain(); !errors.Is(err, errBoom) {
+    t.Fatalf("Drain() error = %v, want errBoom", err)
+  }
+
+  stats := pool.Stats()
+
+  if stats.Pushed != completed+failed || stats.Completed != completed || stats.Failed != failed {
+    t.Fatalf("stats = %+v, want %d pushed, %d completed, %d failed", stats, completed+failed, completed, failed)
+  }
+  if stats.Running != 0 || stats.Queued != 0 || stats.Dropped != 0 || stats.Panicked != 0 {
+    t.Fatalf("stats = %+v, want idle pool", stats)
+  }
+  if stats.AvgLatency <= 0 || stats.MaxLatency < stats.AvgLatency {
+    t.Fatalf("stats = %+v, want positive latency with max not below average", stats)
+  }
+}
+
+func mustPush(t *testing.T, pool *Pool, call Call) {
+  t.Helper()
+
+  if err := pool.Push(context.Background(), call); err != nil {
+    t.Fatalf("Push() error = %v", err)
+  }
+}
+
+func mustPushKey(t *testing.T, pool *Pool, key string, call Call) {
+  t.Helper()
+
+  if err := pool.PushKey(context.Background(), key, call); err != nil {
+    t.Fatalf("PushKey() error = %v", err)
+  }
+}